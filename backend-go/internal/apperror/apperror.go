@@ -0,0 +1,172 @@
+// Package apperror defines the errors services return to API clients. Each
+// error has a kind, which decides the HTTP status, a machine-readable code
+// and a message that is safe to show to users.
+package apperror
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kind classifies an error for clients
+type Kind int
+
+// Error kinds
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindRateLimited
+	KindLocked
+	KindUnavailable
+)
+
+// CodeValidation is the code of requests that fail field validation
+const CodeValidation = "VALIDATION_ERROR"
+
+// Error is a domain error. Services declare sentinels with the constructors
+// below and derive request-specific errors from them with Field, Withf and
+// the other With methods; errors.Is matches a derived error to its sentinel.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	// Fields maps request fields to what is wrong with them
+	Fields map[string]string
+	// RetryAfter tells rate limited and locked clients when to try again
+	RetryAfter time.Duration
+	// Data is extra payload for the client, such as a challenge token
+	Data interface{}
+
+	base  *Error
+	cause error
+}
+
+// New creates an error
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// NotFound creates an error for a missing resource
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+// Conflict creates an error for a request that conflicts with current state
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+// Validation creates an error for invalid input
+func Validation(code, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+// Unauthorized creates an error for missing or invalid credentials
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+// Forbidden creates an error for an action the user may not take
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+// RateLimited creates an error for an action repeated too often
+func RateLimited(code, message string) *Error {
+	return New(KindRateLimited, code, message)
+}
+
+// Locked creates an error for a resource that is temporarily locked
+func Locked(code, message string) *Error {
+	return New(KindLocked, code, message)
+}
+
+// Unavailable creates an error for a feature that is not configured
+func Unavailable(code, message string) *Error {
+	return New(KindUnavailable, code, message)
+}
+
+// Error implements error. The cause is included for logs but never shown
+// to clients.
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+// Unwrap lets errors.Is and errors.As reach the sentinel and the cause
+func (e *Error) Unwrap() []error {
+	var errs []error
+	if e.base != nil {
+		errs = append(errs, e.base)
+	}
+	if e.cause != nil {
+		errs = append(errs, e.cause)
+	}
+	return errs
+}
+
+// Withf returns a copy whose message ends with the formatted detail
+func (e *Error) Withf(format string, args ...interface{}) *Error {
+	d := e.derive()
+	d.Message += ": " + fmt.Sprintf(format, args...)
+	return d
+}
+
+// Field returns a copy reporting what is wrong with a request field. The
+// problem is also appended to the message.
+func (e *Error) Field(field, format string, args ...interface{}) *Error {
+	problem := fmt.Sprintf(format, args...)
+	d := e.derive()
+	d.Message += ": " + problem
+	d.Fields[field] = problem
+	return d
+}
+
+// WithFields returns a copy with problems for several fields, leaving the
+// message unchanged
+func (e *Error) WithFields(fields map[string]string) *Error {
+	d := e.derive()
+	for field, problem := range fields {
+		d.Fields[field] = problem
+	}
+	return d
+}
+
+// WithRetryAfter returns a copy telling the client when to try again
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	c := e.derive()
+	c.RetryAfter = d
+	return c
+}
+
+// WithData returns a copy carrying a payload for the client
+func (e *Error) WithData(data interface{}) *Error {
+	d := e.derive()
+	d.Data = data
+	return d
+}
+
+// Wrap returns a copy recording the underlying error
+func (e *Error) Wrap(cause error) *Error {
+	d := e.derive()
+	d.cause = cause
+	return d
+}
+
+// derive copies e for a more specific error that still matches e
+func (e *Error) derive() *Error {
+	d := *e
+	d.base = e
+	d.cause = nil
+	d.Fields = make(map[string]string, len(e.Fields))
+	for field, problem := range e.Fields {
+		d.Fields[field] = problem
+	}
+	return &d
+}
@@ -0,0 +1,60 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDerivedErrorsMatchTheirSentinel(t *testing.T) {
+	sentinel := Validation(CodeValidation, "invalid item data")
+	other := Validation(CodeValidation, "invalid collection data")
+	cause := errors.New("connection reset")
+
+	derived := []error{
+		sentinel.Field("name", "name is required"),
+		sentinel.Withf("at most %d", 3),
+		sentinel.WithFields(map[string]string{"name": "is required"}),
+		sentinel.WithRetryAfter(time.Second),
+		sentinel.WithData("payload"),
+		sentinel.Wrap(cause),
+		fmt.Errorf("create item: %w", sentinel.Field("name", "name is required")),
+	}
+	for _, err := range derived {
+		if !errors.Is(err, sentinel) {
+			t.Errorf("%v does not match its sentinel", err)
+		}
+		if errors.Is(err, other) {
+			t.Errorf("%v matches another sentinel with the same code", err)
+		}
+		var appErr *Error
+		if !errors.As(err, &appErr) || appErr.Kind != KindValidation || appErr.Code != CodeValidation {
+			t.Errorf("%v: errors.As = %+v", err, appErr)
+		}
+	}
+
+	if !errors.Is(sentinel.Wrap(cause), cause) {
+		t.Error("wrapped error does not match its cause")
+	}
+}
+
+func TestFieldAndMessage(t *testing.T) {
+	sentinel := Validation(CodeValidation, "invalid item data")
+
+	err := sentinel.Field("price", "price must not be negative").Field("name", "name is required")
+	if err.Message != "invalid item data: price must not be negative: name is required" {
+		t.Errorf("message = %q", err.Message)
+	}
+	if len(err.Fields) != 2 || err.Fields["name"] != "name is required" {
+		t.Errorf("fields = %v", err.Fields)
+	}
+	if len(sentinel.Fields) != 0 || sentinel.Message != "invalid item data" {
+		t.Errorf("sentinel was modified: %+v", sentinel)
+	}
+
+	wrapped := NotFound("ITEM_NOT_FOUND", "Item not found").Wrap(errors.New("record not found"))
+	if wrapped.Message != "Item not found" || wrapped.Error() != "Item not found: record not found" {
+		t.Errorf("wrapped message = %q, error = %q", wrapped.Message, wrapped.Error())
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"strconv"
 	"strings"
@@ -12,25 +13,37 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Environment string
-	LogLevel    string
-	Server      ServerConfig
-	Database    DatabaseConfig
-	Redis       RedisConfig
-	JWT         JWTConfig
-	CORS        CORSConfig
-	API         APIConfig
+	Environment   string
+	LogLevel      string
+	Server        ServerConfig
+	Database      DatabaseConfig
+	Redis         RedisConfig
+	JWT           JWTConfig
+	Google        GoogleConfig
+	OAuth         OAuthConfig
+	Mail          MailConfig
+	Verification  EmailVerificationConfig
+	PasswordReset PasswordResetConfig
+	Login         LoginProtectionConfig
+	MFA           MFAConfig
+	PasswordHash  PasswordHashConfig
+	CORS          CORSConfig
+	API           APIConfig
+	PriceAlerts   PriceAlertConfig
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
 	Address string
 	Port    int
+	// TrustedProxies are the proxy IPs or CIDRs whose X-Forwarded-For header is believed
+	TrustedProxies []string
 }
 
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
-	URL string
+	URL         string
+	AutoMigrate bool // apply pending migrations when the server starts
 }
 
 // RedisConfig holds Redis configuration
@@ -42,8 +55,96 @@ type RedisConfig struct {
 
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	Secret     string
-	Expiration time.Duration
+	Secret            string
+	Expiration        time.Duration // access token lifetime
+	RefreshExpiration time.Duration // refresh token lifetime, renewed on every refresh
+}
+
+// GoogleConfig holds Google sign-in configuration. Sign-in is disabled when
+// no client IDs are set. JWKSFile, when set, is used instead of JWKSURL.
+type GoogleConfig struct {
+	ClientIDs    []string
+	JWKSURL      string
+	JWKSFile     string
+	JWKSCacheTTL time.Duration // used when the JWKS response has no max-age
+}
+
+// OAuthConfig holds authorization-code sign-in providers. A provider is
+// enabled when its client ID is set.
+type OAuthConfig struct {
+	Google   OAuthProviderConfig
+	Facebook OAuthProviderConfig
+	Apple    AppleOAuthConfig
+}
+
+// OAuthProviderConfig holds an OAuth client registration
+type OAuthProviderConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// AppleOAuthConfig holds a Sign in with Apple registration. The client secret
+// is generated from the team's private key.
+type AppleOAuthConfig struct {
+	ClientID       string // the Services ID
+	TeamID         string
+	KeyID          string
+	PrivateKeyFile string
+	RedirectURL    string
+}
+
+// MailConfig holds outgoing email configuration. The outbox driver writes
+// messages to OutboxDir instead of sending them.
+type MailConfig struct {
+	Driver       string // "smtp" or "outbox"
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	OutboxDir    string
+	AppURL       string // base URL of the web app that emailed links point at
+}
+
+// EmailVerificationConfig holds email verification configuration
+type EmailVerificationConfig struct {
+	TokenTTL                     time.Duration
+	ResendInterval               time.Duration
+	RequiredForPublicCollections bool
+}
+
+// PasswordResetConfig holds password reset configuration
+type PasswordResetConfig struct {
+	TokenTTL       time.Duration
+	ResendInterval time.Duration
+}
+
+// LoginProtectionConfig holds sign-in rate limit and lockout configuration
+type LoginProtectionConfig struct {
+	RateLimitWindow    time.Duration
+	IPLimit            int // attempts per window from one IP address
+	EmailLimit         int // attempts per window against one email
+	LockoutThreshold   int // consecutive wrong passwords that lock an account
+	LockoutDuration    time.Duration
+	MaxLockoutDuration time.Duration
+}
+
+// MFAConfig holds two-factor authentication configuration
+type MFAConfig struct {
+	// EncryptionKey encrypts TOTP secrets at rest; the JWT secret is used when
+	// empty. Changing it invalidates every enrolled authenticator.
+	EncryptionKey string
+}
+
+// PasswordHashConfig holds Argon2id parameters for password hashes. Stored
+// hashes with other parameters are upgraded when their owner next logs in.
+type PasswordHashConfig struct {
+	Time       int // iterations
+	MemoryKiB  int
+	Threads    int
+	SaltLength int // bytes
+	KeyLength  int // bytes
 }
 
 // CORSConfig holds CORS configuration
@@ -56,6 +157,20 @@ type APIConfig struct {
 	Prefix string
 }
 
+// PriceAlertConfig holds price alert worker configuration
+type PriceAlertConfig struct {
+	Enabled        bool
+	Interval       time.Duration // how often the worker looks for due alerts
+	CheckEvery     time.Duration // minimum time between checks of the same alert
+	BatchSize      int
+	Concurrency    int
+	DomainInterval time.Duration // minimum spacing between requests to one domain
+	MaxBackoff     time.Duration
+	RequestTimeout time.Duration
+	UserAgent      string
+	AllowPrivate   bool // fetch loopback and private addresses, for local development only
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	// Load .env file if it exists
@@ -67,11 +182,13 @@ func Load() (*Config, error) {
 		Environment: getEnv("ENVIRONMENT", "development"),
 		LogLevel:    getEnv("LOG_LEVEL", "info"),
 		Server: ServerConfig{
-			Address: getEnv("SERVER_ADDRESS", "localhost:8080"),
-			Port:    getEnvAsInt("SERVER_PORT", 8080),
+			Address:        getEnv("SERVER_ADDRESS", "localhost:8080"),
+			Port:           getEnvAsInt("SERVER_PORT", 8080),
+			TrustedProxies: getEnvAsSlice("TRUSTED_PROXIES", nil),
 		},
 		Database: DatabaseConfig{
-			URL: getEnv("DATABASE_URL", "postgresql://cliffordxu@localhost:5432/digital_wardrobe_go?sslmode=disable"),
+			URL:         getEnv("DATABASE_URL", "postgresql://cliffordxu@localhost:5432/digital_wardrobe_go?sslmode=disable"),
+			AutoMigrate: getEnvAsBool("DATABASE_AUTO_MIGRATE", true),
 		},
 		Redis: RedisConfig{
 			URL:      getEnv("REDIS_URL", ""),
@@ -79,8 +196,71 @@ func Load() (*Config, error) {
 			DB:       getEnvAsInt("REDIS_DB", 0),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-this-in-production"),
-			Expiration: getEnvAsDuration("JWT_EXPIRATION", 24*time.Hour),
+			Secret:            getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-this-in-production"),
+			Expiration:        getEnvAsDuration("JWT_EXPIRATION", 15*time.Minute),
+			RefreshExpiration: getEnvAsDuration("JWT_REFRESH_EXPIRATION", 30*24*time.Hour),
+		},
+		Google: GoogleConfig{
+			ClientIDs:    getEnvAsSlice("GOOGLE_CLIENT_IDS", nil),
+			JWKSURL:      getEnv("GOOGLE_JWKS_URL", "https://www.googleapis.com/oauth2/v3/certs"),
+			JWKSFile:     getEnv("GOOGLE_JWKS_FILE", ""),
+			JWKSCacheTTL: getEnvAsDuration("GOOGLE_JWKS_CACHE_TTL", time.Hour),
+		},
+		OAuth: OAuthConfig{
+			Google: OAuthProviderConfig{
+				ClientID:     getEnv("OAUTH_GOOGLE_CLIENT_ID", ""),
+				ClientSecret: getEnv("OAUTH_GOOGLE_CLIENT_SECRET", ""),
+				RedirectURL:  getEnv("OAUTH_GOOGLE_REDIRECT_URL", ""),
+			},
+			Facebook: OAuthProviderConfig{
+				ClientID:     getEnv("OAUTH_FACEBOOK_CLIENT_ID", ""),
+				ClientSecret: getEnv("OAUTH_FACEBOOK_CLIENT_SECRET", ""),
+				RedirectURL:  getEnv("OAUTH_FACEBOOK_REDIRECT_URL", ""),
+			},
+			Apple: AppleOAuthConfig{
+				ClientID:       getEnv("OAUTH_APPLE_CLIENT_ID", ""),
+				TeamID:         getEnv("OAUTH_APPLE_TEAM_ID", ""),
+				KeyID:          getEnv("OAUTH_APPLE_KEY_ID", ""),
+				PrivateKeyFile: getEnv("OAUTH_APPLE_PRIVATE_KEY_FILE", ""),
+				RedirectURL:    getEnv("OAUTH_APPLE_REDIRECT_URL", ""),
+			},
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "outbox"),
+			From:         getEnv("MAIL_FROM", "Digital Wardrobe <no-reply@localhost>"),
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnvAsInt("SMTP_PORT", 587),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			OutboxDir:    getEnv("MAIL_OUTBOX_DIR", "./tmp/outbox"),
+			AppURL:       getEnv("APP_URL", "http://localhost:3000"),
+		},
+		Verification: EmailVerificationConfig{
+			TokenTTL:                     getEnvAsDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+			ResendInterval:               getEnvAsDuration("EMAIL_VERIFICATION_RESEND_INTERVAL", time.Minute),
+			RequiredForPublicCollections: getEnvAsBool("EMAIL_VERIFICATION_REQUIRED_FOR_PUBLIC_COLLECTIONS", true),
+		},
+		PasswordReset: PasswordResetConfig{
+			TokenTTL:       getEnvAsDuration("PASSWORD_RESET_TTL", time.Hour),
+			ResendInterval: getEnvAsDuration("PASSWORD_RESET_RESEND_INTERVAL", time.Minute),
+		},
+		Login: LoginProtectionConfig{
+			RateLimitWindow:    getEnvAsDuration("LOGIN_RATE_LIMIT_WINDOW", 15*time.Minute),
+			IPLimit:            getEnvAsInt("LOGIN_RATE_LIMIT_PER_IP", 50),
+			EmailLimit:         getEnvAsInt("LOGIN_RATE_LIMIT_PER_EMAIL", 10),
+			LockoutThreshold:   getEnvAsInt("LOGIN_LOCKOUT_THRESHOLD", 5),
+			LockoutDuration:    getEnvAsDuration("LOGIN_LOCKOUT_DURATION", time.Minute),
+			MaxLockoutDuration: getEnvAsDuration("LOGIN_LOCKOUT_MAX_DURATION", time.Hour),
+		},
+		MFA: MFAConfig{
+			EncryptionKey: getEnv("MFA_ENCRYPTION_KEY", ""),
+		},
+		PasswordHash: PasswordHashConfig{
+			Time:       getEnvAsInt("ARGON2_TIME", 3),
+			MemoryKiB:  getEnvAsInt("ARGON2_MEMORY_KIB", 64*1024),
+			Threads:    getEnvAsInt("ARGON2_THREADS", 4),
+			SaltLength: getEnvAsInt("ARGON2_SALT_LENGTH", 16),
+			KeyLength:  getEnvAsInt("ARGON2_KEY_LENGTH", 32),
 		},
 		CORS: CORSConfig{
 			Origins: getEnvAsSlice("CORS_ORIGIN", []string{
@@ -92,6 +272,18 @@ func Load() (*Config, error) {
 		API: APIConfig{
 			Prefix: getEnv("API_PREFIX", "/api/v1"),
 		},
+		PriceAlerts: PriceAlertConfig{
+			Enabled:        getEnvAsBool("PRICE_ALERTS_ENABLED", true),
+			Interval:       getEnvAsDuration("PRICE_ALERTS_INTERVAL", time.Minute),
+			CheckEvery:     getEnvAsDuration("PRICE_ALERTS_CHECK_EVERY", 6*time.Hour),
+			BatchSize:      getEnvAsInt("PRICE_ALERTS_BATCH_SIZE", 200),
+			Concurrency:    getEnvAsInt("PRICE_ALERTS_CONCURRENCY", 4),
+			DomainInterval: getEnvAsDuration("PRICE_ALERTS_DOMAIN_INTERVAL", 5*time.Second),
+			MaxBackoff:     getEnvAsDuration("PRICE_ALERTS_MAX_BACKOFF", time.Hour),
+			RequestTimeout: getEnvAsDuration("PRICE_ALERTS_REQUEST_TIMEOUT", 15*time.Second),
+			UserAgent:      getEnv("PRICE_ALERTS_USER_AGENT", "DigitalWardrobeBot/1.0 (+price alerts)"),
+			AllowPrivate:   getEnvAsBool("PRICE_ALERTS_ALLOW_PRIVATE_HOSTS", false),
+		},
 	}
 
 	// Validate required fields
@@ -116,6 +308,45 @@ func (c *Config) validate() error {
 		return fmt.Errorf("JWT_SECRET must be at least 32 characters")
 	}
 
+	for name, p := range map[string]OAuthProviderConfig{"GOOGLE": c.OAuth.Google, "FACEBOOK": c.OAuth.Facebook} {
+		if p.ClientID != "" && (p.ClientSecret == "" || p.RedirectURL == "") {
+			return fmt.Errorf("OAUTH_%s_CLIENT_SECRET and OAUTH_%s_REDIRECT_URL are required when OAUTH_%s_CLIENT_ID is set", name, name, name)
+		}
+	}
+	if a := c.OAuth.Apple; a.ClientID != "" && (a.TeamID == "" || a.KeyID == "" || a.PrivateKeyFile == "" || a.RedirectURL == "") {
+		return fmt.Errorf("OAUTH_APPLE_TEAM_ID, OAUTH_APPLE_KEY_ID, OAUTH_APPLE_PRIVATE_KEY_FILE and OAUTH_APPLE_REDIRECT_URL are required when OAUTH_APPLE_CLIENT_ID is set")
+	}
+
+	if c.MFA.EncryptionKey != "" && len(c.MFA.EncryptionKey) < 32 {
+		return fmt.Errorf("MFA_ENCRYPTION_KEY must be at least 32 characters")
+	}
+
+	if h := c.PasswordHash; h.Time < 1 || h.MemoryKiB < 1 || h.Threads < 1 || h.Threads > 255 || h.SaltLength < 1 || h.KeyLength < 1 {
+		return fmt.Errorf("ARGON2_TIME, ARGON2_MEMORY_KIB, ARGON2_SALT_LENGTH and ARGON2_KEY_LENGTH must be positive and ARGON2_THREADS between 1 and 255")
+	}
+
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("TRUSTED_PROXIES entry %q is not an IP address or CIDR", proxy)
+			}
+		}
+	}
+
+	if c.PriceAlerts.Enabled && c.PriceAlerts.Interval <= 0 {
+		return fmt.Errorf("PRICE_ALERTS_INTERVAL must be positive")
+	}
+
+	switch c.Mail.Driver {
+	case "outbox":
+	case "smtp":
+		if c.Mail.SMTPHost == "" {
+			return fmt.Errorf("SMTP_HOST is required when MAIL_DRIVER is smtp")
+		}
+	default:
+		return fmt.Errorf("MAIL_DRIVER must be smtp or outbox")
+	}
+
 	return nil
 }
 
@@ -137,6 +368,16 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsBool gets an environment variable as a boolean
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 // getEnvAsDuration gets an environment variable as a duration
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
@@ -1,12 +1,11 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
-	"digital-wardrobe-backend/internal/models"
-
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -19,7 +18,8 @@ func New(databaseURL string) (*gorm.DB, error) {
 	
 	// Open database connection
 	db, err := gorm.Open(postgres.Open(databaseURL), &gorm.Config{
-		Logger: gormLogger,
+		Logger:         gormLogger,
+		TranslateError: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -43,41 +43,28 @@ func New(databaseURL string) (*gorm.DB, error) {
 
 	log.Println("✅ Database connected successfully")
 
-	// Auto migrate tables
-	if err := migrateTables(db); err != nil {
-		return nil, fmt.Errorf("failed to migrate tables: %w", err)
-	}
-
 	return db, nil
 }
 
-// migrateTables migrates all tables
-func migrateTables(db *gorm.DB) error {
-	log.Println("🔄 Migrating database tables...")
+// Migrate applies pending schema migrations
+func Migrate(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
 
-	// Enable UUID extension
-	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\"").Error; err != nil {
-		return fmt.Errorf("failed to create UUID extension: %w", err)
+	migrator, err := NewMigrator(sqlDB)
+	if err != nil {
+		return err
 	}
 
-	// Auto migrate all models
-	err := db.AutoMigrate(
-		&models.User{},
-		&models.Session{},
-		&models.Item{},
-		&models.Collection{},
-		&models.CollectionItem{},
-		&models.Follow{},
-		&models.UserAnalytics{},
-		&models.PriceAlert{},
-		&models.AppConfig{},
-		&models.AuditLog{},
-	)
+	log.Println("🔄 Migrating database tables...")
+	applied, err := migrator.Up(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to auto migrate: %w", err)
+		return err
 	}
 
-	log.Println("✅ Database migration completed")
+	log.Printf("✅ Database migration completed (%d applied)", applied)
 	return nil
 }
 
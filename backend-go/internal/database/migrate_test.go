@@ -0,0 +1,213 @@
+package database
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := LoadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d_%s: versions should be contiguous from 1, want %d", m.Version, m.Name, i+1)
+		}
+		if strings.TrimSpace(m.Down) == "" {
+			t.Errorf("migration %d_%s has no down script", m.Version, m.Name)
+		}
+		if len(m.Checksum) != 64 {
+			t.Errorf("migration %d_%s: checksum %q", m.Version, m.Name, m.Checksum)
+		}
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []int
+		wantErr string
+	}{
+		{
+			name: "ordered by version",
+			files: fstest.MapFS{
+				"m/0010_later.up.sql":    file("SELECT 10"),
+				"m/0002_second.up.sql":   file("SELECT 2"),
+				"m/0002_second.down.sql": file("SELECT -2"),
+			},
+			want: []int{2, 10},
+		},
+		{
+			name:    "bad file name",
+			files:   fstest.MapFS{"m/add_users.sql": file("SELECT 1")},
+			wantErr: "invalid migration file name",
+		},
+		{
+			name:    "down without up",
+			files:   fstest.MapFS{"m/0001_init.down.sql": file("SELECT 1")},
+			wantErr: "has no up script",
+		},
+		{
+			name: "version reused",
+			files: fstest.MapFS{
+				"m/0001_init.up.sql":  file("SELECT 1"),
+				"m/0001_other.up.sql": file("SELECT 1"),
+			},
+			wantErr: "has two names",
+		},
+		{
+			name:    "version zero",
+			files:   fstest.MapFS{"m/0000_init.up.sql": file("SELECT 1")},
+			wantErr: "invalid migration version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := LoadMigrations(tt.files, "m")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadMigrations: %v", err)
+			}
+			if len(migrations) != len(tt.want) {
+				t.Fatalf("got %d migrations, want %d", len(migrations), len(tt.want))
+			}
+			for i, v := range tt.want {
+				if migrations[i].Version != v {
+					t.Errorf("migrations[%d].Version = %d, want %d", i, migrations[i].Version, v)
+				}
+			}
+		})
+	}
+}
+
+// TestMigrateAdoptsAutoMigrateSchema applies every migration to a database
+// AutoMigrate created before versioned migrations. It needs TEST_DATABASE_URL
+// and works in its own schema.
+func TestMigrateAdoptsAutoMigrateSchema(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	const schema = "automigrate_adoption"
+
+	admin, err := New(dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	resetSchema := "DROP SCHEMA IF EXISTS " + schema + " CASCADE"
+	if err := admin.Exec(resetSchema).Error; err != nil {
+		t.Fatalf("drop schema: %v", err)
+	}
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() { admin.Exec(resetSchema) })
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("TEST_DATABASE_URL must be a URL: %v", err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	db, err := New(u.String())
+	if err != nil {
+		t.Fatalf("connect to schema: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql.DB: %v", err)
+	}
+	defer sqlDB.Close()
+
+	baseline, err := os.ReadFile("testdata/automigrate_schema.sql")
+	if err != nil {
+		t.Fatalf("read baseline: %v", err)
+	}
+	ctx := context.Background()
+	for _, stmt := range []string{
+		string(baseline),
+		`INSERT INTO users (id, email) VALUES ('0190b5b0-0000-7000-8000-000000000001', 'ada@example.com')`,
+		`INSERT INTO sessions (id, user_id, token, expires_at) VALUES
+			('0190b5b0-0000-7000-8000-000000000002', '0190b5b0-0000-7000-8000-000000000001', 'kept', now()),
+			('0190b5b0-0000-7000-8000-000000000003', 'user_20240101', 'orphaned', now())`,
+		`INSERT INTO user_analytics (user_id, total_value) VALUES ('0190b5b0-0000-7000-8000-000000000001', 120)`,
+	} {
+		if _, err := sqlDB.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("seed baseline: %v", err)
+		}
+	}
+
+	migrator, err := NewMigrator(sqlDB)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	columns := map[string]string{
+		"users.failed_login_attempts":     "bigint",
+		"users.lockout_count":             "bigint",
+		"users.locked_until":              "timestamp with time zone",
+		"users.unlock_token":              "text",
+		"users.totp_secret":               "text",
+		"users.totp_enabled":              "boolean",
+		"users.totp_last_step":            "bigint",
+		"sessions.user_id":                "uuid",
+		"user_analytics.total_value":      "jsonb",
+		"user_analytics.monthly_activity": "jsonb",
+		"user_analytics.monthly_spending": "jsonb",
+		"user_analytics.priced_items":     "jsonb",
+		"user_analytics.price_total":      "jsonb",
+		"items.search_vector":             "tsvector",
+	}
+	for column, want := range columns {
+		table, name, _ := strings.Cut(column, ".")
+		var got string
+		err := sqlDB.QueryRowContext(ctx,
+			`SELECT data_type FROM information_schema.columns
+			WHERE table_schema = $1 AND table_name = $2 AND column_name = $3`,
+			schema, table, name).Scan(&got)
+		if err != nil {
+			t.Errorf("%s: %v", column, err)
+		} else if got != want {
+			t.Errorf("%s is %s, want %s", column, got, want)
+		}
+	}
+
+	var tokens []string
+	rows, err := sqlDB.QueryContext(ctx, `SELECT token FROM sessions ORDER BY token`)
+	if err != nil {
+		t.Fatalf("list sessions: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var token string
+		if err := rows.Scan(&token); err != nil {
+			t.Fatalf("scan session: %v", err)
+		}
+		tokens = append(tokens, token)
+	}
+	if len(tokens) != 1 || tokens[0] != "kept" {
+		t.Errorf("sessions after migrating = %v, want only the one with a known user", tokens)
+	}
+}
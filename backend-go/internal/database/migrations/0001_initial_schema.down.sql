@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS app_config;
+DROP TABLE IF EXISTS price_snapshots;
+DROP TABLE IF EXISTS price_alerts;
+DROP TABLE IF EXISTS user_analytics;
+DROP TABLE IF EXISTS follows;
+DROP TABLE IF EXISTS collection_items;
+DROP TABLE IF EXISTS collections;
+DROP TABLE IF EXISTS items;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS oauth_states;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
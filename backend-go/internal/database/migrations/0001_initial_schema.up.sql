@@ -0,0 +1,294 @@
+-- Baseline schema. Databases created by AutoMigrate before versioned
+-- migrations already have the older tables, so CREATE TABLE IF NOT EXISTS
+-- skips them; the ALTER TABLE statements that follow bring those tables up
+-- to date and do nothing on a fresh database.
+
+CREATE TABLE IF NOT EXISTS users (
+    id                         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    email                      text NOT NULL,
+    username                   text,
+    first_name                 text,
+    last_name                  text,
+    display_name               text,
+    avatar                     text,
+    password_hash              text,
+    is_email_verified          boolean DEFAULT false,
+    email_verification_token   text,
+    email_verification_expires timestamptz,
+    password_reset_token       text,
+    password_reset_expires     timestamptz,
+    failed_login_attempts      bigint NOT NULL DEFAULT 0,
+    lockout_count              bigint NOT NULL DEFAULT 0,
+    locked_until               timestamptz,
+    unlock_token               text,
+    totp_secret                text,
+    totp_enabled               boolean NOT NULL DEFAULT false,
+    totp_last_step             bigint NOT NULL DEFAULT 0,
+    google_id                  text,
+    facebook_id                text,
+    apple_id                   text,
+    bio                        text,
+    birth_date                 timestamptz,
+    gender                     text,
+    location                   text,
+    website                    text,
+    is_private                 boolean DEFAULT false,
+    allow_analytics            boolean DEFAULT true,
+    email_notifications        boolean DEFAULT true,
+    push_notifications         boolean DEFAULT true,
+    subscription_tier          text DEFAULT 'free',
+    subscription_expires       timestamptz,
+    created_at                 timestamptz,
+    updated_at                 timestamptz,
+    last_login_at              timestamptz,
+    is_active                  boolean DEFAULT true
+);
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS failed_login_attempts bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS lockout_count         bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS locked_until          timestamptz,
+    ADD COLUMN IF NOT EXISTS unlock_token          text,
+    ADD COLUMN IF NOT EXISTS totp_secret           text,
+    ADD COLUMN IF NOT EXISTS totp_enabled          boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS totp_last_step        bigint NOT NULL DEFAULT 0;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_google_id ON users (google_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_facebook_id ON users (facebook_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_apple_id ON users (apple_id);
+CREATE INDEX IF NOT EXISTS idx_users_email_verification_token ON users (email_verification_token);
+CREATE INDEX IF NOT EXISTS idx_users_unlock_token ON users (unlock_token);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id            text PRIMARY KEY,
+    user_id       uuid,
+    token         text NOT NULL,
+    refresh_token text,
+    expires_at    timestamptz NOT NULL,
+    device_info   text,
+    ip_address    text,
+    user_agent    text,
+    is_active     boolean DEFAULT true,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    CONSTRAINT fk_users_sessions FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+-- AutoMigrate may have left user_id as text, without the foreign key.
+-- Sessions that don't belong to a user can't be converted; drop them.
+DELETE FROM sessions s
+WHERE s.user_id IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id::text = s.user_id::text);
+ALTER TABLE sessions ALTER COLUMN user_id TYPE uuid USING user_id::uuid;
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conrelid = 'sessions'::regclass AND conname = 'fk_users_sessions'
+    ) THEN
+        ALTER TABLE sessions ADD CONSTRAINT fk_users_sessions
+            FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+    END IF;
+END $$;
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_token ON sessions (token);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_refresh_token ON sessions (refresh_token);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions (expires_at);
+
+CREATE TABLE IF NOT EXISTS oauth_states (
+    id            text PRIMARY KEY,
+    provider      text NOT NULL,
+    code_verifier text NOT NULL,
+    nonce         text NOT NULL,
+    user_id       text,
+    expires_at    timestamptz NOT NULL,
+    created_at    timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_oauth_states_user_id ON oauth_states (user_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_states_expires_at ON oauth_states (expires_at);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id         text PRIMARY KEY,
+    user_id    uuid NOT NULL,
+    code_hash  text NOT NULL,
+    used_at    timestamptz,
+    created_at timestamptz,
+    CONSTRAINT fk_mfa_recovery_codes_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes (user_id);
+
+CREATE TABLE IF NOT EXISTS items (
+    id                uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id           uuid NOT NULL,
+    name              text NOT NULL,
+    brand             text,
+    description       text,
+    category          text NOT NULL,
+    subcategory       text,
+    price             decimal(10,2),
+    original_price    decimal(10,2),
+    currency          text DEFAULT 'USD',
+    sku               text,
+    size              text,
+    color             text,
+    material          text,
+    care_instructions text,
+    status            text DEFAULT 'want',
+    purchase_date     timestamptz,
+    purchase_location text,
+    images            jsonb,
+    primary_image     text,
+    original_url      text,
+    affiliate_url     text,
+    tags              jsonb,
+    notes             text,
+    is_public         boolean DEFAULT false,
+    likes             bigint DEFAULT 0,
+    views             bigint DEFAULT 0,
+    created_at        timestamptz,
+    updated_at        timestamptz,
+    archived_at       timestamptz,
+    CONSTRAINT fk_users_items FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_items_user_id ON items (user_id);
+CREATE INDEX IF NOT EXISTS idx_items_category ON items (category);
+CREATE INDEX IF NOT EXISTS idx_items_status ON items (status);
+CREATE INDEX IF NOT EXISTS idx_items_is_public ON items (is_public);
+CREATE INDEX IF NOT EXISTS idx_items_created_at ON items (created_at);
+
+CREATE TABLE IF NOT EXISTS collections (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     uuid NOT NULL,
+    name        text NOT NULL,
+    description text,
+    color       text,
+    icon        text,
+    is_public   boolean DEFAULT false,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    CONSTRAINT fk_users_collections FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_collections_user_id ON collections (user_id);
+CREATE INDEX IF NOT EXISTS idx_collections_is_public ON collections (is_public);
+CREATE INDEX IF NOT EXISTS idx_collections_created_at ON collections (created_at);
+
+CREATE TABLE IF NOT EXISTS collection_items (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    collection_id uuid NOT NULL,
+    item_id       uuid NOT NULL,
+    "order"       bigint DEFAULT 0,
+    notes         text,
+    created_at    timestamptz,
+    CONSTRAINT fk_collections_items FOREIGN KEY (collection_id) REFERENCES collections (id) ON DELETE CASCADE,
+    CONSTRAINT fk_items_collection_items FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_collection_items_collection_id ON collection_items (collection_id);
+CREATE INDEX IF NOT EXISTS idx_collection_items_item_id ON collection_items (item_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collection_items_membership ON collection_items (collection_id, item_id);
+
+CREATE TABLE IF NOT EXISTS follows (
+    id           uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    follower_id  uuid NOT NULL,
+    following_id uuid NOT NULL,
+    created_at   timestamptz,
+    CONSTRAINT fk_users_follows FOREIGN KEY (follower_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_users_followers FOREIGN KEY (following_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_follows_follower_id ON follows (follower_id);
+CREATE INDEX IF NOT EXISTS idx_follows_following_id ON follows (following_id);
+
+CREATE TABLE IF NOT EXISTS user_analytics (
+    id                       uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id                  uuid NOT NULL,
+    total_items              bigint DEFAULT 0,
+    total_value              decimal(10,2) DEFAULT 0,
+    total_spent              decimal(10,2) DEFAULT 0,
+    average_item_price       decimal(10,2) DEFAULT 0,
+    category_breakdown       jsonb,
+    brand_breakdown          jsonb,
+    color_breakdown          jsonb,
+    most_active_month        text,
+    preferred_brands         jsonb,
+    average_monthly_spending decimal(10,2) DEFAULT 0,
+    monthly_activity         jsonb,
+    monthly_spending         jsonb,
+    priced_items             bigint DEFAULT 0,
+    price_total              decimal(12,2) DEFAULT 0,
+    total_logins             bigint DEFAULT 0,
+    streak                   bigint DEFAULT 0,
+    longest_streak           bigint DEFAULT 0,
+    last_calculated_at       timestamptz,
+    updated_at               timestamptz,
+    CONSTRAINT fk_users_analytics FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+ALTER TABLE user_analytics
+    ADD COLUMN IF NOT EXISTS monthly_activity jsonb,
+    ADD COLUMN IF NOT EXISTS monthly_spending jsonb,
+    ADD COLUMN IF NOT EXISTS priced_items     bigint DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS price_total      decimal(12,2) DEFAULT 0;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_analytics_user_id ON user_analytics (user_id);
+
+CREATE TABLE IF NOT EXISTS price_alerts (
+    id                 uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id            uuid NOT NULL,
+    item_id            uuid,
+    product_url        text NOT NULL,
+    target_price       decimal(10,2) NOT NULL,
+    current_price      decimal(10,2),
+    currency           text DEFAULT 'USD',
+    is_active          boolean DEFAULT true,
+    is_triggered       boolean DEFAULT false,
+    triggered_at       timestamptz,
+    email_notification boolean DEFAULT true,
+    push_notification  boolean DEFAULT true,
+    created_at         timestamptz,
+    updated_at         timestamptz,
+    last_checked_at    timestamptz,
+    CONSTRAINT fk_users_price_alerts FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_items_price_alerts FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_price_alerts_user_id ON price_alerts (user_id);
+CREATE INDEX IF NOT EXISTS idx_price_alerts_item_id ON price_alerts (item_id);
+CREATE INDEX IF NOT EXISTS idx_price_alerts_is_active ON price_alerts (is_active);
+
+CREATE TABLE IF NOT EXISTS price_snapshots (
+    id             uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    item_id        uuid NOT NULL,
+    user_id        text NOT NULL,
+    price          decimal(10,2) NOT NULL,
+    original_price decimal(10,2),
+    currency       text NOT NULL DEFAULT 'USD',
+    source         text NOT NULL,
+    observed_at    timestamptz NOT NULL,
+    created_at     timestamptz,
+    CONSTRAINT fk_items_price_history FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_price_snapshots_user_id ON price_snapshots (user_id);
+CREATE INDEX IF NOT EXISTS idx_price_snapshots_item_currency_observed ON price_snapshots (item_id, currency, observed_at);
+
+CREATE TABLE IF NOT EXISTS app_config (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    key         text NOT NULL,
+    value       text NOT NULL,
+    description text,
+    created_at  timestamptz,
+    updated_at  timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_app_config_key ON app_config (key);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       uuid,
+    action        text NOT NULL,
+    resource_type text NOT NULL,
+    resource_id   text,
+    details       jsonb,
+    ip_address    text,
+    user_agent    text,
+    created_at    timestamptz,
+    CONSTRAINT fk_audit_logs_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs (user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_resource_type ON audit_logs (resource_type);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_resource_id ON audit_logs (resource_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
@@ -0,0 +1,13 @@
+-- Full-text search over items.
+-- Weights: A = name/brand, B = tags/color/material, C = description, D = notes.
+ALTER TABLE items ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(brand, '')), 'A') ||
+    setweight(jsonb_to_tsvector('english', coalesce(tags, '[]'::jsonb), '["string"]'), 'B') ||
+    setweight(to_tsvector('english', coalesce(color, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(material, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'C') ||
+    setweight(to_tsvector('english', coalesce(notes, '')), 'D')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_items_search_vector ON items USING GIN (search_vector);
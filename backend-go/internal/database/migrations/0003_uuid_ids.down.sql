@@ -0,0 +1,5 @@
+-- Column types only; IDs assigned by the up migration are kept
+ALTER TABLE price_snapshots ALTER COLUMN user_id TYPE text USING user_id::text;
+ALTER TABLE oauth_states ALTER COLUMN user_id TYPE text USING user_id::text;
+ALTER TABLE mfa_recovery_codes ALTER COLUMN id TYPE text USING id::text;
+ALTER TABLE sessions ALTER COLUMN id TYPE text USING id::text;
@@ -0,0 +1,36 @@
+-- Every record ID is now a UUIDv7 from internal/ids. Tables created with
+-- uuid IDs can't hold the old "item_20240101..." style IDs, so only the
+-- columns that were text need their existing rows migrated.
+
+-- A UUIDv7 for a past moment, so migrated rows keep sorting by creation time
+CREATE FUNCTION pg_temp.uuid_v7_at(ts timestamptz) RETURNS uuid LANGUAGE sql VOLATILE AS $$
+    SELECT (
+        lpad(to_hex(floor(extract(epoch FROM coalesce(ts, now())) * 1000)::bigint), 12, '0') ||
+        '7' || substr(md5(random()::text), 1, 3) ||
+        to_hex(8 + floor(random() * 4)::int) || substr(md5(random()::text), 1, 15)
+    )::uuid
+$$;
+
+-- Access and refresh tokens carry the session ID, so sessions with old IDs
+-- can't be matched once renamed; their users sign in again
+DELETE FROM sessions
+WHERE id !~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$';
+ALTER TABLE sessions ALTER COLUMN id TYPE uuid USING id::uuid;
+
+UPDATE mfa_recovery_codes SET id = pg_temp.uuid_v7_at(created_at)::text
+WHERE id !~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$';
+ALTER TABLE mfa_recovery_codes ALTER COLUMN id TYPE uuid USING id::uuid;
+
+-- OAuth states last minutes; drop any that can't be converted
+DELETE FROM oauth_states
+WHERE user_id !~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$';
+ALTER TABLE oauth_states ALTER COLUMN user_id TYPE uuid USING user_id::uuid;
+
+-- A snapshot belongs to its item's owner
+UPDATE price_snapshots ps SET user_id = i.user_id::text
+FROM items i
+WHERE ps.item_id = i.id
+  AND ps.user_id !~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$';
+ALTER TABLE price_snapshots ALTER COLUMN user_id TYPE uuid USING user_id::uuid;
+
+DROP FUNCTION pg_temp.uuid_v7_at(timestamptz);
@@ -0,0 +1,16 @@
+-- Stored analytics are recomputed on the next overview request
+DELETE FROM user_analytics;
+
+ALTER TABLE user_analytics
+    ALTER COLUMN total_value TYPE decimal(10,2) USING 0,
+    ALTER COLUMN total_value SET DEFAULT 0,
+    ALTER COLUMN total_spent TYPE decimal(10,2) USING 0,
+    ALTER COLUMN total_spent SET DEFAULT 0,
+    ALTER COLUMN average_item_price TYPE decimal(10,2) USING 0,
+    ALTER COLUMN average_item_price SET DEFAULT 0,
+    ALTER COLUMN average_monthly_spending TYPE decimal(10,2) USING 0,
+    ALTER COLUMN average_monthly_spending SET DEFAULT 0,
+    ALTER COLUMN priced_items TYPE bigint USING 0,
+    ALTER COLUMN priced_items SET DEFAULT 0,
+    ALTER COLUMN price_total TYPE decimal(12,2) USING 0,
+    ALTER COLUMN price_total SET DEFAULT 0;
@@ -0,0 +1,18 @@
+-- Money totals in user_analytics are now kept per currency. The old sums mixed
+-- currencies and can't be split, so stored analytics are cleared; each user's
+-- are recomputed from their items on the next overview request.
+DELETE FROM user_analytics;
+
+ALTER TABLE user_analytics
+    ALTER COLUMN total_value DROP DEFAULT,
+    ALTER COLUMN total_value TYPE jsonb USING '{}'::jsonb,
+    ALTER COLUMN total_spent DROP DEFAULT,
+    ALTER COLUMN total_spent TYPE jsonb USING '{}'::jsonb,
+    ALTER COLUMN average_item_price DROP DEFAULT,
+    ALTER COLUMN average_item_price TYPE jsonb USING '{}'::jsonb,
+    ALTER COLUMN average_monthly_spending DROP DEFAULT,
+    ALTER COLUMN average_monthly_spending TYPE jsonb USING '{}'::jsonb,
+    ALTER COLUMN priced_items DROP DEFAULT,
+    ALTER COLUMN priced_items TYPE jsonb USING '{}'::jsonb,
+    ALTER COLUMN price_total DROP DEFAULT,
+    ALTER COLUMN price_total TYPE jsonb USING '{}'::jsonb;
@@ -0,0 +1,171 @@
+-- Tables as AutoMigrate created them from the models before versioned
+-- migrations, less indexes and foreign keys. Session.UserID was tagged
+-- type:text, so sessions.user_id is text here.
+
+CREATE TABLE users (
+    id                         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    email                      text NOT NULL,
+    username                   text,
+    first_name                 text,
+    last_name                  text,
+    display_name               text,
+    avatar                     text,
+    password_hash              text,
+    is_email_verified          boolean DEFAULT false,
+    email_verification_token   text,
+    email_verification_expires timestamptz,
+    password_reset_token       text,
+    password_reset_expires     timestamptz,
+    google_id                  text,
+    facebook_id                text,
+    apple_id                   text,
+    bio                        text,
+    birth_date                 timestamptz,
+    gender                     text,
+    location                   text,
+    website                    text,
+    is_private                 boolean DEFAULT false,
+    allow_analytics            boolean DEFAULT true,
+    email_notifications        boolean DEFAULT true,
+    push_notifications         boolean DEFAULT true,
+    subscription_tier          text DEFAULT 'free',
+    subscription_expires       timestamptz,
+    created_at                 timestamptz,
+    updated_at                 timestamptz,
+    last_login_at              timestamptz,
+    is_active                  boolean DEFAULT true
+);
+
+CREATE TABLE sessions (
+    id            text PRIMARY KEY,
+    user_id       text,
+    token         text NOT NULL,
+    refresh_token text,
+    expires_at    timestamptz NOT NULL,
+    device_info   text,
+    ip_address    text,
+    user_agent    text,
+    is_active     boolean DEFAULT true,
+    created_at    timestamptz,
+    updated_at    timestamptz
+);
+
+CREATE TABLE items (
+    id                uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id           uuid NOT NULL,
+    name              text NOT NULL,
+    brand             text,
+    description       text,
+    category          text NOT NULL,
+    subcategory       text,
+    price             decimal(10,2),
+    original_price    decimal(10,2),
+    currency          text DEFAULT 'USD',
+    sku               text,
+    size              text,
+    color             text,
+    material          text,
+    care_instructions text,
+    status            text DEFAULT 'want',
+    purchase_date     timestamptz,
+    purchase_location text,
+    images            jsonb,
+    primary_image     text,
+    original_url      text,
+    affiliate_url     text,
+    tags              jsonb,
+    notes             text,
+    is_public         boolean DEFAULT false,
+    likes             bigint DEFAULT 0,
+    views             bigint DEFAULT 0,
+    created_at        timestamptz,
+    updated_at        timestamptz,
+    archived_at       timestamptz
+);
+
+CREATE TABLE collections (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     uuid NOT NULL,
+    name        text NOT NULL,
+    description text,
+    color       text,
+    icon        text,
+    is_public   boolean DEFAULT false,
+    created_at  timestamptz,
+    updated_at  timestamptz
+);
+
+CREATE TABLE collection_items (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    collection_id uuid NOT NULL,
+    item_id       uuid NOT NULL,
+    "order"       bigint DEFAULT 0,
+    notes         text,
+    created_at    timestamptz
+);
+
+CREATE TABLE follows (
+    id           uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    follower_id  uuid NOT NULL,
+    following_id uuid NOT NULL,
+    created_at   timestamptz
+);
+
+CREATE TABLE user_analytics (
+    id                       uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id                  uuid NOT NULL,
+    total_items              bigint DEFAULT 0,
+    total_value              decimal(10,2) DEFAULT 0,
+    total_spent              decimal(10,2) DEFAULT 0,
+    average_item_price       decimal(10,2) DEFAULT 0,
+    category_breakdown       jsonb,
+    brand_breakdown          jsonb,
+    color_breakdown          jsonb,
+    most_active_month        text,
+    preferred_brands         jsonb,
+    average_monthly_spending decimal(10,2) DEFAULT 0,
+    total_logins             bigint DEFAULT 0,
+    streak                   bigint DEFAULT 0,
+    longest_streak           bigint DEFAULT 0,
+    last_calculated_at       timestamptz,
+    updated_at               timestamptz
+);
+
+CREATE TABLE price_alerts (
+    id                 uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id            uuid NOT NULL,
+    item_id            uuid,
+    product_url        text NOT NULL,
+    target_price       decimal(10,2) NOT NULL,
+    current_price      decimal(10,2),
+    currency           text DEFAULT 'USD',
+    is_active          boolean DEFAULT true,
+    is_triggered       boolean DEFAULT false,
+    triggered_at       timestamptz,
+    email_notification boolean DEFAULT true,
+    push_notification  boolean DEFAULT true,
+    created_at         timestamptz,
+    updated_at         timestamptz,
+    last_checked_at    timestamptz
+);
+
+CREATE TABLE app_config (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    key         text NOT NULL,
+    value       text NOT NULL,
+    description text,
+    created_at  timestamptz,
+    updated_at  timestamptz
+);
+
+CREATE TABLE audit_logs (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       uuid,
+    action        text NOT NULL,
+    resource_type text NOT NULL,
+    resource_id   text,
+    details       jsonb,
+    ip_address    text,
+    user_agent    text,
+    created_at    timestamptz
+);
@@ -0,0 +1,65 @@
+package extraction
+
+import (
+	"strings"
+
+	"digital-wardrobe-backend/internal/models"
+)
+
+// categoryKeywords maps item categories to words that suggest them. Order
+// matters: more specific categories are checked first.
+var categoryKeywords = []struct {
+	category string
+	keywords []string
+}{
+	{models.CategoryDresses, []string{"dress", "gown", "jumpsuit", "romper"}},
+	{models.CategoryOuterwear, []string{"jacket", "coat", "parka", "blazer", "puffer", "trench", "windbreaker", "vest", "gilet", "anorak"}},
+	{models.CategoryShoes, []string{"shoe", "sneaker", "trainer", "boot", "sandal", "heel", "loafer", "slipper", "mule", "oxford", "espadrille", "footwear"}},
+	{models.CategoryBottoms, []string{"jean", "pant", "trouser", "short", "skirt", "legging", "chino", "jogger", "culotte"}},
+	{models.CategoryTops, []string{"shirt", "tee", "t-shirt", "top", "blouse", "sweater", "hoodie", "sweatshirt", "cardigan", "tank", "polo", "jumper", "pullover", "camisole"}},
+	{models.CategoryAccessories, []string{"bag", "belt", "hat", "cap", "scarf", "glove", "sunglasses", "watch", "wallet", "jewelry", "jewellery", "necklace", "bracelet", "earring", "ring", "tie", "backpack", "tote", "purse"}},
+}
+
+// Confidence for a category inferred from structured category data versus the product name
+const (
+	categoryHintConfidence = 0.8
+	categoryNameConfidence = 0.6
+	categoryNoneConfidence = 0.1
+)
+
+// inferCategory maps source category hints, then the product name, to an item category
+func inferCategory(hints []string, name string) (string, float64) {
+	for _, hint := range hints {
+		if category := matchCategory(hint); category != "" {
+			return category, categoryHintConfidence
+		}
+	}
+
+	if category := matchCategory(name); category != "" {
+		return category, categoryNameConfidence
+	}
+
+	return models.CategoryOther, categoryNoneConfidence
+}
+
+// matchCategory returns the first category with a keyword that starts a word in text
+func matchCategory(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z') && r != '-'
+	})
+	if len(words) == 0 {
+		return ""
+	}
+
+	for _, entry := range categoryKeywords {
+		for _, keyword := range entry.keywords {
+			for _, word := range words {
+				if word == keyword || word == keyword+"s" || word == keyword+"es" {
+					return entry.category
+				}
+			}
+		}
+	}
+
+	return ""
+}
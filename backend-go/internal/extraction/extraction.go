@@ -0,0 +1,322 @@
+// Package extraction parses product pages into normalized wardrobe items.
+//
+// Product data is gathered from three structured sources, in decreasing order
+// of trust: JSON-LD Product schema, schema.org microdata, and OpenGraph /
+// product meta tags. Plain HTML (the <title> tag, meta description) is used as
+// a last resort. Each field of the result carries a confidence score so
+// clients can ask the user to review uncertain values.
+package extraction
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/models"
+
+	"golang.org/x/net/html"
+)
+
+// Source names reported per field
+const (
+	SourceJSONLD    = "json-ld"
+	SourceMicrodata = "microdata"
+	SourceOpenGraph = "opengraph"
+	SourceHTML      = "html"
+	SourceCanonical = "canonical"
+	SourceInferred  = "inferred"
+	SourceDefault   = "default"
+)
+
+// Base confidence for each source
+var sourceConfidence = map[string]float64{
+	SourceJSONLD:    0.95,
+	SourceMicrodata: 0.85,
+	SourceOpenGraph: 0.75,
+	SourceHTML:      0.4,
+	SourceCanonical: 0.9,
+}
+
+const (
+	// ReviewThreshold is the confidence below which a field should be reviewed by the user
+	ReviewThreshold = 0.6
+
+	// agreementBonus is added for each additional source that agrees on a value
+	agreementBonus = 0.05
+	// conflictPenalty is subtracted when another source disagrees on a value
+	conflictPenalty = 0.15
+	// maxConfidence caps a field's confidence
+	maxConfidence = 0.99
+	// pageURLConfidence applies when no canonical URL is declared and the request URL is used
+	pageURLConfidence = 0.9
+)
+
+// ErrInvalidURL is returned when the page URL is not an absolute http(s) URL
+var ErrInvalidURL = apperror.Validation("EXTRACTION_FAILED", "url must be an absolute http or https URL")
+
+// Result is the normalized item parsed from a product page
+type Result struct {
+	Item models.ItemData `json:"item"`
+	// Confidence maps item JSON field names to a score between 0 and 1
+	Confidence map[string]float64 `json:"confidence"`
+	// Sources maps item JSON field names to the source the value came from
+	Sources map[string]string `json:"sources"`
+	// NeedsReview lists fields whose confidence is below ReviewThreshold
+	NeedsReview []string `json:"needsReview"`
+}
+
+// Extractor parses product pages
+type Extractor struct{}
+
+// New creates a new Extractor
+func New() *Extractor {
+	return &Extractor{}
+}
+
+// productData is the raw product information found by a single source
+type productData struct {
+	source        string
+	Name          string
+	Brand         string
+	Description   string
+	SKU           string
+	Color         string
+	Material      string
+	Size          string
+	Category      string
+	Currency      string
+	URL           string
+	Price         *float64
+	OriginalPrice *float64
+	Images        []string
+}
+
+// Extract parses rawHTML fetched from pageURL into an item
+func (e *Extractor) Extract(rawHTML, pageURL string) (*Result, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil || !base.IsAbs() || (base.Scheme != "http" && base.Scheme != "https") {
+		return nil, ErrInvalidURL
+	}
+
+	doc, err := html.Parse(strings.NewReader(rawHTML))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse html: %w", err)
+	}
+
+	// Only the first Product per source is used; later ones are usually
+	// related or recommended products
+	var sources []*productData
+	if products := extractJSONLD(doc); len(products) > 0 {
+		sources = append(sources, products[0])
+	}
+	if products := extractMicrodata(doc); len(products) > 0 {
+		sources = append(sources, products[0])
+	}
+	og, plain, canonical := extractMeta(doc)
+	sources = append(sources, og, plain)
+	if canonical != "" {
+		sources = append(sources, &productData{source: SourceCanonical, URL: canonical})
+	}
+
+	for _, src := range sources {
+		src.URL = resolveURL(base, src.URL)
+		for i, img := range src.Images {
+			src.Images[i] = resolveURL(base, img)
+		}
+	}
+
+	return merge(sources, base.String()), nil
+}
+
+// merge combines per-source data into a single result
+func merge(sources []*productData, pageURL string) *Result {
+	r := &Result{
+		Confidence: map[string]float64{},
+		Sources:    map[string]string{},
+	}
+
+	pickString := func(field string, get func(*productData) string) *string {
+		value, conf, src := pick(sources, func(p *productData) (string, bool) {
+			v := cleanText(get(p))
+			return v, v != ""
+		}, strings.EqualFold)
+		if src == "" {
+			return nil
+		}
+		r.Confidence[field] = conf
+		r.Sources[field] = src
+		return &value
+	}
+
+	pickPrice := func(field string, get func(*productData) *float64) *float64 {
+		value, conf, src := pick(sources, func(p *productData) (float64, bool) {
+			v := get(p)
+			if v == nil {
+				return 0, false
+			}
+			return *v, true
+		}, func(a, b float64) bool { return math.Abs(a-b) < 0.005 })
+		if src == "" {
+			return nil
+		}
+		r.Confidence[field] = conf
+		r.Sources[field] = src
+		return &value
+	}
+
+	item := &r.Item
+
+	if name := pickString("name", func(p *productData) string { return p.Name }); name != nil {
+		item.Name = *name
+	}
+	item.Brand = pickString("brand", func(p *productData) string { return p.Brand })
+	item.Description = pickString("description", func(p *productData) string { return p.Description })
+	item.SKU = pickString("sku", func(p *productData) string { return p.SKU })
+	item.Color = pickString("color", func(p *productData) string { return p.Color })
+	item.Material = pickString("material", func(p *productData) string { return p.Material })
+	item.Size = pickString("size", func(p *productData) string { return p.Size })
+	item.Price = pickPrice("price", func(p *productData) *float64 { return p.Price })
+	item.OriginalPrice = pickPrice("originalPrice", func(p *productData) *float64 { return p.OriginalPrice })
+
+	if currency := pickString("currency", func(p *productData) string { return strings.ToUpper(p.Currency) }); currency != nil {
+		item.Currency = currency
+	}
+
+	if canonical := pickString("originalUrl", func(p *productData) string { return p.URL }); canonical != nil {
+		item.OriginalURL = canonical
+	} else {
+		item.OriginalURL = &pageURL
+		r.Confidence["originalUrl"] = pageURLConfidence
+		r.Sources["originalUrl"] = SourceDefault
+	}
+
+	// Images come from the most trusted source that has any
+	for _, src := range sortedSources(sources) {
+		if images := uniqueStrings(src.Images); len(images) > 0 {
+			item.Images = images
+			item.PrimaryImage = &images[0]
+			r.Confidence["images"] = sourceConfidence[src.source]
+			r.Sources["images"] = src.source
+			r.Confidence["primaryImage"] = sourceConfidence[src.source]
+			r.Sources["primaryImage"] = src.source
+			break
+		}
+	}
+
+	// Category is inferred from the source category, then the name
+	var categoryHints []string
+	for _, src := range sortedSources(sources) {
+		categoryHints = append(categoryHints, src.Category)
+	}
+	item.Category, r.Confidence["category"] = inferCategory(categoryHints, item.Name)
+	r.Sources["category"] = SourceInferred
+	if item.Category == models.CategoryOther {
+		r.Sources["category"] = SourceDefault
+	}
+
+	status := models.StatusWant
+	item.Status = &status
+
+	for field, conf := range r.Confidence {
+		if conf < ReviewThreshold {
+			r.NeedsReview = append(r.NeedsReview, field)
+		}
+	}
+	if item.Name == "" {
+		r.NeedsReview = append(r.NeedsReview, "name")
+	}
+	if item.Price == nil {
+		r.NeedsReview = append(r.NeedsReview, "price")
+	}
+	sort.Strings(r.NeedsReview)
+
+	return r
+}
+
+// pick chooses the value from the most trusted source that has one and
+// adjusts its confidence by how many other sources agree or disagree.
+// Low-trust sources can agree but never count as a conflict.
+func pick[T any](sources []*productData, get func(*productData) (T, bool), equal func(a, b T) bool) (T, float64, string) {
+	var zero T
+	ordered := sortedSources(sources)
+
+	for i, src := range ordered {
+		value, ok := get(src)
+		if !ok {
+			continue
+		}
+
+		conf := sourceConfidence[src.source]
+		conflicted := false
+		for _, other := range ordered[i+1:] {
+			otherValue, ok := get(other)
+			if !ok {
+				continue
+			}
+			if equal(value, otherValue) {
+				conf += agreementBonus
+			} else if !conflicted && sourceConfidence[other.source] >= ReviewThreshold {
+				conf -= conflictPenalty
+				conflicted = true
+			}
+		}
+
+		return value, roundConfidence(math.Min(conf, maxConfidence)), src.source
+	}
+
+	return zero, 0, ""
+}
+
+// sortedSources orders sources from most to least trusted, keeping document order for ties
+func sortedSources(sources []*productData) []*productData {
+	ordered := make([]*productData, 0, len(sources))
+	for _, src := range sources {
+		if src != nil {
+			ordered = append(ordered, src)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return sourceConfidence[ordered[i].source] > sourceConfidence[ordered[j].source]
+	})
+	return ordered
+}
+
+// resolveURL resolves ref against base, returning "" for unusable references
+func resolveURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "data:") {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	return u.String()
+}
+
+// cleanText collapses whitespace and trims a string
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// uniqueStrings drops empty and duplicate strings, preserving order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// roundConfidence rounds a confidence score to two decimals
+func roundConfidence(c float64) float64 {
+	return math.Round(c*100) / 100
+}
@@ -0,0 +1,175 @@
+package extraction
+
+import "testing"
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"49", 49},
+		{"$1,299.99", 1299.99},
+		{"1.299,99 €", 1299.99},
+		{"1.299,00 €", 1299},
+		{"€1.299", 1299},
+		{"1.299.000 ₩", 1299000},
+		{"1,299", 1299},
+		{"1,299,000", 1299000},
+		{"12,50 €", 12.5},
+		{"12.50", 12.5},
+		{"0.999", 0.999},
+		{"£100.", 100},
+		{"USD 89.9", 89.9},
+	}
+	for _, tt := range tests {
+		got := parsePrice(tt.in)
+		if got == nil {
+			t.Errorf("parsePrice(%q) = nil, want %v", tt.in, tt.want)
+			continue
+		}
+		if *got != tt.want {
+			t.Errorf("parsePrice(%q) = %v, want %v", tt.in, *got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "free", "Sold out"} {
+		if got := parsePrice(in); got != nil {
+			t.Errorf("parsePrice(%q) = %v, want nil", in, *got)
+		}
+	}
+}
+
+func TestJSONString(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{100.0, "100"},
+		{50.0, "50"},
+		{89.9, "89.9"},
+		{0.0, "0"},
+		{" Boot ", "Boot"},
+		{[]interface{}{"first", "second"}, "first"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := jsonString(tt.in); got != tt.want {
+			t.Errorf("jsonString(%#v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExtractJSONLD(t *testing.T) {
+	tests := []struct {
+		name          string
+		jsonld        string
+		price         float64
+		originalPrice float64
+		currency      string
+	}{
+		{"whole number", `{"@type":"Product","name":"Boot","offers":{"price":100,"priceCurrency":"EUR"}}`, 100, 0, "EUR"},
+		{"whole number ending in zero", `{"@type":"Product","name":"Boot","offers":{"price":50,"priceCurrency":"USD"}}`, 50, 0, "USD"},
+		{"fractional number", `{"@type":"Product","name":"Boot","offers":{"price":89.9}}`, 89.9, 0, ""},
+		{"number with three decimals", `{"@type":"Product","name":"Boot","offers":{"price":1.299}}`, 1.299, 0, ""},
+		{"string", `{"@type":"Product","name":"Boot","offers":{"price":"1299.00","priceCurrency":"GBP"}}`, 1299, 0, "GBP"},
+		{"aggregate offer", `{"@type":"Product","name":"Boot","offers":{"@type":"AggregateOffer","lowPrice":120}}`, 120, 0, ""},
+		{
+			"list price",
+			`{"@graph":[{"@type":"Product","name":"Boot","offers":{"price":60,"priceSpecification":[{"priceType":"https://schema.org/ListPrice","price":100}]}}]}`,
+			60, 100, "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := `<html><head><script type="application/ld+json">` + tt.jsonld + `</script></head></html>`
+			result, err := New().Extract(page, "https://shop.example/boot")
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+
+			item := result.Item
+			if item.Name != "Boot" {
+				t.Errorf("name = %q, want Boot", item.Name)
+			}
+			if item.Price == nil || *item.Price != tt.price {
+				t.Errorf("price = %v, want %v", deref(item.Price), tt.price)
+			}
+			if result.Sources["price"] != SourceJSONLD {
+				t.Errorf("price source = %q, want %q", result.Sources["price"], SourceJSONLD)
+			}
+			if tt.originalPrice != 0 && (item.OriginalPrice == nil || *item.OriginalPrice != tt.originalPrice) {
+				t.Errorf("originalPrice = %v, want %v", deref(item.OriginalPrice), tt.originalPrice)
+			}
+			if tt.currency != "" && (item.Currency == nil || *item.Currency != tt.currency) {
+				t.Errorf("currency = %q, want %s", str(item.Currency), tt.currency)
+			}
+		})
+	}
+}
+
+func TestExtractOpenGraph(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency string
+		price    float64
+	}{
+		{"plain", "100", "USD", 100},
+		{"decimal", "49.95", "USD", 49.95},
+		{"euro thousands", "1.299", "EUR", 1299},
+		{"euro decimal comma", "1.299,00", "EUR", 1299},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := `<html><head>
+				<title>Wool Coat | Shop</title>
+				<meta property="og:title" content="Wool Coat">
+				<meta property="og:image" content="/img/coat.jpg">
+				<meta property="product:brand" content="Acme">
+				<meta property="product:price:amount" content="` + tt.amount + `">
+				<meta property="product:price:currency" content="` + tt.currency + `">
+			</head></html>`
+			result, err := New().Extract(page, "https://shop.example/coat")
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+
+			item := result.Item
+			if item.Name != "Wool Coat" || result.Sources["name"] != SourceOpenGraph {
+				t.Errorf("name = %q from %q, want Wool Coat from opengraph", item.Name, result.Sources["name"])
+			}
+			if item.Brand == nil || *item.Brand != "Acme" {
+				t.Errorf("brand = %q, want Acme", str(item.Brand))
+			}
+			if item.PrimaryImage == nil || *item.PrimaryImage != "https://shop.example/img/coat.jpg" {
+				t.Errorf("primaryImage = %q, want the resolved og:image", str(item.PrimaryImage))
+			}
+			if item.Price == nil || *item.Price != tt.price {
+				t.Errorf("price = %v, want %v", deref(item.Price), tt.price)
+			}
+			if item.Currency == nil || *item.Currency != tt.currency {
+				t.Errorf("currency = %q, want %s", str(item.Currency), tt.currency)
+			}
+		})
+	}
+}
+
+func TestExtractRejectsRelativeURL(t *testing.T) {
+	if _, err := New().Extract("<html></html>", "/boot"); err != ErrInvalidURL {
+		t.Errorf("Extract with a relative URL = %v, want ErrInvalidURL", err)
+	}
+}
+
+func deref(f *float64) interface{} {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
+
+func str(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,63 @@
+package extraction
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// walk visits n and its descendants depth-first. Returning false from visit
+// skips the node's children.
+func walk(n *html.Node, visit func(*html.Node) bool) {
+	if !visit(n) {
+		return
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, visit)
+	}
+}
+
+// attr returns the value of the named attribute, or ""
+func attr(n *html.Node, name string) string {
+	value, _ := attrOK(n, name)
+	return value
+}
+
+// attrOK returns the value of the named attribute and whether it is present
+func attrOK(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, name) {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// hasAttr reports whether the named attribute is present
+func hasAttr(n *html.Node, name string) bool {
+	_, ok := attrOK(n, name)
+	return ok
+}
+
+// textContent concatenates the text of n and its descendants
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	walk(n, func(c *html.Node) bool {
+		if c.Type == html.TextNode {
+			sb.WriteString(c.Data)
+			sb.WriteByte(' ')
+		}
+		return true
+	})
+	return sb.String()
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,214 @@
+package extraction
+
+import (
+	"encoding/json"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// extractJSONLD finds every schema.org Product in the page's JSON-LD blocks
+func extractJSONLD(doc *html.Node) []*productData {
+	var products []*productData
+
+	walk(doc, func(n *html.Node) bool {
+		if n.Type != html.ElementNode || n.Data != "script" {
+			return true
+		}
+		if !strings.EqualFold(strings.TrimSpace(attr(n, "type")), "application/ld+json") {
+			return false
+		}
+
+		var raw interface{}
+		if err := json.Unmarshal([]byte(textContent(n)), &raw); err != nil {
+			return false
+		}
+
+		for _, node := range flattenJSONLD(raw) {
+			if hasSchemaType(node["@type"], "Product") {
+				products = append(products, productFromJSONLD(node))
+			}
+		}
+		return false
+	})
+
+	return products
+}
+
+// flattenJSONLD returns every object in a JSON-LD document, descending into arrays and @graph
+func flattenJSONLD(raw interface{}) []map[string]interface{} {
+	var out []map[string]interface{}
+
+	switch v := raw.(type) {
+	case []interface{}:
+		for _, elem := range v {
+			out = append(out, flattenJSONLD(elem)...)
+		}
+	case map[string]interface{}:
+		out = append(out, v)
+		if graph, ok := v["@graph"]; ok {
+			out = append(out, flattenJSONLD(graph)...)
+		}
+		// ProductGroup nests its variants under hasVariant
+		if variants, ok := v["hasVariant"]; ok && !hasSchemaType(v["@type"], "Product") {
+			out = append(out, flattenJSONLD(variants)...)
+		}
+	}
+
+	return out
+}
+
+// productFromJSONLD maps a JSON-LD Product object to productData
+func productFromJSONLD(node map[string]interface{}) *productData {
+	p := &productData{
+		source:      SourceJSONLD,
+		Name:        jsonString(node["name"]),
+		Brand:       jsonName(node["brand"]),
+		Description: jsonString(node["description"]),
+		SKU:         firstNonEmpty(jsonString(node["sku"]), jsonString(node["mpn"])),
+		Color:       jsonString(node["color"]),
+		Material:    jsonString(node["material"]),
+		Size:        jsonName(node["size"]),
+		Category:    jsonString(node["category"]),
+		URL:         jsonString(node["url"]),
+		Images:      jsonImages(node["image"]),
+	}
+
+	for _, offer := range jsonObjects(node["offers"]) {
+		if p.Price == nil {
+			p.Price = jsonPrice(offer["price"])
+			if p.Price == nil {
+				p.Price = jsonPrice(offer["lowPrice"])
+			}
+		}
+		if p.Currency == "" {
+			p.Currency = jsonString(offer["priceCurrency"])
+		}
+
+		for _, spec := range jsonObjects(offer["priceSpecification"]) {
+			priceType := jsonString(spec["priceType"])
+			value := jsonPrice(spec["price"])
+			switch {
+			case strings.Contains(priceType, "ListPrice"), strings.Contains(priceType, "StrikethroughPrice"):
+				if p.OriginalPrice == nil {
+					p.OriginalPrice = value
+				}
+			case p.Price == nil:
+				p.Price = value
+			}
+			if p.Currency == "" {
+				p.Currency = jsonString(spec["priceCurrency"])
+			}
+		}
+	}
+
+	if p.Currency == "" {
+		p.Currency = detectCurrency(jsonString(node["price"]))
+	}
+
+	return p
+}
+
+// hasSchemaType reports whether a JSON-LD @type value names want
+func hasSchemaType(value interface{}, want string) bool {
+	for _, t := range jsonStrings(value) {
+		t = strings.TrimPrefix(strings.TrimPrefix(t, "http://schema.org/"), "https://schema.org/")
+		if strings.EqualFold(t, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonString converts a scalar JSON value to a string
+func jsonString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return formatFloat(v)
+	case []interface{}:
+		if len(v) > 0 {
+			return jsonString(v[0])
+		}
+	}
+	return ""
+}
+
+// jsonPrice reads a JSON-LD price. Numbers are used as-is; strings may be
+// formatted for display and go through parsePrice.
+func jsonPrice(value interface{}) *float64 {
+	if v, ok := value.(float64); ok {
+		if v < 0 {
+			return nil
+		}
+		return &v
+	}
+	return parsePrice(jsonString(value))
+}
+
+// jsonStrings converts a JSON string or array of strings to a slice
+func jsonStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var out []string
+		for _, elem := range v {
+			if s, ok := elem.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// jsonName reads a value that may be a plain string or an object with a name
+func jsonName(value interface{}) string {
+	if obj, ok := value.(map[string]interface{}); ok {
+		return jsonString(obj["name"])
+	}
+	if list, ok := value.([]interface{}); ok && len(list) > 0 {
+		return jsonName(list[0])
+	}
+	return jsonString(value)
+}
+
+// jsonObjects normalizes a JSON object or array of objects to a slice
+func jsonObjects(value interface{}) []map[string]interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		// AggregateOffer may nest its own offers
+		if nested, ok := v["offers"]; ok {
+			return append([]map[string]interface{}{v}, jsonObjects(nested)...)
+		}
+		return []map[string]interface{}{v}
+	case []interface{}:
+		var out []map[string]interface{}
+		for _, elem := range v {
+			out = append(out, jsonObjects(elem)...)
+		}
+		return out
+	}
+	return nil
+}
+
+// jsonImages reads image URLs from a string, ImageObject, or array of either
+func jsonImages(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case map[string]interface{}:
+		if u := firstNonEmpty(jsonString(v["url"]), jsonString(v["contentUrl"])); u != "" {
+			return []string{u}
+		}
+	case []interface{}:
+		var out []string
+		for _, elem := range v {
+			out = append(out, jsonImages(elem)...)
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package extraction
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// extractMeta reads OpenGraph / product meta tags and the plain HTML fallbacks.
+// It returns the OpenGraph data, the plain HTML data and the canonical link
+// separately because they carry different confidence.
+func extractMeta(doc *html.Node) (*productData, *productData, string) {
+	og := &productData{source: SourceOpenGraph}
+	plain := &productData{source: SourceHTML}
+	canonical := ""
+
+	walk(doc, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+
+		switch n.Data {
+		case "title":
+			if plain.Name == "" {
+				plain.Name = stripSiteName(cleanText(textContent(n)))
+			}
+			return false
+		case "link":
+			if strings.EqualFold(attr(n, "rel"), "canonical") && canonical == "" {
+				canonical = attr(n, "href")
+			}
+			if strings.EqualFold(attr(n, "rel"), "image_src") {
+				plain.Images = append(plain.Images, attr(n, "href"))
+			}
+			return false
+		case "meta":
+			key := strings.ToLower(strings.TrimSpace(firstNonEmpty(attr(n, "property"), attr(n, "name"), attr(n, "itemprop"))))
+			applyMetaTag(og, plain, key, strings.TrimSpace(attr(n, "content")))
+			return false
+		}
+
+		return true
+	})
+
+	return og, plain, canonical
+}
+
+// applyMetaTag stores a single meta tag value
+func applyMetaTag(og, plain *productData, key, value string) {
+	if value == "" {
+		return
+	}
+
+	switch key {
+	case "og:title":
+		setOnce(&og.Name, value)
+	case "og:description":
+		setOnce(&og.Description, value)
+	case "og:url":
+		setOnce(&og.URL, value)
+	case "og:image", "og:image:url", "og:image:secure_url":
+		og.Images = append(og.Images, value)
+	case "product:brand", "og:brand":
+		setOnce(&og.Brand, value)
+	case "product:color", "og:color":
+		setOnce(&og.Color, value)
+	case "product:material":
+		setOnce(&og.Material, value)
+	case "product:size":
+		setOnce(&og.Size, value)
+	case "product:category", "product:product_type":
+		setOnce(&og.Category, value)
+	case "product:retailer_item_id", "product:sku", "product:mfr_part_no":
+		setOnce(&og.SKU, value)
+	case "product:price:amount", "og:price:amount", "product:sale_price:amount":
+		if og.Price == nil {
+			og.Price = parsePrice(value)
+		}
+	case "product:original_price:amount", "og:price:standard_amount":
+		if og.OriginalPrice == nil {
+			og.OriginalPrice = parsePrice(value)
+		}
+	case "product:price:currency", "og:price:currency", "product:sale_price:currency":
+		setOnce(&og.Currency, value)
+	case "twitter:title":
+		setOnce(&plain.Name, value)
+	case "twitter:image", "twitter:image:src":
+		plain.Images = append(plain.Images, value)
+	case "description", "twitter:description":
+		setOnce(&plain.Description, value)
+	}
+}
+
+// setOnce assigns value to dst if dst is still empty
+func setOnce(dst *string, value string) {
+	if *dst == "" {
+		*dst = value
+	}
+}
+
+// stripSiteName drops a trailing " | Site" or " - Site" suffix from a page title
+func stripSiteName(title string) string {
+	for _, sep := range []string{" | ", " – ", " - "} {
+		if i := strings.LastIndex(title, sep); i > 0 {
+			return strings.TrimSpace(title[:i])
+		}
+	}
+	return title
+}
@@ -0,0 +1,164 @@
+package extraction
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// extractMicrodata finds every schema.org Product itemscope in the page
+func extractMicrodata(doc *html.Node) []*productData {
+	var products []*productData
+
+	walk(doc, func(n *html.Node) bool {
+		if n.Type != html.ElementNode || !hasAttr(n, "itemscope") {
+			return true
+		}
+		if !strings.HasSuffix(strings.TrimSpace(attr(n, "itemtype")), "/Product") {
+			return true
+		}
+
+		products = append(products, productFromMicrodata(n))
+		return false
+	})
+
+	return products
+}
+
+// productFromMicrodata collects itemprop values within a Product scope.
+// Nested Offer scopes are included; nested Brand scopes contribute their name.
+func productFromMicrodata(scope *html.Node) *productData {
+	p := &productData{source: SourceMicrodata}
+
+	var visit func(n *html.Node, nested string)
+	visit = func(n *html.Node, nested string) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+
+			prop := strings.TrimSpace(attr(child, "itemprop"))
+			childNested := nested
+
+			if hasAttr(child, "itemscope") {
+				itemType := strings.TrimSpace(attr(child, "itemtype"))
+				// A nested Product (e.g. related items) has its own properties
+				if strings.HasSuffix(itemType, "/Product") {
+					continue
+				}
+				childNested = prop
+			}
+
+			if prop != "" {
+				applyMicrodataProp(p, prop, nested, child)
+			}
+
+			visit(child, childNested)
+		}
+	}
+	visit(scope, "")
+
+	return p
+}
+
+// applyMicrodataProp stores a single itemprop value. nested is the itemprop of
+// the enclosing nested scope, if any.
+func applyMicrodataProp(p *productData, prop, nested string, n *html.Node) {
+	if hasAttr(n, "itemscope") {
+		return
+	}
+
+	value := microdataValue(n)
+	if value == "" {
+		return
+	}
+
+	switch nested {
+	case "brand", "manufacturer":
+		if prop == "name" && p.Brand == "" {
+			p.Brand = value
+		}
+		return
+	case "offers", "priceSpecification":
+		// handled below
+	case "":
+	default:
+		return
+	}
+
+	switch prop {
+	case "name":
+		if nested == "" && p.Name == "" {
+			p.Name = value
+		}
+	case "brand":
+		if p.Brand == "" {
+			p.Brand = value
+		}
+	case "description":
+		if p.Description == "" {
+			p.Description = value
+		}
+	case "sku", "mpn":
+		if p.SKU == "" {
+			p.SKU = value
+		}
+	case "color":
+		if p.Color == "" {
+			p.Color = value
+		}
+	case "material":
+		if p.Material == "" {
+			p.Material = value
+		}
+	case "size":
+		if p.Size == "" {
+			p.Size = value
+		}
+	case "category":
+		if p.Category == "" {
+			p.Category = value
+		}
+	case "url":
+		if nested == "" && p.URL == "" {
+			p.URL = value
+		}
+	case "image":
+		p.Images = append(p.Images, value)
+	case "price", "lowPrice":
+		if p.Price == nil {
+			p.Price = parsePrice(value)
+			if p.Currency == "" {
+				p.Currency = detectCurrency(value)
+			}
+		}
+	case "priceCurrency":
+		if p.Currency == "" {
+			p.Currency = value
+		}
+	}
+}
+
+// microdataValue reads an itemprop value per the microdata spec
+func microdataValue(n *html.Node) string {
+	if content, ok := attrOK(n, "content"); ok {
+		return strings.TrimSpace(content)
+	}
+
+	switch n.Data {
+	case "img", "audio", "video", "source", "embed", "iframe":
+		return strings.TrimSpace(attr(n, "src"))
+	case "a", "area", "link":
+		return strings.TrimSpace(attr(n, "href"))
+	case "meta":
+		return strings.TrimSpace(attr(n, "content"))
+	case "data", "meter":
+		return strings.TrimSpace(attr(n, "value"))
+	case "time":
+		if dt, ok := attrOK(n, "datetime"); ok {
+			return strings.TrimSpace(dt)
+		}
+	}
+
+	return cleanText(textContent(n))
+}
@@ -0,0 +1,99 @@
+package extraction
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// priceNumber matches the first number in a price string, including separators
+var priceNumber = regexp.MustCompile(`\d[\d.,]*`)
+
+// currencyCodes are ISO 4217 codes recognized in free-form price text
+var currencyCodes = []string{"USD", "EUR", "GBP", "CAD", "AUD", "JPY", "CNY", "INR", "CHF", "SEK", "NOK", "DKK", "NZD", "KRW", "MXN", "BRL"}
+
+// currencySymbols maps price symbols to ISO 4217 codes. Longer symbols come
+// first so "CA$" is matched before "$".
+var currencySymbols = []struct {
+	symbol string
+	code   string
+}{
+	{"CA$", "CAD"},
+	{"C$", "CAD"},
+	{"AU$", "AUD"},
+	{"A$", "AUD"},
+	{"NZ$", "NZD"},
+	{"US$", "USD"},
+	{"R$", "BRL"},
+	{"€", "EUR"},
+	{"£", "GBP"},
+	{"¥", "JPY"},
+	{"₹", "INR"},
+	{"₩", "KRW"},
+	{"$", "USD"},
+}
+
+// parsePrice parses the first amount in a price string such as "$1,299.99",
+// "1.299,99 €", "€1.299" or "49". A lone separator followed by exactly three
+// digits is read as a thousands separator. It returns nil when no amount is found.
+func parsePrice(s string) *float64 {
+	number := priceNumber.FindString(s)
+	if number == "" {
+		return nil
+	}
+	number = strings.TrimRight(number, ".,")
+
+	lastDot := strings.LastIndex(number, ".")
+	lastComma := strings.LastIndex(number, ",")
+
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		// Whichever separator comes last is the decimal point
+		if lastComma > lastDot {
+			number = strings.ReplaceAll(number, ".", "")
+			number = strings.Replace(number, ",", ".", 1)
+		} else {
+			number = strings.ReplaceAll(number, ",", "")
+		}
+	case lastComma >= 0:
+		// "12,50" is a decimal comma; "1,299" and "1,299,000" are thousands
+		if strings.Count(number, ",") == 1 && len(number)-lastComma-1 != 3 {
+			number = strings.Replace(number, ",", ".", 1)
+		} else {
+			number = strings.ReplaceAll(number, ",", "")
+		}
+	case lastDot >= 0:
+		// "12.50" is a decimal point; "1.299" and "1.299.000" are thousands,
+		// but "0.999" is still a decimal
+		if strings.Count(number, ".") > 1 || (len(number)-lastDot-1 == 3 && !strings.HasPrefix(number, "0.")) {
+			number = strings.ReplaceAll(number, ".", "")
+		}
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 {
+		return nil
+	}
+	return &value
+}
+
+// detectCurrency guesses the ISO 4217 currency of a price string
+func detectCurrency(s string) string {
+	upper := strings.ToUpper(s)
+	for _, code := range currencyCodes {
+		if strings.Contains(upper, code) {
+			return code
+		}
+	}
+	for _, cs := range currencySymbols {
+		if strings.Contains(upper, cs.symbol) {
+			return cs.code
+		}
+	}
+	return ""
+}
+
+// formatFloat formats a JSON number without exponent notation
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
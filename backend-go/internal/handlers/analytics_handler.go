@@ -21,16 +21,93 @@ func NewAnalyticsHandler(analyticsService *services.AnalyticsService) *Analytics
 }
 
 // GetOverview gets analytics overview
+// @Summary Get analytics overview
+// @Description Wardrobe totals, category/brand/color breakdowns and spending patterns for the current user
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.UserAnalytics
+// @Failure 403 {object} ErrorResponse
+// @Router /analytics/overview [get]
 func (h *AnalyticsHandler) GetOverview(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get analytics overview - not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	overview, err := h.analyticsService.GetOverview(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    overview,
+	})
 }
 
 // GetTrends gets analytics trends
+// @Summary Get spending trends
+// @Description Items added, items purchased and spend per currency, bucketed by day, week or month and optionally broken down by category or brand
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param interval query string false "Bucket size: day, week or month (default month)"
+// @Param startDate query string false "Window start (YYYY-MM-DD or RFC3339)"
+// @Param endDate query string false "Window end, inclusive (YYYY-MM-DD or RFC3339)"
+// @Param groupBy query string false "Breakdown: category or brand"
+// @Success 200 {object} services.Trends
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /analytics/trends [get]
 func (h *AnalyticsHandler) GetTrends(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get analytics trends - not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var opts services.TrendOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		c.Error(invalidQuery(err))
+		return
+	}
+
+	trends, err := h.analyticsService.GetTrends(userID, opts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    trends,
+	})
 }
 
 // GetInsights gets analytics insights
+// @Summary Get wardrobe insights
+// @Description Actionable insights produced by the registered insight rules, each with the evidence behind it
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} services.Insight
+// @Failure 403 {object} ErrorResponse
+// @Router /analytics/insights [get]
 func (h *AnalyticsHandler) GetInsights(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get analytics insights - not implemented"})
-} 
\ No newline at end of file
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	insights, err := h.analyticsService.GetInsights(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    insights,
+	})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"digital-wardrobe-backend/internal/services"
 
@@ -34,27 +35,13 @@ func NewAuthHandler(authService *services.AuthService) *AuthHandler {
 func (h *AuthHandler) Register(c *gin.Context) {
 	var creds services.RegisterCredentials
 	if err := c.ShouldBindJSON(&creds); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request data",
-			"code":    "VALIDATION_ERROR",
-			"details": err.Error(),
-		})
+		c.Error(invalidRequest(err))
 		return
 	}
 
-	result, err := h.authService.Register(creds)
+	result, err := h.authService.Register(creds, clientInfo(c))
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err.Error() == "user with email "+creds.Email+" already exists" {
-			status = http.StatusConflict
-		}
-
-		c.JSON(status, gin.H{
-			"success": false,
-			"error":   err.Error(),
-			"code":    "REGISTRATION_FAILED",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -67,7 +54,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 // Login handles user login
 // @Summary Login user
-// @Description Authenticate user and return JWT token
+// @Description Authenticate user and return JWT token. Users with two-factor authentication get 403 MFA_REQUIRED with a challenge token to redeem at /auth/mfa/verify.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -75,26 +62,20 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Success 200 {object} services.AuthResult
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var creds services.LoginCredentials
 	if err := c.ShouldBindJSON(&creds); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request data",
-			"code":    "VALIDATION_ERROR",
-			"details": err.Error(),
-		})
+		c.Error(invalidRequest(err))
 		return
 	}
 
-	result, err := h.authService.Login(creds)
+	result, err := h.authService.Login(c.Request.Context(), creds, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error":   "Invalid credentials",
-			"code":    "INVALID_CREDENTIALS",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -105,6 +86,37 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	})
 }
 
+// Refresh handles access token refresh
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and a new refresh token. Each refresh token works once; reusing one revokes the session.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.RefreshRequest true "Refresh token"
+// @Success 200 {object} services.AuthResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req services.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	result, err := h.authService.Refresh(req.RefreshToken, clientInfo(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+		"message": "Token refreshed",
+	})
+}
+
 // GetProfile gets the current user's profile
 // @Summary Get user profile
 // @Description Get the current user's profile information
@@ -118,11 +130,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	user, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error":   "User not authenticated",
-			"code":    "AUTHENTICATION_REQUIRED",
-		})
+		c.Error(errNotAuthenticated)
 		return
 	}
 
@@ -134,7 +142,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 
 // Logout handles user logout
 // @Summary Logout user
-// @Description Logout user and invalidate session
+// @Description Logout user and invalidate the current session
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -143,24 +151,295 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 // @Failure 401 {object} ErrorResponse
 // @Router /auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
-	// In a real implementation, you would invalidate the session
-	// For now, we'll just return success
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if err := h.authService.Logout(userID, c.GetString("sessionID"), token); err != nil {
+		c.Error(err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Logout successful",
 	})
 }
 
-// ErrorResponse represents an error response
+// GetSessions lists the current user's active sessions
+// @Summary List sessions
+// @Description List the devices the current user is signed in on
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} services.SessionInfo
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/sessions [get]
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(userID, c.GetString("sessionID"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sessions,
+	})
+}
+
+// RevokeSession signs out one of the current user's sessions
+// @Summary Revoke session
+// @Description Sign out a single device
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID, c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Session revoked",
+	})
+}
+
+// RevokeOtherSessions signs out every session except the current one
+// @Summary Revoke other sessions
+// @Description Sign out every device except the one making the request
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/sessions [delete]
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.authService.RevokeOtherSessions(userID, c.GetString("sessionID"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"revoked": revoked},
+		"message": "Other sessions revoked",
+	})
+}
+
+// VerifyEmail confirms a user's email address
+// @Summary Verify email
+// @Description Confirm an email address with the token from the verification email. Each token works once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} models.SafeUser
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req services.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	user, err := h.authService.VerifyEmail(req.Token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    user,
+		"message": "Email verified",
+	})
+}
+
+// ResendVerification sends a new verification email to the current user
+// @Summary Resend verification email
+// @Description Send a new verification link to the current user's email address, invalidating the previous one. Limited to one email per resend interval.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /auth/verify-email/resend [post]
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.ResendVerificationEmail(c.Request.Context(), userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Verification email sent",
+	})
+}
+
+// UnlockAccount lifts a lockout early
+// @Summary Unlock account
+// @Description Unlock an account locked after repeated failed sign-ins, using the token from the lockout email. Each token works once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.UnlockAccountRequest true "Unlock token"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/unlock [post]
+func (h *AuthHandler) UnlockAccount(c *gin.Context) {
+	var req services.UnlockAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	if err := h.authService.UnlockAccount(req.Token, clientInfo(c)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Account unlocked; you can sign in again",
+	})
+}
+
+// ForgotPassword emails a password reset link
+// @Summary Request password reset
+// @Description Email a single-use password reset link. The response is the same whether or not an account exists for the email.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req services.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	if err := h.authService.ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "If an account exists for that email, a reset link has been sent",
+	})
+}
+
+// ResetPassword sets a new password with a reset token
+// @Summary Reset password
+// @Description Set a new password with the token from a reset email. Each token works once, and every session of the user is signed out.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req services.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	if err := h.authService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Password reset; please log in with your new password",
+	})
+}
+
+// ChangePassword changes the current user's password
+// @Summary Change password
+// @Description Change the password of the current user. The current password is required, and every other session is signed out.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/change-password [post]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	if err := h.authService.ChangePassword(userID, c.GetString("sessionID"), req.CurrentPassword, req.NewPassword); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Password changed",
+	})
+}
+
+// ErrorResponse represents an error response. Details maps invalid fields
+// to what is wrong with them.
 type ErrorResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error"`
-	Code    string `json:"code"`
-	Details string `json:"details,omitempty"`
+	Success bool              `json:"success"`
+	Error   string            `json:"error"`
+	Code    string            `json:"code"`
+	Details map[string]string `json:"details,omitempty"`
 }
 
 // SuccessResponse represents a success response
 type SuccessResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
-} 
\ No newline at end of file
+}
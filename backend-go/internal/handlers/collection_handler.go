@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 
+	"digital-wardrobe-backend/internal/models"
 	"digital-wardrobe-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -20,37 +21,307 @@ func NewCollectionHandler(collectionService *services.CollectionService) *Collec
 	}
 }
 
+// ReorderRequest represents a new item order for a collection
+type ReorderRequest struct {
+	ItemIDs []string `json:"itemIds" binding:"required"`
+}
+
 // GetCollections gets collections for the current user
+// @Summary List collections
+// @Description List the current user's collections
+// @Tags collections
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Collection
+// @Failure 401 {object} ErrorResponse
+// @Router /collections [get]
 func (h *CollectionHandler) GetCollections(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get collections - not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	collections, err := h.collectionService.GetCollections(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    collections,
+	})
 }
 
 // CreateCollection creates a new collection
+// @Summary Create collection
+// @Description Create a collection for the current user
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param collection body models.CollectionData true "Collection data"
+// @Success 201 {object} models.Collection
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /collections [post]
 func (h *CollectionHandler) CreateCollection(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Create collection - not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var data models.CollectionData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	collection, err := h.collectionService.CreateCollection(userID, data)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    collection,
+		"message": "Collection created successfully",
+	})
 }
 
 // GetCollection gets a specific collection
+// @Summary Get collection
+// @Description Get one of the current user's collections with its items in order
+// @Tags collections
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Collection ID"
+// @Success 200 {object} models.Collection
+// @Failure 404 {object} ErrorResponse
+// @Router /collections/{id} [get]
 func (h *CollectionHandler) GetCollection(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get collection - not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	collection, err := h.collectionService.GetCollection(userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    collection,
+	})
 }
 
 // UpdateCollection updates a specific collection
+// @Summary Update collection
+// @Description Update one of the current user's collections
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Collection ID"
+// @Param collection body models.CollectionData true "Collection data"
+// @Success 200 {object} models.Collection
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /collections/{id} [put]
 func (h *CollectionHandler) UpdateCollection(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Update collection - not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var data models.CollectionData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	collection, err := h.collectionService.UpdateCollection(userID, c.Param("id"), data)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    collection,
+		"message": "Collection updated successfully",
+	})
 }
 
 // DeleteCollection deletes a specific collection
+// @Summary Delete collection
+// @Description Delete one of the current user's collections; its items are kept
+// @Tags collections
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Collection ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /collections/{id} [delete]
 func (h *CollectionHandler) DeleteCollection(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Delete collection - not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.collectionService.DeleteCollection(userID, c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Collection deleted successfully",
+	})
 }
 
 // AddItemToCollection adds an item to a collection
+// @Summary Add item to collection
+// @Description Add one of the current user's items to a collection, optionally at a position and with notes
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Collection ID"
+// @Param item body models.CollectionItemData true "Membership data"
+// @Success 201 {object} models.CollectionItem
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /collections/{id}/items [post]
 func (h *CollectionHandler) AddItemToCollection(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Add item to collection - not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var data models.CollectionItemData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	membership, err := h.collectionService.AddItemToCollection(userID, c.Param("id"), data)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    membership,
+		"message": "Item added to collection",
+	})
+}
+
+// UpdateCollectionItem updates an item's notes within a collection
+// @Summary Update collection item
+// @Description Update the notes on an item within a collection
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Collection ID"
+// @Param itemId path string true "Item ID"
+// @Param item body models.CollectionItemUpdate true "Membership changes"
+// @Success 200 {object} models.CollectionItem
+// @Failure 404 {object} ErrorResponse
+// @Router /collections/{id}/items/{itemId} [patch]
+func (h *CollectionHandler) UpdateCollectionItem(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var data models.CollectionItemUpdate
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	membership, err := h.collectionService.UpdateCollectionItem(userID, c.Param("id"), c.Param("itemId"), data)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    membership,
+	})
 }
 
 // RemoveItemFromCollection removes an item from a collection
+// @Summary Remove item from collection
+// @Description Remove an item from a collection; the item itself is kept
+// @Tags collections
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Collection ID"
+// @Param itemId path string true "Item ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /collections/{id}/items/{itemId} [delete]
 func (h *CollectionHandler) RemoveItemFromCollection(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Remove item from collection - not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.collectionService.RemoveItemFromCollection(userID, c.Param("id"), c.Param("itemId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Item removed from collection",
+	})
+}
+
+// ReorderCollectionItems rewrites the order of a collection's items
+// @Summary Reorder collection items
+// @Description Atomically set the order of every item in a collection
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Collection ID"
+// @Param order body ReorderRequest true "Item IDs in their new order"
+// @Success 200 {object} models.Collection
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /collections/{id}/items/order [put]
+func (h *CollectionHandler) ReorderCollectionItems(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	collection, err := h.collectionService.ReorderCollectionItems(userID, c.Param("id"), req.ItemIDs)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    collection,
+		"message": "Collection reordered successfully",
+	})
 }
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	errNotAuthenticated = apperror.Unauthorized("AUTHENTICATION_REQUIRED", "User not authenticated")
+	errInvalidRequest   = apperror.Validation(apperror.CodeValidation, "Invalid request data")
+	errInvalidQuery     = apperror.Validation(apperror.CodeValidation, "Invalid query parameters")
+)
+
+// currentUserID returns the authenticated user's ID set by AuthMiddleware.
+// It records a 401 error and returns false when no user is present.
+func currentUserID(c *gin.Context) (string, bool) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.Error(errNotAuthenticated)
+		return "", false
+	}
+	return userID, true
+}
+
+// invalidRequest reports a request body that failed to bind
+func invalidRequest(err error) error {
+	return errInvalidRequest.WithFields(bindingFields(err)).Wrap(err)
+}
+
+// invalidQuery reports query parameters that failed to bind
+func invalidQuery(err error) error {
+	return errInvalidQuery.WithFields(bindingFields(err)).Wrap(err)
+}
+
+// bindingFields describes what is wrong with each field of a binding error,
+// keyed by the names RegisterFieldNames gives fields. Errors that don't name
+// a field are reported under "request".
+func bindingFields(err error) map[string]string {
+	var invalid validator.ValidationErrors
+	if errors.As(err, &invalid) {
+		fields := make(map[string]string, len(invalid))
+		for _, fe := range invalid {
+			fields[fe.Field()] = describeValidation(fe)
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return map[string]string{typeErr.Field: "must be a " + typeErr.Type.String()}
+	}
+
+	return map[string]string{"request": err.Error()}
+}
+
+// describeValidation turns a failed validation tag into a short problem
+func describeValidation(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "min":
+		return "must be at least " + fe.Param() + lengthUnit(fe)
+	case "max":
+		return "must be at most " + fe.Param() + lengthUnit(fe)
+	case "len":
+		return "must be exactly " + fe.Param() + lengthUnit(fe)
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	default:
+		return "failed the " + fe.Tag() + " check"
+	}
+}
+
+// clientInfo describes the device making the request. Clients may name
+// themselves with X-Device-Name; otherwise a name is derived from the user agent.
+func clientInfo(c *gin.Context) services.ClientInfo {
+	userAgent := c.Request.UserAgent()
+
+	device := strings.TrimSpace(c.GetHeader("X-Device-Name"))
+	if device == "" {
+		device = describeUserAgent(userAgent)
+	}
+	if len(device) > 255 {
+		device = device[:255]
+	}
+
+	return services.ClientInfo{
+		DeviceInfo: device,
+		IPAddress:  c.ClientIP(),
+		UserAgent:  userAgent,
+	}
+}
+
+// describeUserAgent turns a user agent into a short name such as "Chrome on macOS"
+func describeUserAgent(userAgent string) string {
+	if userAgent == "" {
+		return ""
+	}
+
+	browser := ""
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	}
+
+	os := ""
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		os = "iOS"
+	case strings.Contains(userAgent, "Android"):
+		os = "Android"
+	case strings.Contains(userAgent, "CrOS"):
+		os = "ChromeOS"
+	case strings.Contains(userAgent, "Mac OS X"):
+		os = "macOS"
+	case strings.Contains(userAgent, "Windows"):
+		os = "Windows"
+	case strings.Contains(userAgent, "Linux"):
+		os = "Linux"
+	}
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	default:
+		return os
+	}
+}
+
+// lengthUnit names what min, max and len count for strings and lists
+func lengthUnit(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	default:
+		return ""
+	}
+}
+
+// RegisterFieldNames makes the binding validator name fields by their JSON
+// or form key, so error details use the names clients send
+func RegisterFieldNames() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			if name := strings.Split(field.Tag.Get(tag), ",")[0]; name != "" && name != "-" {
+				return name
+			}
+		}
+		return field.Name
+	})
+}
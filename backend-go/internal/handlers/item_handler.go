@@ -3,49 +3,334 @@ package handlers
 import (
 	"net/http"
 
+	"digital-wardrobe-backend/internal/extraction"
+	"digital-wardrobe-backend/internal/models"
 	"digital-wardrobe-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxExtractBodyBytes limits the size of product pages sent for extraction
+const maxExtractBodyBytes = 5 << 20
+
 // ItemHandler handles item requests
 type ItemHandler struct {
 	itemService *services.ItemService
+	extractor   *extraction.Extractor
 }
 
 // NewItemHandler creates a new ItemHandler
-func NewItemHandler(itemService *services.ItemService) *ItemHandler {
+func NewItemHandler(itemService *services.ItemService, extractor *extraction.Extractor) *ItemHandler {
 	return &ItemHandler{
 		itemService: itemService,
+		extractor:   extractor,
 	}
 }
 
+// ExtractRequest represents a product page to extract an item from
+type ExtractRequest struct {
+	HTML string `json:"html" binding:"required"`
+	URL  string `json:"url" binding:"required"`
+}
+
 // GetItems gets items for the current user
+// @Summary List items
+// @Description List the current user's items with filters, sorting and cursor pagination
+// @Tags items
+// @Produce json
+// @Security BearerAuth
+// @Param category query string false "Category filter"
+// @Param status query string false "Status filter"
+// @Param minPrice query number false "Minimum price"
+// @Param maxPrice query number false "Maximum price"
+// @Param brands query string false "Comma-separated brands"
+// @Param colors query string false "Comma-separated colors"
+// @Param sizes query string false "Comma-separated sizes"
+// @Param onSale query bool false "Only items priced below their original price"
+// @Param archived query bool false "List archived items instead of active ones"
+// @Param sortBy query string false "dateAdded, dateUpdated, price, brand, category, status, likes or popularity"
+// @Param sortOrder query string false "asc or desc"
+// @Param page query int false "Page number (ignored when cursor is set)"
+// @Param limit query int false "Page size (max 100)"
+// @Param cursor query string false "Cursor from a previous page's nextCursor"
+// @Success 200 {object} services.ItemPage
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /items [get]
 func (h *ItemHandler) GetItems(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get items - not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var opts services.ItemListOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		c.Error(invalidQuery(err))
+		return
+	}
+
+	page, err := h.itemService.ListItems(userID, opts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       page.Data,
+		"pagination": page.Pagination,
+	})
 }
 
 // CreateItem creates a new item
+// @Summary Create item
+// @Description Save a new item to the current user's wardrobe
+// @Tags items
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param item body models.ItemData true "Item data"
+// @Success 201 {object} models.Item
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /items [post]
 func (h *ItemHandler) CreateItem(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Create item - not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var data models.ItemData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	item, err := h.itemService.CreateItem(userID, data)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    item,
+		"message": "Item created successfully",
+	})
 }
 
 // GetItem gets a specific item
+// @Summary Get item
+// @Description Get one of the current user's items
+// @Tags items
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Item ID"
+// @Success 200 {object} models.Item
+// @Failure 404 {object} ErrorResponse
+// @Router /items/{id} [get]
 func (h *ItemHandler) GetItem(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get item - not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.itemService.GetItem(userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    item,
+	})
 }
 
 // UpdateItem updates a specific item
+// @Summary Update item
+// @Description Update one of the current user's items
+// @Tags items
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Item ID"
+// @Param item body models.ItemData true "Item data"
+// @Success 200 {object} models.Item
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /items/{id} [put]
 func (h *ItemHandler) UpdateItem(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Update item - not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var data models.ItemData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	item, err := h.itemService.UpdateItem(userID, c.Param("id"), data)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    item,
+		"message": "Item updated successfully",
+	})
 }
 
 // DeleteItem deletes a specific item
+// @Summary Delete item
+// @Description Archive one of the current user's items, or delete it permanently with permanent=true
+// @Tags items
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Item ID"
+// @Param permanent query bool false "Permanently delete instead of archiving"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /items/{id} [delete]
 func (h *ItemHandler) DeleteItem(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Delete item - not implemented"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if c.Query("permanent") == "true" {
+		if err := h.itemService.DeleteItem(userID, c.Param("id")); err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Item deleted successfully",
+		})
+		return
+	}
+
+	item, err := h.itemService.ArchiveItem(userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    item,
+		"message": "Item archived successfully",
+	})
+}
+
+// RestoreItem restores an archived item
+// @Summary Restore item
+// @Description Restore one of the current user's archived items
+// @Tags items
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Item ID"
+// @Success 200 {object} models.Item
+// @Failure 404 {object} ErrorResponse
+// @Router /items/{id}/restore [post]
+func (h *ItemHandler) RestoreItem(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.itemService.RestoreItem(userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    item,
+		"message": "Item restored successfully",
+	})
 }
 
 // SearchItems searches for items
+// @Summary Search items
+// @Description Ranked full-text search over the current user's items with highlighted snippets
+// @Tags items
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text; the last word is prefix-matched"
+// @Param category query string false "Category filter"
+// @Param status query string false "Status filter"
+// @Param minPrice query number false "Minimum price"
+// @Param maxPrice query number false "Maximum price"
+// @Param brands query string false "Comma-separated brands"
+// @Param tags query string false "Comma-separated tags; items must carry all of them"
+// @Param startDate query string false "Added on or after (RFC 3339 or YYYY-MM-DD)"
+// @Param endDate query string false "Added on or before (RFC 3339 or YYYY-MM-DD)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} services.ItemSearchPage
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /items/search [get]
 func (h *ItemHandler) SearchItems(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Search items - not implemented"})
-} 
\ No newline at end of file
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var opts services.ItemSearchOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		c.Error(invalidQuery(err))
+		return
+	}
+
+	page, err := h.itemService.SearchItems(userID, opts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       page.Data,
+		"pagination": page.Pagination,
+	})
+}
+
+// ExtractItem extracts item data from a product page
+// @Summary Extract item from product page
+// @Description Parse raw product page HTML (JSON-LD, microdata, OpenGraph) into item data with per-field confidence
+// @Tags items
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page body ExtractRequest true "Product page HTML and URL"
+// @Success 200 {object} extraction.Result
+// @Failure 400 {object} ErrorResponse
+// @Router /items/extract [post]
+func (h *ItemHandler) ExtractItem(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxExtractBodyBytes)
+
+	var req ExtractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	result, err := h.extractor.Extract(req.HTML, req.URL)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"net/http"
+
+	"digital-wardrobe-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MFAHandler handles two-factor authentication
+type MFAHandler struct {
+	authService *services.AuthService
+}
+
+// NewMFAHandler creates a new MFAHandler
+func NewMFAHandler(authService *services.AuthService) *MFAHandler {
+	return &MFAHandler{
+		authService: authService,
+	}
+}
+
+// Verify finishes a sign-in with a second factor
+// @Summary Verify two-factor code
+// @Description Redeem the challenge token from MFA_REQUIRED with a code from the authenticator app or a recovery code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.MFAVerifyRequest true "Challenge token and code"
+// @Success 200 {object} services.AuthResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /auth/mfa/verify [post]
+func (h *MFAHandler) Verify(c *gin.Context) {
+	var req services.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	result, err := h.authService.VerifyMFA(c.Request.Context(), req.ChallengeToken, req.Code, clientInfo(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+		"message": "Login successful",
+	})
+}
+
+// GetStatus reports the current user's two-factor setup
+// @Summary Get two-factor status
+// @Description Whether two-factor authentication is enabled and how many unused recovery codes remain
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} services.MFAStatus
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/mfa [get]
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.authService.MFAStatus(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// EnrollTOTP starts authenticator app enrollment
+// @Summary Enroll authenticator app
+// @Description Generate a TOTP secret and otpauth URI to show as a QR code. Two-factor authentication is enabled once a code from the app is confirmed.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} services.TOTPEnrollment
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/mfa/totp [post]
+func (h *MFAHandler) EnrollTOTP(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.authService.EnrollTOTP(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    enrollment,
+	})
+}
+
+// ConfirmTOTP enables two-factor authentication
+// @Summary Confirm authenticator app
+// @Description Confirm enrollment with a first code from the app. Returns recovery codes, which are only shown once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.MFACodeRequest true "Code from the authenticator app"
+// @Success 200 {object} services.RecoveryCodes
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/mfa/totp/confirm [post]
+func (h *MFAHandler) ConfirmTOTP(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	codes, err := h.authService.ConfirmTOTP(userID, req.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    codes,
+		"message": "Two-factor authentication enabled",
+	})
+}
+
+// DisableTOTP turns off two-factor authentication
+// @Summary Disable two-factor authentication
+// @Description Remove the authenticator app and recovery codes. Requires a current code or a recovery code.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.MFACodeRequest true "Authenticator or recovery code"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/mfa/totp [delete]
+func (h *MFAHandler) DisableTOTP(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	if err := h.authService.DisableTOTP(userID, req.Code, clientInfo(c)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes with a new set. Requires a current code or a recovery code.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.MFACodeRequest true "Authenticator or recovery code"
+// @Success 200 {object} services.RecoveryCodes
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(userID, req.Code, clientInfo(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    codes,
+		"message": "Recovery codes regenerated",
+	})
+}
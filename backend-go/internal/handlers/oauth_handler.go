@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errOAuthDenied is returned when the provider redirects back without a code
+var errOAuthDenied = apperror.Validation("OAUTH_DENIED", "Authorization was not granted")
+
+// OAuthHandler handles third-party sign-in and account linking
+type OAuthHandler struct {
+	authService *services.AuthService
+}
+
+// NewOAuthHandler creates a new OAuthHandler
+func NewOAuthHandler(authService *services.AuthService) *OAuthHandler {
+	return &OAuthHandler{
+		authService: authService,
+	}
+}
+
+// GoogleLogin handles Google sign-in
+// @Summary Sign in with Google
+// @Description Verify a Google ID token from Google Identity Services and sign in, creating a user for new Google accounts. An existing user with the same email must confirm the link first.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.GoogleLoginRequest true "Google credential"
+// @Success 200 {object} services.AuthResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /auth/google [post]
+func (h *OAuthHandler) GoogleLogin(c *gin.Context) {
+	var req services.GoogleLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	result, err := h.authService.LoginWithGoogle(c.Request.Context(), req.Credential, clientInfo(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+		"message": "Login successful",
+	})
+}
+
+// GetProviders lists the enabled sign-in providers
+// @Summary List OAuth providers
+// @Description List the third-party providers users can sign in with
+// @Tags auth
+// @Produce json
+// @Success 200 {array} string
+// @Router /auth/oauth/providers [get]
+func (h *OAuthHandler) GetProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    h.authService.OAuthProviderNames(),
+	})
+}
+
+// Authorize starts signing in with a provider
+// @Summary Start OAuth sign-in
+// @Description Get the provider authorization URL for an authorization code + PKCE sign-in. Send the user there; the provider redirects back with a code and the returned state.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider (google, facebook or apple)"
+// @Success 200 {object} services.OAuthStart
+// @Failure 404 {object} ErrorResponse
+// @Router /auth/oauth/{provider}/authorize [get]
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	start, err := h.authService.StartOAuth(c.Param("provider"), "")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    start,
+	})
+}
+
+// StartLink starts linking a provider to the current user
+// @Summary Start OAuth link
+// @Description Get the provider authorization URL for linking a provider account to the current user
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider (google, facebook or apple)"
+// @Success 200 {object} services.OAuthStart
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /auth/oauth/{provider}/link [post]
+func (h *OAuthHandler) StartLink(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	start, err := h.authService.StartOAuth(c.Param("provider"), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    start,
+	})
+}
+
+// Callback completes a sign-in or link
+// @Summary Complete OAuth sign-in
+// @Description Redeem the code and state the provider redirected back with. Accepts query, form (Apple's form_post) or JSON parameters. Signing in to an account whose email belongs to an existing user returns 409 OAUTH_LINK_REQUIRED with a link token.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider (google, facebook or apple)"
+// @Param callback body services.OAuthCallbackRequest true "Code and state"
+// @Success 200 {object} services.OAuthResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/oauth/{provider}/callback [post]
+func (h *OAuthHandler) Callback(c *gin.Context) {
+	var req services.OAuthCallbackRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+	if req.Error != "" || req.Code == "" {
+		denied := errOAuthDenied
+		if req.Error != "" {
+			denied = denied.WithFields(map[string]string{"error": strings.TrimSpace(req.Error + " " + req.ErrorDescription)})
+		}
+		c.Error(denied)
+		return
+	}
+
+	result, err := h.authService.CompleteOAuth(c.Request.Context(), c.Param("provider"), req.Code, req.State, clientInfo(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	message := "Login successful"
+	if result.Linked {
+		message = "Account linked"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+		"message": message,
+	})
+}
+
+// ConfirmLink links a provider account to the current user
+// @Summary Confirm OAuth link
+// @Description Link the provider account named by a link token from OAUTH_LINK_REQUIRED. The current user must own the email the token was issued for.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.OAuthLinkConfirmation true "Link token"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/oauth/link/confirm [post]
+func (h *OAuthHandler) ConfirmLink(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.OAuthLinkConfirmation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	provider, err := h.authService.ConfirmOAuthLink(userID, req.LinkToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    services.OAuthAccount{Provider: provider, Linked: true},
+		"message": "Account linked",
+	})
+}
+
+// GetAccounts lists which providers the current user has linked
+// @Summary List linked accounts
+// @Description List the enabled providers and whether the current user has linked each
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} services.OAuthAccount
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/oauth/accounts [get]
+func (h *OAuthHandler) GetAccounts(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	accounts, err := h.authService.OAuthAccounts(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    accounts,
+	})
+}
+
+// Unlink removes a provider from the current user
+// @Summary Unlink provider
+// @Description Unlink a provider account. The last remaining sign-in method cannot be removed.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider (google, facebook or apple)"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/oauth/{provider} [delete]
+func (h *OAuthHandler) Unlink(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.UnlinkOAuth(userID, c.Param("provider")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Account unlinked",
+	})
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"net/http"
+
+	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PriceAlertHandler handles price alert requests
+type PriceAlertHandler struct {
+	priceAlertService *services.PriceAlertService
+}
+
+// NewPriceAlertHandler creates a new PriceAlertHandler
+func NewPriceAlertHandler(priceAlertService *services.PriceAlertService) *PriceAlertHandler {
+	return &PriceAlertHandler{
+		priceAlertService: priceAlertService,
+	}
+}
+
+// GetPriceAlerts gets price alerts for the current user
+// @Summary List price alerts
+// @Description List the current user's price alerts
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param active query bool false "Filter by active state"
+// @Param triggered query bool false "Filter by triggered state"
+// @Param itemId query string false "Filter by linked item"
+// @Success 200 {array} models.PriceAlert
+// @Failure 400 {object} ErrorResponse
+// @Router /alerts [get]
+func (h *PriceAlertHandler) GetPriceAlerts(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var opts services.PriceAlertListOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		c.Error(invalidQuery(err))
+		return
+	}
+
+	alerts, err := h.priceAlertService.GetPriceAlerts(userID, opts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    alerts,
+	})
+}
+
+// CreatePriceAlert creates a new price alert
+// @Summary Create price alert
+// @Description Watch a product URL, or an existing item's URL, for a price at or below the target
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param alert body models.PriceAlertData true "Alert data"
+// @Success 201 {object} models.PriceAlert
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /alerts [post]
+func (h *PriceAlertHandler) CreatePriceAlert(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var data models.PriceAlertData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	alert, err := h.priceAlertService.CreatePriceAlert(userID, data)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    alert,
+		"message": "Price alert created successfully",
+	})
+}
+
+// GetPriceAlert gets a specific price alert
+// @Summary Get price alert
+// @Description Get one of the current user's price alerts
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Alert ID"
+// @Success 200 {object} models.PriceAlert
+// @Failure 404 {object} ErrorResponse
+// @Router /alerts/{id} [get]
+func (h *PriceAlertHandler) GetPriceAlert(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	alert, err := h.priceAlertService.GetPriceAlert(userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    alert,
+	})
+}
+
+// UpdatePriceAlert updates a price alert's target or notification settings
+// @Summary Update price alert
+// @Description Update the target price or notification settings; a new target re-arms a triggered alert
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Alert ID"
+// @Param alert body models.PriceAlertUpdate true "Alert changes"
+// @Success 200 {object} models.PriceAlert
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /alerts/{id} [patch]
+func (h *PriceAlertHandler) UpdatePriceAlert(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var data models.PriceAlertUpdate
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	alert, err := h.priceAlertService.UpdatePriceAlert(userID, c.Param("id"), data)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    alert,
+		"message": "Price alert updated successfully",
+	})
+}
+
+// PausePriceAlert pauses a price alert
+// @Summary Pause price alert
+// @Description Stop checking a price alert until it is resumed
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Alert ID"
+// @Success 200 {object} models.PriceAlert
+// @Failure 404 {object} ErrorResponse
+// @Router /alerts/{id}/pause [post]
+func (h *PriceAlertHandler) PausePriceAlert(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	alert, err := h.priceAlertService.PausePriceAlert(userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    alert,
+		"message": "Price alert paused",
+	})
+}
+
+// ResumePriceAlert resumes a paused or triggered price alert
+// @Summary Resume price alert
+// @Description Resume checking a paused alert, or re-arm a triggered one
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Alert ID"
+// @Success 200 {object} models.PriceAlert
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /alerts/{id}/resume [post]
+func (h *PriceAlertHandler) ResumePriceAlert(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	alert, err := h.priceAlertService.ResumePriceAlert(userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    alert,
+		"message": "Price alert resumed",
+	})
+}
+
+// DeletePriceAlert deletes a price alert
+// @Summary Delete price alert
+// @Description Delete one of the current user's price alerts
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Alert ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /alerts/{id} [delete]
+func (h *PriceAlertHandler) DeletePriceAlert(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.priceAlertService.DeletePriceAlert(userID, c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Price alert deleted successfully",
+	})
+}
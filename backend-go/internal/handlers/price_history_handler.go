@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+
+	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PriceHistoryHandler handles price history requests
+type PriceHistoryHandler struct {
+	priceHistoryService *services.PriceHistoryService
+}
+
+// NewPriceHistoryHandler creates a new PriceHistoryHandler
+func NewPriceHistoryHandler(priceHistoryService *services.PriceHistoryService) *PriceHistoryHandler {
+	return &PriceHistoryHandler{
+		priceHistoryService: priceHistoryService,
+	}
+}
+
+// GetPriceHistory gets the price history of an item
+// @Summary Get item price history
+// @Description Price snapshots for an item with min/max/median and "lowest in N days" flags
+// @Tags items
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Item ID"
+// @Param currency query string false "Currency (defaults to the item's currency)"
+// @Param days query int false "History window in days (default 180, max 730)"
+// @Success 200 {object} services.PriceHistory
+// @Failure 404 {object} ErrorResponse
+// @Router /items/{id}/price-history [get]
+func (h *PriceHistoryHandler) GetPriceHistory(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var opts services.PriceHistoryOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		c.Error(invalidQuery(err))
+		return
+	}
+
+	history, err := h.priceHistoryService.GetPriceHistory(userID, c.Param("id"), opts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    history,
+	})
+}
+
+// RecordPrice records an observed price for an item
+// @Summary Record item price
+// @Description Record a price observed for an item, e.g. by the browser extension
+// @Tags items
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Item ID"
+// @Param observation body services.PriceObservation true "Observed price"
+// @Success 201 {object} models.PriceSnapshot
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /items/{id}/price-history [post]
+func (h *PriceHistoryHandler) RecordPrice(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var obs services.PriceObservation
+	if err := c.ShouldBindJSON(&obs); err != nil {
+		c.Error(invalidRequest(err))
+		return
+	}
+
+	snapshot, err := h.priceHistoryService.RecordPrice(userID, c.Param("id"), models.PriceSourceExtension, obs)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    snapshot,
+		"message": "Price recorded successfully",
+	})
+}
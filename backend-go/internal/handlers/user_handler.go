@@ -22,15 +22,15 @@ func NewUserHandler(userService *services.UserService) *UserHandler {
 
 // GetProfile gets the current user's profile
 func (h *UserHandler) GetProfile(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get profile - not implemented"})
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Get profile - not implemented"})
 }
 
 // UpdateProfile updates the current user's profile
 func (h *UserHandler) UpdateProfile(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Update profile - not implemented"})
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Update profile - not implemented"})
 }
 
 // DeleteAccount deletes the current user's account
 func (h *UserHandler) DeleteAccount(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Delete account - not implemented"})
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Delete account - not implemented"})
 } 
\ No newline at end of file
@@ -0,0 +1,146 @@
+// Package ids generates and parses the identifiers used for every record.
+//
+// IDs are UUIDv7 (RFC 9562): a 48-bit millisecond timestamp followed by a
+// 12-bit counter and 62 random bits. They sort by creation time, fit the
+// Postgres uuid columns, and are unique across processes.
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// UUID is a 128-bit identifier
+type UUID [16]byte
+
+// Nil is the zero UUID
+var Nil UUID
+
+// ErrInvalid is returned when parsing a string that isn't a UUID
+var ErrInvalid = errors.New("invalid id")
+
+// generator keeps IDs from one process strictly increasing, even within a
+// millisecond or when the clock steps back
+type generator struct {
+	mu     sync.Mutex
+	lastMs int64
+	seq    uint16 // 12-bit counter in the rand_a field
+}
+
+var gen generator
+
+// New returns a new UUIDv7 in canonical string form
+func New() string {
+	return NewV7().String()
+}
+
+// NewV7 returns a new UUIDv7
+func NewV7() UUID {
+	return gen.next(time.Now())
+}
+
+func (g *generator) next(now time.Time) UUID {
+	var u UUID
+	if _, err := rand.Read(u[6:]); err != nil {
+		panic("ids: failed to read random bytes: " + err.Error())
+	}
+
+	g.mu.Lock()
+	if ms := now.UnixMilli(); ms > g.lastMs {
+		g.lastMs = ms
+		// Start the counter in its lower half so it rarely overflows
+		g.seq = binary.BigEndian.Uint16(u[6:8]) & 0x7ff
+	} else {
+		g.seq++
+		if g.seq > 0xfff {
+			g.lastMs++
+			g.seq = 0
+		}
+	}
+	ms, seq := g.lastMs, g.seq
+	g.mu.Unlock()
+
+	u[0] = byte(ms >> 40)
+	u[1] = byte(ms >> 32)
+	u[2] = byte(ms >> 24)
+	u[3] = byte(ms >> 16)
+	u[4] = byte(ms >> 8)
+	u[5] = byte(ms)
+	u[6] = 0x70 | byte(seq>>8)
+	u[7] = byte(seq)
+	u[8] = u[8]&0x3f | 0x80 // RFC 9562 variant
+	return u
+}
+
+// Parse parses a UUID in canonical 8-4-4-4-12 form, in either case
+func Parse(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return Nil, ErrInvalid
+	}
+	j := 0
+	for _, group := range [][2]int{{0, 8}, {9, 13}, {14, 18}, {19, 23}, {24, 36}} {
+		n, err := hex.Decode(u[j:], []byte(s[group[0]:group[1]]))
+		if err != nil {
+			return Nil, ErrInvalid
+		}
+		j += n
+	}
+	return u, nil
+}
+
+// Valid reports whether s is a UUID that can be looked up. Any version is
+// accepted, since rows may predate UUIDv7.
+func Valid(s string) bool {
+	_, err := Parse(s)
+	return err == nil
+}
+
+// String returns the canonical lowercase form
+func (u UUID) String() string {
+	var b [36]byte
+	hex.Encode(b[0:8], u[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+	return string(b[:])
+}
+
+// Version returns the UUID version
+func (u UUID) Version() int {
+	return int(u[6] >> 4)
+}
+
+// Time returns when a UUIDv7 was generated, to the millisecond. It returns
+// the zero time for other versions.
+func (u UUID) Time() time.Time {
+	if u.Version() != 7 {
+		return time.Time{}
+	}
+	ms := int64(u[0])<<40 | int64(u[1])<<32 | int64(u[2])<<24 | int64(u[3])<<16 | int64(u[4])<<8 | int64(u[5])
+	return time.UnixMilli(ms)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (u *UUID) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
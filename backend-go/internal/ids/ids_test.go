@@ -0,0 +1,106 @@
+package ids
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewV7(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	u := NewV7()
+	after := time.Now()
+
+	if u.Version() != 7 {
+		t.Errorf("Version() = %d, want 7", u.Version())
+	}
+	if u[8]&0xc0 != 0x80 {
+		t.Errorf("variant bits = %02x, want 10xxxxxx", u[8])
+	}
+	if ts := u.Time(); ts.Before(before) || ts.After(after) {
+		t.Errorf("Time() = %v, want between %v and %v", ts, before, after)
+	}
+}
+
+func TestNewIsSortedAndUnique(t *testing.T) {
+	const n = 100000
+	seen := make(map[string]bool, n)
+	prev := ""
+	for i := 0; i < n; i++ {
+		id := New()
+		if seen[id] {
+			t.Fatalf("duplicate id %s after %d ids", id, i)
+		}
+		seen[id] = true
+		if id <= prev {
+			t.Fatalf("id %s not after %s", id, prev)
+		}
+		prev = id
+	}
+}
+
+func TestGeneratorStaysMonotonic(t *testing.T) {
+	g := &generator{}
+	now := time.UnixMilli(1700000000000)
+
+	// Same millisecond, counter overflow and a clock step back
+	prev := g.next(now).String()
+	for i := 0; i < 5000; i++ {
+		id := g.next(now).String()
+		if id <= prev {
+			t.Fatalf("step %d: %s not after %s", i, id, prev)
+		}
+		prev = id
+	}
+	if id := g.next(now.Add(-time.Second)).String(); id <= prev {
+		t.Fatalf("after clock step back: %s not after %s", id, prev)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"0190b7d4-5f3a-7c2e-9b1d-3f4a5b6c7d8e", "0190b7d4-5f3a-7c2e-9b1d-3f4a5b6c7d8e", true},
+		{"0190B7D4-5F3A-7C2E-9B1D-3F4A5B6C7D8E", "0190b7d4-5f3a-7c2e-9b1d-3f4a5b6c7d8e", true},
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430c8", "6ba7b810-9dad-11d1-80b4-00c04fd430c8", true}, // v1
+		{"item_20240101120000_abcdefgh", "", false},
+		{"0190b7d45f3a7c2e9b1d3f4a5b6c7d8e", "", false},
+		{"0190b7d4-5f3a-7c2e-9b1d-3f4a5b6c7d8", "", false},
+		{"0190b7d4-5f3a-7c2e-9b1d_3f4a5b6c7d8e", "", false},
+		{"0190b7d4-5f3a-7c2e-9b1d-3f4a5b6c7dxe", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		u, err := Parse(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("Parse(%q) err = %v, want ok=%v", tt.in, err, tt.ok)
+			continue
+		}
+		if Valid(tt.in) != tt.ok {
+			t.Errorf("Valid(%q) = %v, want %v", tt.in, !tt.ok, tt.ok)
+		}
+		if tt.ok && u.String() != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.in, u.String(), tt.want)
+		}
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	u := NewV7()
+	text, _ := u.MarshalText()
+	if strings.ToLower(string(text)) != string(text) {
+		t.Errorf("MarshalText() = %q, want lowercase", text)
+	}
+
+	var back UUID
+	if err := back.UnmarshalText(text); err != nil || back != u {
+		t.Errorf("UnmarshalText(%q) = %v, %v; want %v", text, back, err, u)
+	}
+	if err := back.UnmarshalText([]byte("nope")); err != ErrInvalid {
+		t.Errorf("UnmarshalText(nope) err = %v, want ErrInvalid", err)
+	}
+}
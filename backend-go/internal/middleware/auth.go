@@ -1,36 +1,34 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
+	"digital-wardrobe-backend/internal/apperror"
 	"digital-wardrobe-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
+var (
+	errAuthorizationRequired = apperror.Unauthorized("AUTHENTICATION_REQUIRED", "Authorization header required")
+	errInvalidAuthFormat     = apperror.Unauthorized("INVALID_AUTH_FORMAT", "Invalid authorization format")
+	errUserNotFound          = apperror.Unauthorized("USER_NOT_FOUND", "User not found")
+)
+
 // AuthMiddleware creates authentication middleware
 func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Authorization header required",
-				"code":    "AUTHENTICATION_REQUIRED",
-			})
+			c.Error(errAuthorizationRequired)
 			c.Abort()
 			return
 		}
 
 		// Check if it's a Bearer token
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Invalid authorization format",
-				"code":    "INVALID_AUTH_FORMAT",
-			})
+			c.Error(errInvalidAuthFormat)
 			c.Abort()
 			return
 		}
@@ -41,11 +39,7 @@ func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 		// Validate token
 		claims, err := authService.ValidateToken(token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Invalid or expired token",
-				"code":    "INVALID_TOKEN",
-			})
+			c.Error(err)
 			c.Abort()
 			return
 		}
@@ -53,11 +47,7 @@ func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 		// Get user from database
 		user, err := authService.GetUserByID(claims.UserID)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "User not found",
-				"code":    "USER_NOT_FOUND",
-			})
+			c.Error(errUserNotFound.Wrap(err))
 			c.Abort()
 			return
 		}
@@ -66,6 +56,7 @@ func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 		c.Set("user", user)
 		c.Set("userID", claims.UserID)
 		c.Set("userEmail", claims.Email)
+		c.Set("sessionID", claims.SessionID)
 
 		c.Next()
 	}
@@ -108,6 +99,7 @@ func OptionalAuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 		c.Set("user", user)
 		c.Set("userID", claims.UserID)
 		c.Set("userEmail", claims.Email)
+		c.Set("sessionID", claims.SessionID)
 
 		c.Next()
 	}
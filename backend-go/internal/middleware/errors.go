@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// kindStatus maps error kinds to HTTP statuses
+var kindStatus = map[apperror.Kind]int{
+	apperror.KindNotFound:     http.StatusNotFound,
+	apperror.KindConflict:     http.StatusConflict,
+	apperror.KindValidation:   http.StatusBadRequest,
+	apperror.KindUnauthorized: http.StatusUnauthorized,
+	apperror.KindForbidden:    http.StatusForbidden,
+	apperror.KindRateLimited:  http.StatusTooManyRequests,
+	apperror.KindLocked:       http.StatusLocked,
+	apperror.KindUnavailable:  http.StatusServiceUnavailable,
+}
+
+// ErrorHandler renders the last error a handler added with c.Error as an
+// error response. Errors that are not *apperror.Error are logged and hidden
+// behind a generic 500.
+func ErrorHandler(log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+
+		var appErr *apperror.Error
+		status, ok := 0, errors.As(err, &appErr)
+		if ok {
+			status, ok = kindStatus[appErr.Kind]
+		}
+		if !ok {
+			log.Errorf("%s %s failed: %v", c.Request.Method, c.Request.URL.Path, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Internal server error",
+				"code":    "INTERNAL_ERROR",
+			})
+			return
+		}
+
+		body := gin.H{
+			"success": false,
+			"error":   appErr.Message,
+			"code":    appErr.Code,
+		}
+		if len(appErr.Fields) > 0 {
+			body["details"] = appErr.Fields
+		}
+		if appErr.Data != nil {
+			body["data"] = appErr.Data
+		}
+		if appErr.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+		}
+		c.JSON(status, body)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"time"
 
+	"digital-wardrobe-backend/internal/ids"
+
 	"gorm.io/gorm"
 )
 
@@ -40,9 +42,9 @@ type UserAnalytics struct {
 	
 	// Item Statistics
 	TotalItems int     `json:"totalItems" gorm:"default:0"`
-	TotalValue float64 `json:"totalValue" gorm:"type:decimal(10,2);default:0"`
-	TotalSpent float64 `json:"totalSpent" gorm:"type:decimal(10,2);default:0"`
-	AverageItemPrice float64 `json:"averageItemPrice" gorm:"type:decimal(10,2);default:0"`
+	TotalValue JSONMap `json:"totalValue" gorm:"type:jsonb"` // by currency: {USD: 120.5, EUR: 80}
+	TotalSpent JSONMap `json:"totalSpent" gorm:"type:jsonb"` // by currency
+	AverageItemPrice JSONMap `json:"averageItemPrice" gorm:"type:jsonb"` // by currency
 	
 	// Category Breakdown
 	CategoryBreakdown JSONMap `json:"categoryBreakdown" gorm:"type:jsonb"` // {tops: 10, bottoms: 5, etc.}
@@ -52,7 +54,13 @@ type UserAnalytics struct {
 	// Shopping Patterns
 	MostActiveMonth string  `json:"mostActiveMonth"`
 	PreferredBrands StringSlice `json:"preferredBrands" gorm:"type:jsonb"`
-	AverageMonthlySpending float64 `json:"averageMonthlySpending" gorm:"type:decimal(10,2);default:0"`
+	AverageMonthlySpending JSONMap `json:"averageMonthlySpending" gorm:"type:jsonb"` // by currency
+	MonthlyActivity JSONMap `json:"monthlyActivity" gorm:"type:jsonb"` // {"2024-03": 4, etc.} items added per month
+	MonthlySpending JSONMap `json:"monthlySpending" gorm:"type:jsonb"` // {"2024-03": {USD: 120.5}, etc.}
+	
+	// Running totals kept for incremental updates
+	PricedItems JSONMap `json:"-" gorm:"type:jsonb"` // by currency
+	PriceTotal  JSONMap `json:"-" gorm:"type:jsonb"` // by currency
 	
 	// Engagement
 	TotalLogins int `json:"totalLogins" gorm:"default:0"`
@@ -64,7 +72,7 @@ type UserAnalytics struct {
 	UpdatedAt time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
 	
 	// Relationships
-	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	User User `json:"-" gorm:"foreignKey:UserID"`
 }
 
 // TableName specifies the table name for UserAnalytics
@@ -75,16 +83,11 @@ func (UserAnalytics) TableName() string {
 // BeforeCreate is called before creating user analytics
 func (ua *UserAnalytics) BeforeCreate(tx *gorm.DB) error {
 	if ua.ID == "" {
-		ua.ID = generateAnalyticsUUID()
+		ua.ID = ids.New()
 	}
 	return nil
 }
 
-// generateAnalyticsUUID generates a UUID for analytics
-func generateAnalyticsUUID() string {
-	return "analytics_" + time.Now().Format("20060102150405") + "_" + randomString(8)
-}
-
 // Follow represents social following relationships
 type Follow struct {
 	ID string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
@@ -106,16 +109,11 @@ func (Follow) TableName() string {
 // BeforeCreate is called before creating a follow
 func (f *Follow) BeforeCreate(tx *gorm.DB) error {
 	if f.ID == "" {
-		f.ID = generateFollowUUID()
+		f.ID = ids.New()
 	}
 	return nil
 }
 
-// generateFollowUUID generates a UUID for follows
-func generateFollowUUID() string {
-	return "follow_" + time.Now().Format("20060102150405") + "_" + randomString(8)
-}
-
 // PriceAlert represents price tracking alerts
 type PriceAlert struct {
 	ID string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
@@ -155,12 +153,25 @@ func (PriceAlert) TableName() string {
 // BeforeCreate is called before creating a price alert
 func (pa *PriceAlert) BeforeCreate(tx *gorm.DB) error {
 	if pa.ID == "" {
-		pa.ID = generatePriceAlertUUID()
+		pa.ID = ids.New()
 	}
 	return nil
 }
 
-// generatePriceAlertUUID generates a UUID for price alerts
-func generatePriceAlertUUID() string {
-	return "price_alert_" + time.Now().Format("20060102150405") + "_" + randomString(8)
-} 
\ No newline at end of file
+// PriceAlertData represents data for creating a price alert
+type PriceAlertData struct {
+	ItemID            *string  `json:"itemId"`
+	ProductURL        *string  `json:"productUrl"`
+	TargetPrice       float64  `json:"targetPrice" binding:"required"`
+	CurrentPrice      *float64 `json:"currentPrice"`
+	Currency          *string  `json:"currency"`
+	EmailNotification *bool    `json:"emailNotification"`
+	PushNotification  *bool    `json:"pushNotification"`
+}
+
+// PriceAlertUpdate represents data for updating a price alert. Nil fields are left unchanged.
+type PriceAlertUpdate struct {
+	TargetPrice       *float64 `json:"targetPrice"`
+	EmailNotification *bool    `json:"emailNotification"`
+	PushNotification  *bool    `json:"pushNotification"`
+}
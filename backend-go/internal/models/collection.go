@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"digital-wardrobe-backend/internal/ids"
+
 	"gorm.io/gorm"
 )
 
@@ -35,21 +37,16 @@ func (Collection) TableName() string {
 // BeforeCreate is called before creating a collection
 func (c *Collection) BeforeCreate(tx *gorm.DB) error {
 	if c.ID == "" {
-		c.ID = generateCollectionUUID()
+		c.ID = ids.New()
 	}
 	return nil
 }
 
-// generateCollectionUUID generates a UUID for collections
-func generateCollectionUUID() string {
-	return "collection_" + time.Now().Format("20060102150405") + "_" + randomString(8)
-}
-
 // CollectionItem represents the many-to-many relationship between collections and items
 type CollectionItem struct {
 	ID           string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	CollectionID string    `json:"collectionId" gorm:"not null;index"`
-	ItemID       string    `json:"itemId" gorm:"not null;index"`
+	CollectionID string    `json:"collectionId" gorm:"not null;index;uniqueIndex:idx_collection_items_membership"`
+	ItemID       string    `json:"itemId" gorm:"not null;index;uniqueIndex:idx_collection_items_membership"`
 	
 	// Custom order within collection
 	Order int `json:"order" gorm:"default:0"`
@@ -72,12 +69,29 @@ func (CollectionItem) TableName() string {
 // BeforeCreate is called before creating a collection item
 func (ci *CollectionItem) BeforeCreate(tx *gorm.DB) error {
 	if ci.ID == "" {
-		ci.ID = generateCollectionItemUUID()
+		ci.ID = ids.New()
 	}
 	return nil
 }
 
-// generateCollectionItemUUID generates a UUID for collection items
-func generateCollectionItemUUID() string {
-	return "collection_item_" + time.Now().Format("20060102150405") + "_" + randomString(8)
-} 
\ No newline at end of file
+// CollectionData represents the data needed to create/update a collection
+type CollectionData struct {
+	Name        string  `json:"name" binding:"required"`
+	Description *string `json:"description"`
+	Color       *string `json:"color"`
+	Icon        *string `json:"icon"`
+	IsPublic    *bool   `json:"isPublic"`
+}
+
+// CollectionItemData represents the data needed to add an item to a collection
+type CollectionItemData struct {
+	ItemID string  `json:"itemId" binding:"required"`
+	Notes  *string `json:"notes"`
+	// Order is the zero-based position to insert at; nil appends to the end
+	Order *int `json:"order"`
+}
+
+// CollectionItemUpdate represents changes to an item's membership in a collection
+type CollectionItemUpdate struct {
+	Notes *string `json:"notes"`
+}
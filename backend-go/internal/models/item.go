@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"time"
 
+	"digital-wardrobe-backend/internal/ids"
+
 	"gorm.io/gorm"
 )
 
@@ -86,6 +88,7 @@ type Item struct {
 	User            User             `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	CollectionItems []CollectionItem `json:"collectionItems,omitempty" gorm:"foreignKey:ItemID;constraint:OnDelete:CASCADE"`
 	PriceAlerts     []PriceAlert     `json:"-" gorm:"foreignKey:ItemID;constraint:OnDelete:CASCADE"`
+	PriceHistory    []PriceSnapshot  `json:"-" gorm:"foreignKey:ItemID;constraint:OnDelete:CASCADE"`
 }
 
 // TableName specifies the table name for Item
@@ -96,16 +99,11 @@ func (Item) TableName() string {
 // BeforeCreate is called before creating an item
 func (i *Item) BeforeCreate(tx *gorm.DB) error {
 	if i.ID == "" {
-		i.ID = generateItemUUID()
+		i.ID = ids.New()
 	}
 	return nil
 }
 
-// generateItemUUID generates a UUID for items
-func generateItemUUID() string {
-	return "item_" + time.Now().Format("20060102150405") + "_" + randomString(8)
-}
-
 // ItemWithRelations represents an item with its relationships
 type ItemWithRelations struct {
 	Item
@@ -137,4 +135,69 @@ type ItemData struct {
 	Tags        StringSlice `json:"tags"`
 	Notes       *string     `json:"notes"`
 	IsPublic    *bool       `json:"isPublic"`
-} 
\ No newline at end of file
+}
+
+// Item categories
+const (
+	CategoryTops        = "tops"
+	CategoryBottoms     = "bottoms"
+	CategoryShoes       = "shoes"
+	CategoryAccessories = "accessories"
+	CategoryOuterwear   = "outerwear"
+	CategoryDresses     = "dresses"
+	CategoryOther       = "other"
+)
+
+// Item statuses
+const (
+	StatusWant      = "want"
+	StatusPurchased = "purchased"
+	StatusOwned     = "owned"
+	StatusSold      = "sold"
+	StatusDonated   = "donated"
+)
+
+// ItemCategories lists every valid item category
+var ItemCategories = []string{
+	CategoryTops,
+	CategoryBottoms,
+	CategoryShoes,
+	CategoryAccessories,
+	CategoryOuterwear,
+	CategoryDresses,
+	CategoryOther,
+}
+
+// ItemStatuses lists every valid item status
+var ItemStatuses = []string{
+	StatusWant,
+	StatusPurchased,
+	StatusOwned,
+	StatusSold,
+	StatusDonated,
+}
+
+// IsValidCategory reports whether category is a known item category
+func IsValidCategory(category string) bool {
+	return containsString(ItemCategories, category)
+}
+
+// IsValidStatus reports whether status is a known item status
+func IsValidStatus(status string) bool {
+	return containsString(ItemStatuses, status)
+}
+
+// IsArchived reports whether the item has been soft-archived
+func (i *Item) IsArchived() bool {
+	return i.ArchivedAt != nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
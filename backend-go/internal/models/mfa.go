@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"digital-wardrobe-backend/internal/ids"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCode is a one-time code that stands in for a two-factor code when
+// the user has lost their authenticator
+type RecoveryCode struct {
+	ID        string     `json:"-" gorm:"primaryKey;type:uuid"`
+	UserID    string     `json:"-" gorm:"index;type:uuid;not null"`
+	CodeHash  string     `json:"-" gorm:"not null"` // SHA-256 of the normalized code
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt" gorm:"autoCreateTime"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName specifies the table name for RecoveryCode
+func (RecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// BeforeCreate is called before creating a recovery code
+func (rc *RecoveryCode) BeforeCreate(tx *gorm.DB) error {
+	if rc.ID == "" {
+		rc.ID = ids.New()
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// OAuthState is a pending OAuth authorization. It is keyed by the SHA-256 of
+// the state parameter and deleted when the callback redeems it.
+type OAuthState struct {
+	ID           string    `json:"-" gorm:"primaryKey;type:text"`
+	Provider     string    `json:"provider" gorm:"not null"`
+	CodeVerifier string    `json:"-" gorm:"not null"`
+	Nonce        string    `json:"-" gorm:"not null"`
+	UserID       *string   `json:"userId" gorm:"index;type:uuid"` // set when linking a provider to a signed-in user
+	ExpiresAt    time.Time `json:"expiresAt" gorm:"not null;index"`
+	CreatedAt    time.Time `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for OAuthState
+func (OAuthState) TableName() string {
+	return "oauth_states"
+}
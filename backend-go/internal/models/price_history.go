@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"digital-wardrobe-backend/internal/ids"
+
+	"gorm.io/gorm"
+)
+
+// Price snapshot sources
+const (
+	PriceSourceItem      = "item"      // price set on the item itself
+	PriceSourceExtension = "extension" // observed by the browser extension
+	PriceSourceAlert     = "alert"     // observed by the price alert worker
+)
+
+// PriceSnapshot records a price observed for an item at a point in time
+type PriceSnapshot struct {
+	ID     string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ItemID string `json:"itemId" gorm:"not null;index:idx_price_snapshots_item_currency_observed,priority:1"`
+	UserID string `json:"userId" gorm:"not null;index;type:uuid"`
+
+	// Price
+	Price         float64  `json:"price" gorm:"type:decimal(10,2);not null"`
+	OriginalPrice *float64 `json:"originalPrice" gorm:"type:decimal(10,2)"`
+	Currency      string   `json:"currency" gorm:"not null;default:'USD';index:idx_price_snapshots_item_currency_observed,priority:2"`
+
+	// Provenance
+	Source     string    `json:"source" gorm:"not null"` // item, extension, alert
+	ObservedAt time.Time `json:"observedAt" gorm:"not null;index:idx_price_snapshots_item_currency_observed,priority:3"`
+
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"`
+
+	// Relationships
+	Item *Item `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+}
+
+// TableName specifies the table name for PriceSnapshot
+func (PriceSnapshot) TableName() string {
+	return "price_snapshots"
+}
+
+// BeforeCreate is called before creating a price snapshot
+func (ps *PriceSnapshot) BeforeCreate(tx *gorm.DB) error {
+	if ps.ID == "" {
+		ps.ID = ids.New()
+	}
+	return nil
+}
@@ -3,15 +3,17 @@ package models
 import (
 	"time"
 
+	"digital-wardrobe-backend/internal/ids"
+
 	"gorm.io/gorm"
 )
 
 // Session represents a user session
 type Session struct {
-	ID           string    `json:"id" gorm:"primaryKey;type:text"`
-	UserID       string    `json:"userId" gorm:"index;type:text"`
-	Token        string    `json:"token" gorm:"uniqueIndex;not null;type:text"`
-	RefreshToken *string   `json:"refreshToken" gorm:"uniqueIndex;type:text"`
+	ID           string    `json:"id" gorm:"primaryKey;type:uuid"`
+	UserID       string    `json:"userId" gorm:"index;type:uuid"`
+	Token        string    `json:"-" gorm:"uniqueIndex;not null;type:text"`
+	RefreshToken *string   `json:"-" gorm:"uniqueIndex;type:text"` // SHA-256 of the current refresh token
 	ExpiresAt    time.Time `json:"expiresAt" gorm:"not null;index"`
 	DeviceInfo   *string   `json:"deviceInfo"`
 	IPAddress    *string   `json:"ipAddress"`
@@ -32,12 +34,7 @@ func (Session) TableName() string {
 // BeforeCreate is called before creating a session
 func (s *Session) BeforeCreate(tx *gorm.DB) error {
 	if s.ID == "" {
-		s.ID = generateSessionUUID()
+		s.ID = ids.New()
 	}
 	return nil
 }
-
-// generateSessionUUID generates a UUID for sessions
-func generateSessionUUID() string {
-	return "session_" + time.Now().Format("20060102150405") + "_" + randomString(8)
-}
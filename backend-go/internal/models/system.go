@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"digital-wardrobe-backend/internal/ids"
+
 	"gorm.io/gorm"
 )
 
@@ -25,16 +27,11 @@ func (AppConfig) TableName() string {
 // BeforeCreate is called before creating app config
 func (ac *AppConfig) BeforeCreate(tx *gorm.DB) error {
 	if ac.ID == "" {
-		ac.ID = generateAppConfigUUID()
+		ac.ID = ids.New()
 	}
 	return nil
 }
 
-// generateAppConfigUUID generates a UUID for app config
-func generateAppConfigUUID() string {
-	return "app_config_" + time.Now().Format("20060102150405") + "_" + randomString(8)
-}
-
 // AuditLog represents system audit logs
 type AuditLog struct {
 	ID           string  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
@@ -60,12 +57,7 @@ func (AuditLog) TableName() string {
 // BeforeCreate is called before creating audit log
 func (al *AuditLog) BeforeCreate(tx *gorm.DB) error {
 	if al.ID == "" {
-		al.ID = generateAuditLogUUID()
+		al.ID = ids.New()
 	}
 	return nil
 }
-
-// generateAuditLogUUID generates a UUID for audit logs
-func generateAuditLogUUID() string {
-	return "audit_log_" + time.Now().Format("20060102150405") + "_" + randomString(8)
-}
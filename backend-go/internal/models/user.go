@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"digital-wardrobe-backend/internal/ids"
+
 	"gorm.io/gorm"
 )
 
@@ -19,17 +21,28 @@ type User struct {
 	// Authentication
 	PasswordHash            *string   `json:"-" gorm:"column:password_hash"`
 	IsEmailVerified        bool      `json:"isEmailVerified" gorm:"default:false"`
-	EmailVerificationToken *string   `json:"-"`
+	EmailVerificationToken *string   `json:"-" gorm:"index"` // SHA-256 of the emailed token
 	EmailVerificationExpires *time.Time `json:"-"`
 	
 	// Password Reset
 	PasswordResetToken     *string   `json:"-"`
 	PasswordResetExpires   *time.Time `json:"-"`
-	
+
+	// Lockout
+	FailedLoginAttempts int        `json:"-" gorm:"not null;default:0"` // consecutive failures since the last success or lock
+	LockoutCount        int        `json:"-" gorm:"not null;default:0"` // locks since the last success; each lasts twice as long
+	LockedUntil         *time.Time `json:"-"`
+	UnlockToken         *string    `json:"-" gorm:"index"` // SHA-256 of the emailed unlock token
+
+	// Two-factor authentication
+	TOTPSecret   *string `json:"-"` // encrypted; set at enrollment, in use once TOTPEnabled
+	TOTPEnabled  bool    `json:"-" gorm:"not null;default:false"`
+	TOTPLastStep int64   `json:"-" gorm:"not null;default:0"` // time step of the last accepted code, so codes can't be replayed
+
 	// OAuth
-	GoogleID   *string `json:"-"`
-	FacebookID *string `json:"-"`
-	AppleID    *string `json:"-"`
+	GoogleID   *string `json:"-" gorm:"uniqueIndex"`
+	FacebookID *string `json:"-" gorm:"uniqueIndex"`
+	AppleID    *string `json:"-" gorm:"uniqueIndex"`
 	
 	// Profile
 	Bio       *string    `json:"bio"`
@@ -84,6 +97,8 @@ type SafeUser struct {
 	Location    *string    `json:"location"`
 	Website     *string    `json:"website"`
 	IsPrivate   bool       `json:"isPrivate"`
+	IsEmailVerified bool   `json:"isEmailVerified"`
+	TwoFactorEnabled bool  `json:"twoFactorEnabled"`
 	SubscriptionTier string `json:"subscriptionTier"`
 	CreatedAt   time.Time  `json:"createdAt"`
 	UpdatedAt   time.Time  `json:"updatedAt"`
@@ -105,6 +120,8 @@ func (u *User) ToSafeUser() *SafeUser {
 		Location:        u.Location,
 		Website:         u.Website,
 		IsPrivate:       u.IsPrivate,
+		IsEmailVerified: u.IsEmailVerified,
+		TwoFactorEnabled: u.TOTPEnabled,
 		SubscriptionTier: u.SubscriptionTier,
 		CreatedAt:       u.CreatedAt,
 		UpdatedAt:       u.UpdatedAt,
@@ -116,22 +133,25 @@ func (u *User) ToSafeUser() *SafeUser {
 // BeforeCreate is called before creating a user
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == "" {
-		u.ID = generateUUID()
+		u.ID = ids.New()
 	}
 	return nil
 }
 
-// generateUUID generates a UUID (simplified for now)
-func generateUUID() string {
-	return "user_" + time.Now().Format("20060102150405") + "_" + randomString(8)
-}
+// Subscription tiers
+const (
+	SubscriptionFree    = "free"
+	SubscriptionPremium = "premium"
+	SubscriptionPro     = "pro"
+)
 
-// randomString generates a random string
-func randomString(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = charset[time.Now().UnixNano()%int64(len(charset))]
+// EffectiveSubscriptionTier returns the user's tier, falling back to free once a paid subscription has expired
+func (u *User) EffectiveSubscriptionTier(now time.Time) string {
+	if u.SubscriptionTier == "" || u.SubscriptionTier == SubscriptionFree {
+		return SubscriptionFree
 	}
-	return string(b)
-} 
\ No newline at end of file
+	if u.SubscriptionExpires != nil && !u.SubscriptionExpires.After(now) {
+		return SubscriptionFree
+	}
+	return u.SubscriptionTier
+}
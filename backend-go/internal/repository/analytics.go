@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"time"
+
+	"digital-wardrobe-backend/internal/models"
+)
+
+// Trend groupings
+const (
+	TrendGroupNone     = ""
+	TrendGroupCategory = "category"
+	TrendGroupBrand    = "brand"
+)
+
+const (
+	// TrendAllKey is the group key of rows that are not broken down
+	TrendAllKey = "all"
+	// TrendUnknownBrand is the group key of items without a brand
+	TrendUnknownBrand = "unknown"
+)
+
+// TrendQuery selects the active items counted in a trend. Periods are
+// truncated in UTC to the interval (day, week or month), weeks starting on Monday.
+type TrendQuery struct {
+	UserID   string
+	Interval string
+	GroupBy  string
+	Start    time.Time
+	End      time.Time
+	// Statuses limits a purchases query to items that were bought
+	Statuses []string
+}
+
+// TrendRow counts the items in one period, group and currency
+type TrendRow struct {
+	Period   time.Time
+	GroupKey string
+	Currency string
+	Items    int
+	Spend    float64
+}
+
+// Analytics stores per-user analytics and runs the trend aggregates
+type Analytics interface {
+	Get(userID string) (*models.UserAnalytics, error)
+	Create(analytics *models.UserAnalytics) error
+	// Save writes every column of existing analytics
+	Save(analytics *models.UserAnalytics) error
+	Delete(userID string) error
+	// ItemsAdded counts items by when they were added. Currency is left empty.
+	ItemsAdded(query TrendQuery) ([]TrendRow, error)
+	// Purchases counts items and sums prices by when they were bought: the
+	// purchase date, falling back to when they were added
+	Purchases(query TrendQuery) ([]TrendRow, error)
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"digital-wardrobe-backend/internal/models"
+)
+
+// Collections stores collections and the items in them
+type Collections interface {
+	// List returns a user's collections, newest first
+	List(userID string) ([]models.Collection, error)
+	// Get returns a collection owned by the user
+	Get(userID, id string) (*models.Collection, error)
+	GetForUpdate(userID, id string) (*models.Collection, error)
+	// GetWithItems also loads the memberships in order, each with its item
+	GetWithItems(userID, id string) (*models.Collection, error)
+	Create(collection *models.Collection) error
+	// Save writes every column of an existing collection
+	Save(collection *models.Collection) error
+	// Delete removes a collection and its memberships
+	Delete(id string) error
+
+	GetMembership(collectionID, itemID string) (*models.CollectionItem, error)
+	ListMemberships(collectionID string) ([]models.CollectionItem, error)
+	CountMemberships(collectionID string) (int64, error)
+	// AddMembership returns ErrDuplicate if the item is already in the collection
+	AddMembership(membership *models.CollectionItem) error
+	UpdateMembership(id string, updates map[string]interface{}) error
+	DeleteMembership(id string) error
+	// ShiftOrder adds delta to the order of the memberships at or after from
+	ShiftOrder(collectionID string, from, delta int) error
+}
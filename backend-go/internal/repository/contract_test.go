@@ -0,0 +1,716 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"digital-wardrobe-backend/internal/database"
+	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
+)
+
+// contractTables are emptied between contract tests on the GORM store
+const contractTables = "users, sessions, audit_logs, mfa_recovery_codes, oauth_states, items, price_snapshots, " +
+	"collections, collection_items, price_alerts, user_analytics"
+
+// contractStores returns a constructor for every store the contract runs
+// against: the in-memory store always, and the GORM store when
+// TEST_DATABASE_URL points at a scratch database. Each call returns an empty store.
+func contractStores(t *testing.T) map[string]func(t *testing.T) repository.Store {
+	stores := map[string]func(t *testing.T) repository.Store{
+		"memory": func(t *testing.T) repository.Store { return repository.NewMemoryStore() },
+	}
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		return stores
+	}
+	db, err := database.New(url)
+	if err != nil {
+		t.Fatalf("connect to TEST_DATABASE_URL: %v", err)
+	}
+	if err := database.Migrate(context.Background(), db); err != nil {
+		t.Fatalf("migrate TEST_DATABASE_URL: %v", err)
+	}
+	stores["gorm"] = func(t *testing.T) repository.Store {
+		if err := db.Exec("TRUNCATE " + contractTables + " CASCADE").Error; err != nil {
+			t.Fatalf("truncate: %v", err)
+		}
+		return repository.NewGormStore(db)
+	}
+	return stores
+}
+
+func TestStoreContract(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, s repository.Store)
+	}{
+		{"users take column defaults", testUserDefaults},
+		{"users enforce unique emails", testUserUniqueEmail},
+		{"users are found by email and provider", testUserLookups},
+		{"user tokens expire", testUserTokens},
+		{"missing records", testNotFound},
+		{"sessions revoke", testSessionRevoke},
+		{"recovery codes are used once", testRecoveryCodes},
+		{"oauth states are taken once", testOAuthStates},
+		{"items filter and page", testItemPaging},
+		{"items search ranks by field", testItemSearch},
+		{"item delete cascades", testItemDeleteCascades},
+		{"price snapshots", testPriceSnapshots},
+		{"collection memberships", testCollectionMemberships},
+		{"price alerts trigger once", testPriceAlerts},
+		{"analytics trends", testTrends},
+		{"transactions roll back", testTransactionRollback},
+	}
+
+	for name, newStore := range contractStores(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					tt.run(t, newStore(t))
+				})
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func mustCreateUser(t *testing.T, s repository.Store, email string) *models.User {
+	t.Helper()
+	user := &models.User{Email: email}
+	if err := s.Users().Create(user); err != nil {
+		t.Fatalf("create user %s: %v", email, err)
+	}
+	return user
+}
+
+func mustCreateItem(t *testing.T, s repository.Store, item models.Item) *models.Item {
+	t.Helper()
+	if item.Category == "" {
+		item.Category = "tops"
+	}
+	if err := s.Items().Create(&item); err != nil {
+		t.Fatalf("create item %s: %v", item.Name, err)
+	}
+	return &item
+}
+
+func itemNames(items []models.Item) []string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Name
+	}
+	return names
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testUserDefaults(t *testing.T, s repository.Store) {
+	user := mustCreateUser(t, s, "ada@example.com")
+	if user.ID == "" || user.CreatedAt.IsZero() {
+		t.Fatalf("create did not set ID and CreatedAt: %+v", user)
+	}
+
+	got, err := s.Users().Get(user.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !got.IsActive || !got.AllowAnalytics || got.IsEmailVerified || got.SubscriptionTier != "free" {
+		t.Errorf("defaults not applied: active=%v analytics=%v verified=%v tier=%q",
+			got.IsActive, got.AllowAnalytics, got.IsEmailVerified, got.SubscriptionTier)
+	}
+
+	if err := s.Users().Update(user.ID, map[string]interface{}{"display_name": "Ada", "failed_login_attempts": 2}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, _ = s.Users().Get(user.ID)
+	if got.DisplayName == nil || *got.DisplayName != "Ada" || got.FailedLoginAttempts != 2 {
+		t.Errorf("update not applied: %+v", got)
+	}
+	if err := s.Users().Update(user.ID, map[string]interface{}{"display_name": nil}); err != nil {
+		t.Fatalf("update to NULL: %v", err)
+	}
+	if got, _ = s.Users().Get(user.ID); got.DisplayName != nil {
+		t.Errorf("display name = %q, want NULL", *got.DisplayName)
+	}
+}
+
+func testUserUniqueEmail(t *testing.T, s repository.Store) {
+	mustCreateUser(t, s, "ada@example.com")
+
+	err := s.Users().Create(&models.User{Email: "ada@example.com"})
+	if !errors.Is(err, repository.ErrDuplicate) {
+		t.Fatalf("duplicate email: err = %v, want ErrDuplicate", err)
+	}
+
+	// NULL never conflicts
+	if err := s.Users().Create(&models.User{Email: "grace@example.com"}); err != nil {
+		t.Fatalf("second user without username: %v", err)
+	}
+}
+
+func testUserLookups(t *testing.T, s repository.Store) {
+	user := mustCreateUser(t, s, "ada@example.com")
+	if err := s.Users().Update(user.ID, map[string]interface{}{"google_id": "g-1"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	if _, err := s.Users().FindByEmail("ADA@example.com"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("FindByEmail is exact: err = %v, want ErrNotFound", err)
+	}
+	if got, err := s.Users().FindByEmailFold("ada@example.com"); err != nil || got.ID != user.ID {
+		t.Errorf("FindByEmailFold = %v, %v", got, err)
+	}
+	if got, err := s.Users().FindByOAuthSubject("google_id", "g-1"); err != nil || got.ID != user.ID {
+		t.Errorf("FindByOAuthSubject = %v, %v", got, err)
+	}
+	if _, err := s.Users().FindByOAuthSubject("apple_id", "g-1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("FindByOAuthSubject on another provider: err = %v, want ErrNotFound", err)
+	}
+}
+
+func testUserTokens(t *testing.T, s repository.Store) {
+	now := time.Now()
+	user := mustCreateUser(t, s, "ada@example.com")
+	if err := s.Users().Update(user.ID, map[string]interface{}{
+		"password_reset_token":   "reset-hash",
+		"password_reset_expires": now.Add(time.Hour),
+		"unlock_token":           "unlock-hash",
+		"locked_until":           now.Add(-time.Hour),
+	}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	err := s.Transaction(func(tx repository.Store) error {
+		got, err := tx.Users().GetByTokenForUpdate(repository.PasswordResetToken, "reset-hash", now)
+		if err != nil || got.ID != user.ID {
+			t.Errorf("reset token = %v, %v", got, err)
+		}
+		if _, err := tx.Users().GetByTokenForUpdate(repository.PasswordResetToken, "reset-hash", now.Add(2*time.Hour)); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expired reset token: err = %v, want ErrNotFound", err)
+		}
+		if _, err := tx.Users().GetByTokenForUpdate(repository.EmailVerificationToken, "reset-hash", now); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("token of another kind: err = %v, want ErrNotFound", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+}
+
+func testNotFound(t *testing.T, s repository.Store) {
+	user := mustCreateUser(t, s, "ada@example.com")
+	missing := "01890a5d-ac96-774b-bcce-b302099a8057"
+
+	checks := map[string]error{
+		"user":         func() error { _, err := s.Users().Get(missing); return err }(),
+		"user update":  s.Users().Update(missing, map[string]interface{}{"bio": "x"}),
+		"item":         func() error { _, err := s.Items().Get(user.ID, missing); return err }(),
+		"item delete":  s.Items().Delete(missing),
+		"collection":   func() error { _, err := s.Collections().Get(user.ID, missing); return err }(),
+		"alert":        func() error { _, err := s.PriceAlerts().Get(user.ID, missing); return err }(),
+		"alert delete": s.PriceAlerts().Delete(user.ID, missing),
+		"analytics":    func() error { _, err := s.Analytics().Get(user.ID); return err }(),
+		"snapshot":     func() error { _, err := s.PriceSnapshots().Latest(missing, "USD"); return err }(),
+	}
+	for name, err := range checks {
+		if !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("%s: err = %v, want ErrNotFound", name, err)
+		}
+	}
+}
+
+func testSessionRevoke(t *testing.T, s repository.Store) {
+	now := time.Now()
+	user := mustCreateUser(t, s, "ada@example.com")
+	other := mustCreateUser(t, s, "grace@example.com")
+
+	var sessions []*models.Session
+	for i, owner := range []string{user.ID, user.ID, user.ID, other.ID} {
+		session := &models.Session{UserID: owner, Token: "token-" + string(rune('a'+i)), ExpiresAt: now.Add(time.Hour)}
+		if err := s.Sessions().Create(session); err != nil {
+			t.Fatalf("create session: %v", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if got, err := s.Sessions().GetActiveByToken("token-b"); err != nil || got.ID != sessions[1].ID {
+		t.Fatalf("GetActiveByToken = %v, %v", got, err)
+	}
+
+	n, err := s.Sessions().Revoke(repository.SessionFilter{UserID: user.ID, ExceptID: sessions[0].ID})
+	if err != nil || n != 2 {
+		t.Fatalf("Revoke = %d, %v; want 2", n, err)
+	}
+	if n, _ := s.Sessions().Revoke(repository.SessionFilter{ID: sessions[1].ID}); n != 0 {
+		t.Errorf("revoking a revoked session counted %d", n)
+	}
+
+	active, err := s.Sessions().ListActive(user.ID, now)
+	if err != nil || len(active) != 1 || active[0].ID != sessions[0].ID {
+		t.Errorf("ListActive = %v, %v; want only the kept session", active, err)
+	}
+	if _, err := s.Sessions().GetActive(sessions[2].ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("GetActive on a revoked session: err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Sessions().GetActive(sessions[3].ID); err != nil {
+		t.Errorf("another user's session was revoked: %v", err)
+	}
+}
+
+func testRecoveryCodes(t *testing.T, s repository.Store) {
+	user := mustCreateUser(t, s, "ada@example.com")
+	codes := []models.RecoveryCode{{UserID: user.ID, CodeHash: "a"}, {UserID: user.ID, CodeHash: "b"}}
+	if err := s.RecoveryCodes().Replace(user.ID, codes); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+
+	if used, err := s.RecoveryCodes().Use(user.ID, "a", time.Now()); err != nil || !used {
+		t.Fatalf("first use = %v, %v", used, err)
+	}
+	if used, _ := s.RecoveryCodes().Use(user.ID, "a", time.Now()); used {
+		t.Error("a code was used twice")
+	}
+	if n, _ := s.RecoveryCodes().CountUnused(user.ID); n != 1 {
+		t.Errorf("unused = %d, want 1", n)
+	}
+
+	if err := s.RecoveryCodes().Replace(user.ID, []models.RecoveryCode{{UserID: user.ID, CodeHash: "c"}}); err != nil {
+		t.Fatalf("replace again: %v", err)
+	}
+	if used, _ := s.RecoveryCodes().Use(user.ID, "b", time.Now()); used {
+		t.Error("a replaced code was still usable")
+	}
+}
+
+func testOAuthStates(t *testing.T, s repository.Store) {
+	now := time.Now()
+	for id, expires := range map[string]time.Time{"live": now.Add(time.Minute), "stale": now.Add(-time.Minute)} {
+		state := &models.OAuthState{ID: id, Provider: "google", CodeVerifier: "v", Nonce: "n", ExpiresAt: expires}
+		if err := s.OAuthStates().Create(state); err != nil {
+			t.Fatalf("create state: %v", err)
+		}
+	}
+
+	if _, err := s.OAuthStates().Take("live", "apple"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("taking another provider's state: err = %v, want ErrNotFound", err)
+	}
+	if state, err := s.OAuthStates().Take("live", "google"); err != nil || state.Nonce != "n" {
+		t.Fatalf("Take = %v, %v", state, err)
+	}
+	if _, err := s.OAuthStates().Take("live", "google"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("second Take: err = %v, want ErrNotFound", err)
+	}
+
+	if err := s.OAuthStates().DeleteExpired(now); err != nil {
+		t.Fatalf("DeleteExpired: %v", err)
+	}
+	if _, err := s.OAuthStates().Take("stale", "google"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expired state survived: err = %v", err)
+	}
+}
+
+func testItemPaging(t *testing.T, s repository.Store) {
+	user := mustCreateUser(t, s, "ada@example.com")
+	other := mustCreateUser(t, s, "grace@example.com")
+
+	mustCreateItem(t, s, models.Item{UserID: user.ID, Name: "Tee", Brand: ptr("Acme"), Price: ptr(20.0), Tags: models.StringSlice{"cotton", "summer"}})
+	mustCreateItem(t, s, models.Item{UserID: user.ID, Name: "Jeans", Category: "bottoms", Brand: ptr("ACME"), Price: ptr(60.0), OriginalPrice: ptr(80.0)})
+	mustCreateItem(t, s, models.Item{UserID: user.ID, Name: "Shirt", Brand: ptr("Other"), Price: ptr(40.0), Tags: models.StringSlice{"cotton"}})
+	mustCreateItem(t, s, models.Item{UserID: user.ID, Name: "Cap"})
+	mustCreateItem(t, s, models.Item{UserID: other.ID, Name: "Not mine", Price: ptr(1.0)})
+	archived := mustCreateItem(t, s, models.Item{UserID: user.ID, Name: "Old coat", Price: ptr(5.0)})
+	if err := s.Items().Update(archived.ID, map[string]interface{}{"archived_at": time.Now()}); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	got, err := s.Items().Get(user.ID, archived.ID)
+	if err != nil || got.Currency != "USD" || got.Status != models.StatusWant {
+		t.Fatalf("item defaults: %+v, %v", got, err)
+	}
+
+	filters := []struct {
+		name   string
+		filter repository.ItemFilter
+		want   int64
+	}{
+		{"active", repository.ItemFilter{UserID: user.ID}, 4},
+		{"archived", repository.ItemFilter{UserID: user.ID, Archived: true}, 1},
+		{"category", repository.ItemFilter{UserID: user.ID, Category: "bottoms"}, 1},
+		{"brand folds case", repository.ItemFilter{UserID: user.ID, Brands: []string{"acme"}}, 2},
+		{"price range", repository.ItemFilter{UserID: user.ID, MinPrice: ptr(30.0), MaxPrice: ptr(60.0)}, 2},
+		{"on sale", repository.ItemFilter{UserID: user.ID, OnSale: ptr(true)}, 1},
+		{"every tag", repository.ItemFilter{UserID: user.ID, Tags: []string{"cotton", "summer"}}, 1},
+	}
+	for _, f := range filters {
+		if n, err := s.Items().Count(f.filter); err != nil || n != f.want {
+			t.Errorf("%s: count = %d, %v; want %d", f.name, n, err, f.want)
+		}
+	}
+
+	// Page through by price, descending, two at a time
+	query := repository.ItemPageQuery{
+		Filter: repository.ItemFilter{UserID: user.ID},
+		Sort:   repository.ItemSort{Field: "price", Desc: true},
+		Limit:  2,
+	}
+	var pages [][]string
+	for {
+		page, err := s.Items().List(query)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		pages = append(pages, itemNames(page))
+		last := page[len(page)-1]
+		query.After = &repository.ItemCursor{Value: repository.ItemSortValue("price", &last), ID: last.ID}
+	}
+	if len(pages) != 2 || !equalStrings(pages[0], []string{"Jeans", "Shirt"}) || !equalStrings(pages[1], []string{"Tee", "Cap"}) {
+		t.Errorf("pages = %v", pages)
+	}
+
+	query.After, query.Offset = nil, 3
+	if page, _ := s.Items().List(query); !equalStrings(itemNames(page), []string{"Cap"}) {
+		t.Errorf("offset page = %v", itemNames(page))
+	}
+
+	all, err := s.Items().ListAll(user.ID, true)
+	if err != nil || len(all) != 5 {
+		t.Errorf("ListAll with archived = %d items, %v", len(all), err)
+	}
+}
+
+func testItemSearch(t *testing.T, s repository.Store) {
+	user := mustCreateUser(t, s, "ada@example.com")
+	mustCreateItem(t, s, models.Item{UserID: user.ID, Name: "Plain tee", Description: ptr("A linen shirt for summer")})
+	mustCreateItem(t, s, models.Item{UserID: user.ID, Name: "Linen shirt"})
+	mustCreateItem(t, s, models.Item{UserID: user.ID, Name: "Wool sweater"})
+
+	hits, total, err := s.Items().Search(repository.ItemSearchQuery{
+		Filter: repository.ItemFilter{UserID: user.ID},
+		Terms:  []string{"linen", "shi"},
+		Limit:  10,
+	})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if total != 2 || len(hits) != 2 {
+		t.Fatalf("search found %d (%d hits), want 2", total, len(hits))
+	}
+	if hits[0].Item.Name != "Linen shirt" {
+		t.Errorf("name match should rank first, got %q", hits[0].Item.Name)
+	}
+	want := repository.HighlightStart + "Linen" + repository.HighlightStop + " " +
+		repository.HighlightStart + "shirt" + repository.HighlightStop
+	if hits[0].NameHighlight != want {
+		t.Errorf("highlight = %q", hits[0].NameHighlight)
+	}
+
+	if _, total, _ := s.Items().Search(repository.ItemSearchQuery{
+		Filter: repository.ItemFilter{UserID: user.ID},
+		Terms:  []string{"shi", "linen"},
+		Limit:  10,
+	}); total != 0 {
+		t.Errorf("only the last term is a prefix, but %d items matched", total)
+	}
+}
+
+func testItemDeleteCascades(t *testing.T, s repository.Store) {
+	user := mustCreateUser(t, s, "ada@example.com")
+	item := mustCreateItem(t, s, models.Item{UserID: user.ID, Name: "Tee"})
+	collection := &models.Collection{UserID: user.ID, Name: "Summer"}
+	if err := s.Collections().Create(collection); err != nil {
+		t.Fatalf("create collection: %v", err)
+	}
+	if err := s.Collections().AddMembership(&models.CollectionItem{CollectionID: collection.ID, ItemID: item.ID}); err != nil {
+		t.Fatalf("add membership: %v", err)
+	}
+	if err := s.PriceSnapshots().Create(&models.PriceSnapshot{ItemID: item.ID, UserID: user.ID, Price: 10, Source: models.PriceSourceItem, ObservedAt: time.Now()}); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	alert := &models.PriceAlert{UserID: user.ID, ItemID: &item.ID, ProductURL: "https://shop.example/tee", TargetPrice: 5}
+	if err := s.PriceAlerts().Create(alert); err != nil {
+		t.Fatalf("create alert: %v", err)
+	}
+
+	if err := s.Items().Delete(item.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if n, _ := s.Collections().CountMemberships(collection.ID); n != 0 {
+		t.Errorf("%d memberships survived", n)
+	}
+	if _, err := s.PriceSnapshots().Latest(item.ID, "USD"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("snapshot survived: err = %v", err)
+	}
+	if _, err := s.PriceAlerts().Get(user.ID, alert.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("alert survived: err = %v", err)
+	}
+}
+
+func testPriceSnapshots(t *testing.T, s repository.Store) {
+	user := mustCreateUser(t, s, "ada@example.com")
+	tee := mustCreateItem(t, s, models.Item{UserID: user.ID, Name: "Tee"})
+	hat := mustCreateItem(t, s, models.Item{UserID: user.ID, Name: "Cap"})
+
+	base := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	for i, price := range []float64{30, 20, 25} {
+		snapshot := &models.PriceSnapshot{ItemID: tee.ID, UserID: user.ID, Price: price, Source: models.PriceSourceExtension, ObservedAt: base.Add(time.Duration(i) * 24 * time.Hour)}
+		if err := s.PriceSnapshots().Create(snapshot); err != nil {
+			t.Fatalf("create snapshot: %v", err)
+		}
+	}
+	if err := s.PriceSnapshots().Create(&models.PriceSnapshot{ItemID: tee.ID, UserID: user.ID, Price: 99, Currency: "EUR", Source: models.PriceSourceExtension, ObservedAt: base.Add(96 * time.Hour)}); err != nil {
+		t.Fatalf("create EUR snapshot: %v", err)
+	}
+	if err := s.PriceSnapshots().Create(&models.PriceSnapshot{ItemID: hat.ID, UserID: user.ID, Price: 5, Source: models.PriceSourceItem, ObservedAt: base}); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+
+	if latest, err := s.PriceSnapshots().Latest(tee.ID, "USD"); err != nil || latest.Price != 25 {
+		t.Errorf("Latest = %v, %v; want 25", latest, err)
+	}
+	if earliest, err := s.PriceSnapshots().Earliest(tee.ID, "USD"); err != nil || earliest.Price != 30 {
+		t.Errorf("Earliest = %v, %v; want 30", earliest, err)
+	}
+
+	since, err := s.PriceSnapshots().ListSince(tee.ID, "USD", base.Add(24*time.Hour))
+	if err != nil || len(since) != 2 || since[0].Price != 20 || since[1].Price != 25 {
+		t.Errorf("ListSince = %v, %v; want 20 then 25", since, err)
+	}
+
+	latest, err := s.PriceSnapshots().LatestForItems([]string{tee.ID, hat.ID})
+	if err != nil || len(latest) != 2 {
+		t.Fatalf("LatestForItems = %v, %v", latest, err)
+	}
+	for _, snapshot := range latest {
+		if want := map[string]float64{tee.ID: 99, hat.ID: 5}[snapshot.ItemID]; snapshot.Price != want {
+			t.Errorf("latest price of %s = %v, want %v", snapshot.ItemID, snapshot.Price, want)
+		}
+	}
+}
+
+func testCollectionMemberships(t *testing.T, s repository.Store) {
+	user := mustCreateUser(t, s, "ada@example.com")
+	collection := &models.Collection{UserID: user.ID, Name: "Capsule"}
+	if err := s.Collections().Create(collection); err != nil {
+		t.Fatalf("create collection: %v", err)
+	}
+
+	var items []*models.Item
+	for i, name := range []string{"Tee", "Jeans", "Boots"} {
+		item := mustCreateItem(t, s, models.Item{UserID: user.ID, Name: name})
+		items = append(items, item)
+		if err := s.Collections().AddMembership(&models.CollectionItem{CollectionID: collection.ID, ItemID: item.ID, Order: i}); err != nil {
+			t.Fatalf("add %s: %v", name, err)
+		}
+	}
+
+	err := s.Collections().AddMembership(&models.CollectionItem{CollectionID: collection.ID, ItemID: items[0].ID})
+	if !errors.Is(err, repository.ErrDuplicate) {
+		t.Fatalf("adding an item twice: err = %v, want ErrDuplicate", err)
+	}
+
+	// Move Boots to the front
+	boots, err := s.Collections().GetMembership(collection.ID, items[2].ID)
+	if err != nil {
+		t.Fatalf("get membership: %v", err)
+	}
+	if err := s.Collections().ShiftOrder(collection.ID, 0, 1); err != nil {
+		t.Fatalf("shift: %v", err)
+	}
+	if err := s.Collections().UpdateMembership(boots.ID, map[string]interface{}{"order": 0, "notes": "wear first"}); err != nil {
+		t.Fatalf("update membership: %v", err)
+	}
+
+	got, err := s.Collections().GetWithItems(user.ID, collection.ID)
+	if err != nil {
+		t.Fatalf("get with items: %v", err)
+	}
+	var names []string
+	for _, m := range got.Items {
+		names = append(names, m.Item.Name)
+	}
+	if !equalStrings(names, []string{"Boots", "Tee", "Jeans"}) {
+		t.Errorf("order = %v", names)
+	}
+	if got.Items[0].Notes == nil || *got.Items[0].Notes != "wear first" {
+		t.Errorf("notes = %v", got.Items[0].Notes)
+	}
+
+	if err := s.Collections().Delete(collection.ID); err != nil {
+		t.Fatalf("delete collection: %v", err)
+	}
+	if n, _ := s.Collections().CountMemberships(collection.ID); n != 0 {
+		t.Errorf("%d memberships survived their collection", n)
+	}
+	if _, err := s.Items().Get(user.ID, items[0].ID); err != nil {
+		t.Errorf("deleting a collection removed its items: %v", err)
+	}
+}
+
+func testPriceAlerts(t *testing.T, s repository.Store) {
+	user := mustCreateUser(t, s, "ada@example.com")
+	now := time.Now()
+
+	newAlert := func(lastChecked *time.Time) *models.PriceAlert {
+		alert := &models.PriceAlert{UserID: user.ID, ProductURL: "https://shop.example/p", TargetPrice: 10, LastCheckedAt: lastChecked}
+		if err := s.PriceAlerts().Create(alert); err != nil {
+			t.Fatalf("create alert: %v", err)
+		}
+		return alert
+	}
+	recent := newAlert(ptr(now.Add(-time.Minute)))
+	stale := newAlert(ptr(now.Add(-2 * time.Hour)))
+	never := newAlert(nil)
+
+	got, err := s.PriceAlerts().Get(user.ID, never.ID)
+	if err != nil || !got.IsActive || got.IsTriggered || !got.EmailNotification || got.Currency != "USD" {
+		t.Fatalf("alert defaults: %+v, %v", got, err)
+	}
+
+	due, err := s.PriceAlerts().Due(now.Add(-time.Hour), 10)
+	if err != nil || len(due) != 2 || due[0].ID != never.ID || due[1].ID != stale.ID {
+		t.Errorf("Due = %v, %v; want the never-checked alert, then the stale one", due, err)
+	}
+
+	updates := map[string]interface{}{"is_triggered": true, "triggered_at": now, "current_price": 9.5}
+	if fired, err := s.PriceAlerts().Trigger(stale.ID, updates); err != nil || !fired {
+		t.Fatalf("Trigger = %v, %v", fired, err)
+	}
+	if fired, _ := s.PriceAlerts().Trigger(stale.ID, updates); fired {
+		t.Error("an alert triggered twice")
+	}
+
+	if n, _ := s.PriceAlerts().CountWatching(user.ID); n != 2 {
+		t.Errorf("watching = %d, want 2", n)
+	}
+	triggered, _ := s.PriceAlerts().List(repository.PriceAlertFilter{UserID: user.ID, Triggered: ptr(true)})
+	if len(triggered) != 1 || triggered[0].ID != stale.ID || floatValue(triggered[0].CurrentPrice) != 9.5 {
+		t.Errorf("triggered alerts = %v", triggered)
+	}
+
+	if err := s.PriceAlerts().Delete(user.ID, recent.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := s.PriceAlerts().Delete(user.ID, recent.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("second delete: err = %v, want ErrNotFound", err)
+	}
+}
+
+func floatValue(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+	return *f
+}
+
+func testTrends(t *testing.T, s repository.Store) {
+	user := mustCreateUser(t, s, "ada@example.com")
+	jan := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	feb := time.Date(2026, 2, 10, 12, 0, 0, 0, time.UTC)
+
+	mustCreateItem(t, s, models.Item{UserID: user.ID, Name: "Tee", Brand: ptr(" Acme "), Price: ptr(20.0), Status: models.StatusOwned, CreatedAt: jan})
+	mustCreateItem(t, s, models.Item{UserID: user.ID, Name: "Jeans", Brand: ptr("acme"), Price: ptr(60.0), Status: models.StatusPurchased, CreatedAt: jan, PurchaseDate: &feb})
+	mustCreateItem(t, s, models.Item{UserID: user.ID, Name: "Scarf", Price: ptr(15.0), Currency: "EUR", Status: models.StatusOwned, CreatedAt: feb})
+	mustCreateItem(t, s, models.Item{UserID: user.ID, Name: "Wish", Price: ptr(99.0), CreatedAt: feb})
+
+	query := repository.TrendQuery{
+		UserID:   user.ID,
+		Interval: "month",
+		GroupBy:  repository.TrendGroupBrand,
+		Start:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:      time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	added, err := s.Analytics().ItemsAdded(query)
+	if err != nil {
+		t.Fatalf("ItemsAdded: %v", err)
+	}
+	addedBy := map[string]int{}
+	for _, row := range added {
+		addedBy[row.Period.UTC().Format("2006-01")+" "+row.GroupKey] += row.Items
+	}
+	wantAdded := map[string]int{"2026-01 acme": 2, "2026-02 " + repository.TrendUnknownBrand: 2}
+	if len(addedBy) != len(wantAdded) {
+		t.Errorf("items added = %v, want %v", addedBy, wantAdded)
+	}
+	for k, v := range wantAdded {
+		if addedBy[k] != v {
+			t.Errorf("items added %s = %d, want %d", k, addedBy[k], v)
+		}
+	}
+
+	query.GroupBy = repository.TrendGroupNone
+	query.Statuses = []string{models.StatusPurchased, models.StatusOwned}
+	purchases, err := s.Analytics().Purchases(query)
+	if err != nil {
+		t.Fatalf("Purchases: %v", err)
+	}
+	spend := map[string]float64{}
+	for _, row := range purchases {
+		if row.GroupKey != repository.TrendAllKey {
+			t.Errorf("ungrouped row key = %q", row.GroupKey)
+		}
+		spend[row.Period.UTC().Format("2006-01")+" "+row.Currency] += row.Spend
+	}
+	wantSpend := map[string]float64{"2026-01 USD": 20, "2026-02 USD": 60, "2026-02 EUR": 15}
+	if len(spend) != len(wantSpend) {
+		t.Errorf("spend = %v, want %v", spend, wantSpend)
+	}
+	for k, v := range wantSpend {
+		if spend[k] != v {
+			t.Errorf("spend %s = %v, want %v", k, spend[k], v)
+		}
+	}
+}
+
+func testTransactionRollback(t *testing.T, s repository.Store) {
+	user := mustCreateUser(t, s, "ada@example.com")
+	boom := errors.New("boom")
+
+	err := s.Transaction(func(tx repository.Store) error {
+		if err := tx.Users().Update(user.ID, map[string]interface{}{"bio": "changed"}); err != nil {
+			return err
+		}
+		return tx.Transaction(func(tx repository.Store) error {
+			mustCreateUser(t, tx, "grace@example.com")
+			return boom
+		})
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("transaction err = %v, want boom", err)
+	}
+
+	if got, _ := s.Users().Get(user.ID); got.Bio != nil {
+		t.Errorf("update survived the rollback: bio = %q", *got.Bio)
+	}
+	if _, err := s.Users().FindByEmail("grace@example.com"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("insert survived the rollback: err = %v", err)
+	}
+
+	if err := s.Transaction(func(tx repository.Store) error {
+		return tx.Users().Update(user.ID, map[string]interface{}{"bio": "kept"})
+	}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if got, _ := s.Users().Get(user.ID); got.Bio == nil || *got.Bio != "kept" {
+		t.Errorf("committed update lost: %v", got.Bio)
+	}
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"fmt"
+
+	"digital-wardrobe-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// trendGroupExprs maps each trend grouping to the SQL expression it groups on
+var trendGroupExprs = map[string]string{
+	TrendGroupNone:     "'" + TrendAllKey + "'",
+	TrendGroupCategory: "items.category",
+	TrendGroupBrand:    "COALESCE(NULLIF(LOWER(TRIM(items.brand)), ''), '" + TrendUnknownBrand + "')",
+}
+
+// gormAnalytics implements Analytics
+type gormAnalytics struct {
+	db *gorm.DB
+}
+
+func (r *gormAnalytics) Get(userID string) (*models.UserAnalytics, error) {
+	return first[models.UserAnalytics](r.db, "user_id = ?", userID)
+}
+
+func (r *gormAnalytics) Create(analytics *models.UserAnalytics) error {
+	return translateError(r.db.Create(analytics).Error)
+}
+
+func (r *gormAnalytics) Save(analytics *models.UserAnalytics) error {
+	return translateError(r.db.Save(analytics).Error)
+}
+
+func (r *gormAnalytics) Delete(userID string) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.UserAnalytics{}).Error
+}
+
+func (r *gormAnalytics) ItemsAdded(q TrendQuery) ([]TrendRow, error) {
+	groupExpr, ok := trendGroupExprs[q.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported trend grouping %q", q.GroupBy)
+	}
+
+	rows := []TrendRow{}
+	err := r.db.Model(&models.Item{}).
+		Select("date_trunc(?, items.created_at AT TIME ZONE 'UTC') AS period, "+groupExpr+" AS group_key, COUNT(*) AS items", q.Interval).
+		Where("items.user_id = ? AND items.archived_at IS NULL", q.UserID).
+		Where("items.created_at >= ? AND items.created_at < ?", q.Start, q.End).
+		Group("period, group_key").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *gormAnalytics) Purchases(q TrendQuery) ([]TrendRow, error) {
+	groupExpr, ok := trendGroupExprs[q.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported trend grouping %q", q.GroupBy)
+	}
+
+	purchasedAt := "COALESCE(items.purchase_date, items.created_at)"
+	rows := []TrendRow{}
+	err := r.db.Model(&models.Item{}).
+		Select("date_trunc(?, "+purchasedAt+" AT TIME ZONE 'UTC') AS period, "+groupExpr+" AS group_key, items.currency AS currency, COUNT(*) AS items, COALESCE(SUM(items.price), 0) AS spend", q.Interval).
+		Where("items.user_id = ? AND items.archived_at IS NULL AND items.status IN ?", q.UserID, q.Statuses).
+		Where(purchasedAt+" >= ? AND "+purchasedAt+" < ?", q.Start, q.End).
+		Group("period, group_key, currency").
+		Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"digital-wardrobe-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// gormCollections implements Collections
+type gormCollections struct {
+	db *gorm.DB
+}
+
+func (r *gormCollections) List(userID string) ([]models.Collection, error) {
+	collections := []models.Collection{}
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&collections).Error
+	return collections, err
+}
+
+func (r *gormCollections) Get(userID, id string) (*models.Collection, error) {
+	return first[models.Collection](r.db, "id = ? AND user_id = ?", id, userID)
+}
+
+func (r *gormCollections) GetForUpdate(userID, id string) (*models.Collection, error) {
+	return first[models.Collection](forUpdate(r.db), "id = ? AND user_id = ?", id, userID)
+}
+
+func (r *gormCollections) GetWithItems(userID, id string) (*models.Collection, error) {
+	query := r.db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order(`collection_items."order" ASC, collection_items.created_at ASC`)
+		}).
+		Preload("Items.Item")
+	return first[models.Collection](query, "id = ? AND user_id = ?", id, userID)
+}
+
+func (r *gormCollections) Create(collection *models.Collection) error {
+	return translateError(r.db.Create(collection).Error)
+}
+
+func (r *gormCollections) Save(collection *models.Collection) error {
+	return translateError(r.db.Save(collection).Error)
+}
+
+func (r *gormCollections) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", id).Delete(&models.CollectionItem{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Collection{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *gormCollections) GetMembership(collectionID, itemID string) (*models.CollectionItem, error) {
+	return first[models.CollectionItem](r.db, "collection_id = ? AND item_id = ?", collectionID, itemID)
+}
+
+func (r *gormCollections) ListMemberships(collectionID string) ([]models.CollectionItem, error) {
+	memberships := []models.CollectionItem{}
+	err := r.db.Where("collection_id = ?", collectionID).Find(&memberships).Error
+	return memberships, err
+}
+
+func (r *gormCollections) CountMemberships(collectionID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.CollectionItem{}).Where("collection_id = ?", collectionID).Count(&count).Error
+	return count, err
+}
+
+func (r *gormCollections) AddMembership(membership *models.CollectionItem) error {
+	return translateError(r.db.Create(membership).Error)
+}
+
+func (r *gormCollections) UpdateMembership(id string, updates map[string]interface{}) error {
+	return updateByID(r.db, &models.CollectionItem{}, id, updates)
+}
+
+func (r *gormCollections) DeleteMembership(id string) error {
+	result := r.db.Delete(&models.CollectionItem{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormCollections) ShiftOrder(collectionID string, from, delta int) error {
+	return r.db.Model(&models.CollectionItem{}).
+		Where(`collection_id = ? AND "order" >= ?`, collectionID, from).
+		Update("order", gorm.Expr(`"order" + ?`, delta)).Error
+}
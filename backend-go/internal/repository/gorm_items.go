@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"digital-wardrobe-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Options for ts_headline, highlighting with HighlightStart and HighlightStop
+const (
+	searchHighlightOptions = "StartSel=\"" + HighlightStart + "\", StopSel=\"" + HighlightStop + "\""
+	nameHeadlineOptions    = "HighlightAll=true, " + searchHighlightOptions
+	searchHeadlineOptions  = searchHighlightOptions + ", MaxWords=25, MinWords=8, MaxFragments=2, FragmentDelimiter=\" … \""
+)
+
+// gormItems implements Items
+type gormItems struct {
+	db *gorm.DB
+}
+
+func (r *gormItems) Create(item *models.Item) error {
+	return translateError(r.db.Create(item).Error)
+}
+
+func (r *gormItems) Get(userID, id string) (*models.Item, error) {
+	return first[models.Item](r.db, "id = ? AND user_id = ?", id, userID)
+}
+
+func (r *gormItems) GetForUpdate(userID, id string) (*models.Item, error) {
+	return first[models.Item](forUpdate(r.db), "id = ? AND user_id = ?", id, userID)
+}
+
+func (r *gormItems) Save(item *models.Item) error {
+	return translateError(r.db.Save(item).Error)
+}
+
+func (r *gormItems) Update(id string, updates map[string]interface{}) error {
+	return updateByID(r.db, &models.Item{}, id, updates)
+}
+
+// Delete relies on the foreign keys to cascade
+func (r *gormItems) Delete(id string) error {
+	result := r.db.Delete(&models.Item{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormItems) Count(filter ItemFilter) (int64, error) {
+	query, err := r.filtered(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	err = query.Count(&total).Error
+	return total, err
+}
+
+func (r *gormItems) List(q ItemPageQuery) ([]models.Item, error) {
+	spec, ok := itemSortFields[q.Sort.Field]
+	if !ok {
+		return nil, fmt.Errorf("unsupported item sort field %q", q.Sort.Field)
+	}
+	query, err := r.filtered(q.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, op := "ASC", ">"
+	if q.Sort.Desc {
+		dir, op = "DESC", "<"
+	}
+	query = query.Order(spec.expr + " " + dir).Order("items.id " + dir).Limit(q.Limit)
+
+	if q.After != nil {
+		query = query.Where(fmt.Sprintf("(%s, items.id) %s (?, ?)", spec.expr, op), q.After.Value, q.After.ID)
+	} else {
+		query = query.Offset(q.Offset)
+	}
+
+	items := []models.Item{}
+	err = query.Find(&items).Error
+	return items, err
+}
+
+func (r *gormItems) ListAll(userID string, includeArchived bool) ([]models.Item, error) {
+	query := r.db.Where("user_id = ?", userID)
+	if !includeArchived {
+		query = query.Where("archived_at IS NULL")
+	}
+
+	items := []models.Item{}
+	err := query.Find(&items).Error
+	return items, err
+}
+
+// searchHit is the raw row returned by the ranking query
+type searchHit struct {
+	ID            string
+	SearchRank    float64
+	NameHighlight string
+	Snippet       string
+}
+
+func (r *gormItems) Search(q ItemSearchQuery) ([]ItemSearchHit, int64, error) {
+	tsQuery := prefixTSQuery(q.Terms)
+	if tsQuery == "" {
+		return []ItemSearchHit{}, 0, nil
+	}
+
+	query, err := r.filtered(q.Filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	query = query.Where("items.search_vector @@ to_tsquery('english', ?)", tsQuery)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []searchHit
+	err = query.Session(&gorm.Session{}).
+		Select(
+			"items.id, "+
+				"ts_rank_cd(items.search_vector, to_tsquery('english', ?)) AS search_rank, "+
+				"ts_headline('english', items.name, to_tsquery('english', ?), ?) AS name_highlight, "+
+				"ts_headline('english', concat_ws(' ', items.description, items.notes, items.material, items.color), to_tsquery('english', ?), ?) AS snippet",
+			tsQuery, tsQuery, nameHeadlineOptions, tsQuery, searchHeadlineOptions,
+		).
+		Order("search_rank DESC").
+		Order("items.created_at DESC").
+		Offset(q.Offset).
+		Limit(q.Limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits := []ItemSearchHit{}
+	if len(rows) == 0 {
+		return hits, total, nil
+	}
+
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+
+	var items []models.Item
+	if err := r.db.Where("id IN ?", ids).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+
+	byID := make(map[string]models.Item, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	for _, row := range rows {
+		item, ok := byID[row.ID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, ItemSearchHit{
+			Item:          item,
+			Rank:          row.SearchRank,
+			NameHighlight: row.NameHighlight,
+			Snippet:       row.Snippet,
+		})
+	}
+
+	return hits, total, nil
+}
+
+// filtered narrows an items query by filter
+func (r *gormItems) filtered(filter ItemFilter) (*gorm.DB, error) {
+	query := r.db.Model(&models.Item{}).Where("items.user_id = ?", filter.UserID)
+
+	if filter.Archived {
+		query = query.Where("items.archived_at IS NOT NULL")
+	} else {
+		query = query.Where("items.archived_at IS NULL")
+	}
+
+	if filter.Category != "" {
+		query = query.Where("items.category = ?", filter.Category)
+	}
+	if filter.Status != "" {
+		query = query.Where("items.status = ?", filter.Status)
+	}
+	if filter.MinPrice != nil {
+		query = query.Where("items.price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("items.price <= ?", *filter.MaxPrice)
+	}
+	if len(filter.Brands) > 0 {
+		query = query.Where("LOWER(items.brand) IN ?", lowerAll(filter.Brands))
+	}
+	if len(filter.Colors) > 0 {
+		query = query.Where("LOWER(items.color) IN ?", lowerAll(filter.Colors))
+	}
+	if len(filter.Sizes) > 0 {
+		query = query.Where("LOWER(items.size) IN ?", lowerAll(filter.Sizes))
+	}
+	if filter.OnSale != nil {
+		onSale := "items.price IS NOT NULL AND items.original_price IS NOT NULL AND items.price < items.original_price"
+		if *filter.OnSale {
+			query = query.Where(onSale)
+		} else {
+			query = query.Where("NOT (" + onSale + ")")
+		}
+	}
+	if len(filter.Tags) > 0 {
+		encoded, err := json.Marshal(filter.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode tags: %w", err)
+		}
+		query = query.Where("items.tags @> ?::jsonb", string(encoded))
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("items.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("items.created_at < ?", *filter.CreatedBefore)
+	}
+
+	return query, nil
+}
+
+// prefixTSQuery AND-s search terms into a to_tsquery expression with a :*
+// prefix operator on the last term. Terms must be letters and digits only.
+func prefixTSQuery(terms []string) string {
+	if len(terms) == 0 {
+		return ""
+	}
+	query := strings.Join(terms, " & ")
+	return query + ":*"
+}
+
+// lowerAll returns a lower-cased copy of values
+func lowerAll(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strings.ToLower(v)
+	}
+	return out
+}
+
+// gormPriceSnapshots implements PriceSnapshots
+type gormPriceSnapshots struct {
+	db *gorm.DB
+}
+
+func (r *gormPriceSnapshots) Create(snapshot *models.PriceSnapshot) error {
+	return translateError(r.db.Create(snapshot).Error)
+}
+
+func (r *gormPriceSnapshots) Latest(itemID, currency string) (*models.PriceSnapshot, error) {
+	return first[models.PriceSnapshot](r.db.Order("observed_at DESC"), "item_id = ? AND currency = ?", itemID, currency)
+}
+
+func (r *gormPriceSnapshots) Earliest(itemID, currency string) (*models.PriceSnapshot, error) {
+	return first[models.PriceSnapshot](r.db.Order("observed_at ASC"), "item_id = ? AND currency = ?", itemID, currency)
+}
+
+func (r *gormPriceSnapshots) ListSince(itemID, currency string, since time.Time) ([]models.PriceSnapshot, error) {
+	snapshots := []models.PriceSnapshot{}
+	err := r.db.Where("item_id = ? AND currency = ? AND observed_at >= ?", itemID, currency, since).
+		Order("observed_at ASC").
+		Find(&snapshots).Error
+	return snapshots, err
+}
+
+func (r *gormPriceSnapshots) LatestForItems(itemIDs []string) ([]models.PriceSnapshot, error) {
+	snapshots := []models.PriceSnapshot{}
+	if len(itemIDs) == 0 {
+		return snapshots, nil
+	}
+	err := r.db.Raw(`SELECT DISTINCT ON (item_id) * FROM price_snapshots
+		WHERE item_id IN ? ORDER BY item_id, observed_at DESC`, itemIDs).
+		Scan(&snapshots).Error
+	return snapshots, err
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"time"
+
+	"digital-wardrobe-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// gormPriceAlerts implements PriceAlerts
+type gormPriceAlerts struct {
+	db *gorm.DB
+}
+
+func (r *gormPriceAlerts) List(filter PriceAlertFilter) ([]models.PriceAlert, error) {
+	query := r.db.Where("user_id = ?", filter.UserID)
+	if filter.Active != nil {
+		query = query.Where("is_active = ?", *filter.Active)
+	}
+	if filter.Triggered != nil {
+		query = query.Where("is_triggered = ?", *filter.Triggered)
+	}
+	if filter.ItemID != "" {
+		query = query.Where("item_id = ?", filter.ItemID)
+	}
+
+	alerts := []models.PriceAlert{}
+	err := query.Order("created_at DESC").Find(&alerts).Error
+	return alerts, err
+}
+
+func (r *gormPriceAlerts) Get(userID, id string) (*models.PriceAlert, error) {
+	return first[models.PriceAlert](r.db, "id = ? AND user_id = ?", id, userID)
+}
+
+func (r *gormPriceAlerts) Create(alert *models.PriceAlert) error {
+	return translateError(r.db.Create(alert).Error)
+}
+
+func (r *gormPriceAlerts) Save(alert *models.PriceAlert) error {
+	return translateError(r.db.Save(alert).Error)
+}
+
+func (r *gormPriceAlerts) Update(id string, updates map[string]interface{}) error {
+	return updateByID(r.db, &models.PriceAlert{}, id, updates)
+}
+
+func (r *gormPriceAlerts) Delete(userID, id string) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.PriceAlert{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormPriceAlerts) CountWatching(userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PriceAlert{}).
+		Where("user_id = ? AND is_active = ? AND is_triggered = ?", userID, true, false).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *gormPriceAlerts) Due(checkedBefore time.Time, limit int) ([]models.PriceAlert, error) {
+	alerts := []models.PriceAlert{}
+	err := r.db.
+		Where("is_active = ? AND is_triggered = ?", true, false).
+		Where("last_checked_at IS NULL OR last_checked_at < ?", checkedBefore).
+		Order("last_checked_at ASC NULLS FIRST").
+		Limit(limit).
+		Find(&alerts).Error
+	return alerts, err
+}
+
+func (r *gormPriceAlerts) Trigger(id string, updates map[string]interface{}) (bool, error) {
+	result := r.db.Model(&models.PriceAlert{}).
+		Where("id = ? AND is_triggered = ?", id, false).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
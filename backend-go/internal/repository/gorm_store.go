@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"digital-wardrobe-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormStore implements Store on a GORM connection
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore returns a Store backed by db. db should be opened with
+// TranslateError so unique violations surface as ErrDuplicate.
+func NewGormStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+func (s *gormStore) Users() Users                   { return &gormUsers{db: s.db} }
+func (s *gormStore) Sessions() Sessions             { return &gormSessions{db: s.db} }
+func (s *gormStore) AuditLogs() AuditLogs           { return &gormAuditLogs{db: s.db} }
+func (s *gormStore) RecoveryCodes() RecoveryCodes   { return &gormRecoveryCodes{db: s.db} }
+func (s *gormStore) OAuthStates() OAuthStates       { return &gormOAuthStates{db: s.db} }
+func (s *gormStore) Items() Items                   { return &gormItems{db: s.db} }
+func (s *gormStore) PriceSnapshots() PriceSnapshots { return &gormPriceSnapshots{db: s.db} }
+func (s *gormStore) Collections() Collections       { return &gormCollections{db: s.db} }
+func (s *gormStore) PriceAlerts() PriceAlerts       { return &gormPriceAlerts{db: s.db} }
+func (s *gormStore) Analytics() Analytics           { return &gormAnalytics{db: s.db} }
+
+// WithContext returns a Store whose queries run under ctx
+func (s *gormStore) WithContext(ctx context.Context) Store {
+	return &gormStore{db: s.db.WithContext(ctx)}
+}
+
+// Transaction runs fn in a database transaction. Nested calls use savepoints.
+func (s *gormStore) Transaction(fn func(tx Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{db: tx})
+	})
+}
+
+// translateError maps GORM errors to the repository's
+func translateError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return fmt.Errorf("%w: %v", ErrDuplicate, err)
+	}
+	return err
+}
+
+// forUpdate locks the rows a query reads until the transaction ends
+func forUpdate(db *gorm.DB) *gorm.DB {
+	return db.Clauses(clause.Locking{Strength: "UPDATE"})
+}
+
+// first loads the first row matching the conditions into a new T
+func first[T any](db *gorm.DB, query string, args ...interface{}) (*T, error) {
+	var row T
+	if err := db.Where(query, args...).First(&row).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &row, nil
+}
+
+// updateByID applies column updates to the row of model with the given ID
+func updateByID(db *gorm.DB, model interface{}, id string, updates map[string]interface{}) error {
+	result := db.Model(model).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// oauthSubjectColumns are the user columns holding provider subject IDs
+var oauthSubjectColumns = map[string]bool{
+	"google_id":   true,
+	"facebook_id": true,
+	"apple_id":    true,
+}
+
+// userTokenColumns maps each emailed token to its hash and expiry columns
+var userTokenColumns = map[UserToken][2]string{
+	EmailVerificationToken: {"email_verification_token", "email_verification_expires"},
+	PasswordResetToken:     {"password_reset_token", "password_reset_expires"},
+	UnlockToken:            {"unlock_token", "locked_until"},
+}
+
+// gormUsers implements Users
+type gormUsers struct {
+	db *gorm.DB
+}
+
+func (r *gormUsers) Create(user *models.User) error {
+	return translateError(r.db.Create(user).Error)
+}
+
+func (r *gormUsers) Get(id string) (*models.User, error) {
+	return first[models.User](r.db, "id = ?", id)
+}
+
+func (r *gormUsers) GetForUpdate(id string) (*models.User, error) {
+	return first[models.User](forUpdate(r.db), "id = ?", id)
+}
+
+func (r *gormUsers) FindByEmail(email string) (*models.User, error) {
+	return first[models.User](r.db, "email = ?", email)
+}
+
+func (r *gormUsers) FindByEmailFold(email string) (*models.User, error) {
+	return first[models.User](r.db, "LOWER(email) = ?", email)
+}
+
+func (r *gormUsers) FindByOAuthSubject(column, subject string) (*models.User, error) {
+	if !oauthSubjectColumns[column] {
+		return nil, fmt.Errorf("unknown oauth subject column %q", column)
+	}
+	return first[models.User](r.db, column+" = ?", subject)
+}
+
+func (r *gormUsers) GetByTokenForUpdate(token UserToken, tokenHash string, now time.Time) (*models.User, error) {
+	columns, ok := userTokenColumns[token]
+	if !ok {
+		return nil, fmt.Errorf("unknown user token %d", token)
+	}
+	return first[models.User](forUpdate(r.db), columns[0]+" = ? AND "+columns[1]+" > ?", tokenHash, now)
+}
+
+func (r *gormUsers) Update(id string, updates map[string]interface{}) error {
+	return updateByID(r.db, &models.User{}, id, updates)
+}
+
+// gormSessions implements Sessions
+type gormSessions struct {
+	db *gorm.DB
+}
+
+func (r *gormSessions) Create(session *models.Session) error {
+	return translateError(r.db.Create(session).Error)
+}
+
+func (r *gormSessions) GetForUpdate(id string) (*models.Session, error) {
+	return first[models.Session](forUpdate(r.db), "id = ?", id)
+}
+
+func (r *gormSessions) GetActive(id string) (*models.Session, error) {
+	return first[models.Session](r.db, "id = ? AND is_active = ?", id, true)
+}
+
+func (r *gormSessions) GetActiveByToken(token string) (*models.Session, error) {
+	return first[models.Session](r.db, "token = ? AND is_active = ?", token, true)
+}
+
+func (r *gormSessions) ListActive(userID string, now time.Time) ([]models.Session, error) {
+	sessions := []models.Session{}
+	err := r.db.Where("user_id = ? AND is_active = ? AND expires_at > ?", userID, true, now).
+		Order("updated_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *gormSessions) Update(id string, updates map[string]interface{}) error {
+	return updateByID(r.db, &models.Session{}, id, updates)
+}
+
+func (r *gormSessions) Revoke(filter SessionFilter) (int64, error) {
+	if filter.ID == "" && filter.UserID == "" && filter.Token == "" {
+		return 0, errors.New("revoking sessions needs an ID, user or token")
+	}
+
+	query := r.db.Model(&models.Session{}).Where("is_active = ?", true)
+	if filter.ID != "" {
+		query = query.Where("id = ?", filter.ID)
+	}
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Token != "" {
+		query = query.Where("token = ?", filter.Token)
+	}
+	if filter.ExceptID != "" {
+		query = query.Where("id <> ?", filter.ExceptID)
+	}
+
+	result := query.Update("is_active", false)
+	return result.RowsAffected, result.Error
+}
+
+// gormAuditLogs implements AuditLogs
+type gormAuditLogs struct {
+	db *gorm.DB
+}
+
+func (r *gormAuditLogs) Create(entry *models.AuditLog) error {
+	return translateError(r.db.Create(entry).Error)
+}
+
+// gormRecoveryCodes implements RecoveryCodes
+type gormRecoveryCodes struct {
+	db *gorm.DB
+}
+
+func (r *gormRecoveryCodes) Replace(userID string, codes []models.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return translateError(tx.Create(&codes).Error)
+	})
+}
+
+func (r *gormRecoveryCodes) DeleteForUser(userID string) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
+
+func (r *gormRecoveryCodes) CountUnused(userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *gormRecoveryCodes) Use(userID, codeHash string, at time.Time) (bool, error) {
+	result := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// gormOAuthStates implements OAuthStates
+type gormOAuthStates struct {
+	db *gorm.DB
+}
+
+func (r *gormOAuthStates) Create(state *models.OAuthState) error {
+	return translateError(r.db.Create(state).Error)
+}
+
+func (r *gormOAuthStates) Take(id, provider string) (*models.OAuthState, error) {
+	var state models.OAuthState
+	result := r.db.Clauses(clause.Returning{}).
+		Where("id = ? AND provider = ?", id, provider).
+		Delete(&state)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrNotFound
+	}
+	return &state, nil
+}
+
+func (r *gormOAuthStates) DeleteExpired(now time.Time) error {
+	return r.db.Where("expires_at < ?", now).Delete(&models.OAuthState{}).Error
+}
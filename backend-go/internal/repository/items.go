@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"digital-wardrobe-backend/internal/models"
+)
+
+// ItemFilter selects a user's items. Empty fields match any item.
+type ItemFilter struct {
+	UserID   string
+	Archived bool // archived items instead of active ones
+	Category string
+	Status   string
+	MinPrice *float64
+	MaxPrice *float64
+	// Brands, Colors and Sizes match case-insensitively
+	Brands []string
+	Colors []string
+	Sizes  []string
+	OnSale *bool // priced below the original price
+	// Tags must all be present on the item
+	Tags []string
+	// CreatedFrom and CreatedBefore bound CreatedAt to [from, before)
+	CreatedFrom   *time.Time
+	CreatedBefore *time.Time
+}
+
+// ItemSort orders items by one of the ItemSortFields, then by ID in the same direction
+type ItemSort struct {
+	Field string
+	Desc  bool
+}
+
+// ItemCursor is the sort value and ID of the last item on the previous page
+type ItemCursor struct {
+	Value interface{}
+	ID    string
+}
+
+// ItemPageQuery selects one page of items. After continues a keyset page;
+// otherwise Offset skips rows.
+type ItemPageQuery struct {
+	Filter ItemFilter
+	Sort   ItemSort
+	After  *ItemCursor
+	Offset int
+	Limit  int
+}
+
+// ItemSearchQuery runs a ranked full-text search. Every term must match;
+// the last one also matches as a prefix.
+type ItemSearchQuery struct {
+	Filter ItemFilter
+	Terms  []string
+	Offset int
+	Limit  int
+}
+
+// HighlightStart and HighlightStop wrap matched words in ItemSearchHit text.
+// They are private-use characters, so callers can HTML-escape the item text
+// before swapping them for markup
+const (
+	HighlightStart = "\uE000"
+	HighlightStop  = "\uE001"
+)
+
+// ItemSearchHit is a ranked search result with highlighted text
+type ItemSearchHit struct {
+	Item          models.Item
+	Rank          float64
+	NameHighlight string
+	Snippet       string
+}
+
+// Items stores wardrobe items
+type Items interface {
+	Create(item *models.Item) error
+	// Get returns an item owned by the user
+	Get(userID, id string) (*models.Item, error)
+	GetForUpdate(userID, id string) (*models.Item, error)
+	// Save writes every column of an existing item
+	Save(item *models.Item) error
+	Update(id string, updates map[string]interface{}) error
+	// Delete removes an item along with its memberships, alerts and price history
+	Delete(id string) error
+	Count(filter ItemFilter) (int64, error)
+	List(query ItemPageQuery) ([]models.Item, error)
+	// ListAll returns every item of the user, archived ones included when asked
+	ListAll(userID string, includeArchived bool) ([]models.Item, error)
+	// Search returns a page of hits, best first, and the total number of matches
+	Search(query ItemSearchQuery) ([]ItemSearchHit, int64, error)
+}
+
+// PriceSnapshots stores observed item prices
+type PriceSnapshots interface {
+	Create(snapshot *models.PriceSnapshot) error
+	// Latest returns the most recently observed snapshot of an item in a currency
+	Latest(itemID, currency string) (*models.PriceSnapshot, error)
+	// Earliest returns the first observed snapshot of an item in a currency
+	Earliest(itemID, currency string) (*models.PriceSnapshot, error)
+	// ListSince returns snapshots observed at or after since, oldest first
+	ListSince(itemID, currency string, since time.Time) ([]models.PriceSnapshot, error)
+	// LatestForItems returns the most recent snapshot of each item in any currency
+	LatestForItems(itemIDs []string) ([]models.PriceSnapshot, error)
+}
+
+// itemSortField describes how a sort field maps to SQL and to Go values
+type itemSortField struct {
+	expr  string
+	value func(item *models.Item) interface{}
+}
+
+// itemSortFields maps each supported sort field to its definition
+var itemSortFields = map[string]itemSortField{
+	"dateAdded": {
+		expr:  "items.created_at",
+		value: func(i *models.Item) interface{} { return i.CreatedAt },
+	},
+	"dateUpdated": {
+		expr:  "items.updated_at",
+		value: func(i *models.Item) interface{} { return i.UpdatedAt },
+	},
+	"price": {
+		expr:  "COALESCE(items.price, 0)",
+		value: func(i *models.Item) interface{} { return floatOrZero(i.Price) },
+	},
+	"brand": {
+		expr:  "COALESCE(LOWER(items.brand), '')",
+		value: func(i *models.Item) interface{} { return strings.ToLower(stringOrEmpty(i.Brand)) },
+	},
+	"category": {
+		expr:  "items.category",
+		value: func(i *models.Item) interface{} { return i.Category },
+	},
+	"status": {
+		expr:  "items.status",
+		value: func(i *models.Item) interface{} { return i.Status },
+	},
+	"likes": {
+		expr:  "items.likes",
+		value: func(i *models.Item) interface{} { return i.Likes },
+	},
+	// popularity weighs likes above views
+	"popularity": {
+		expr:  "(items.likes * 2 + items.views)",
+		value: func(i *models.Item) interface{} { return i.Likes*2 + i.Views },
+	},
+}
+
+// ItemSortValue returns an item's value for a sort field, as a cursor carries
+// it: a time.Time, float64, string or int
+func ItemSortValue(field string, item *models.Item) interface{} {
+	return itemSortFields[field].value(item)
+}
+
+// floatOrZero dereferences f, returning 0 when nil
+func floatOrZero(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+	return *f
+}
+
+// stringOrEmpty dereferences s, returning "" when nil
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"digital-wardrobe-backend/internal/models"
+)
+
+// memoryAnalytics implements Analytics
+type memoryAnalytics struct {
+	s *MemoryStore
+}
+
+func (r *memoryAnalytics) Get(userID string) (*models.UserAnalytics, error) {
+	defer r.s.lock()()
+	return r.s.data.analytics.first(func(a *models.UserAnalytics) bool { return a.UserID == userID })
+}
+
+func (r *memoryAnalytics) Create(analytics *models.UserAnalytics) error {
+	defer r.s.lock()()
+	return r.s.data.analytics.insert(analytics)
+}
+
+func (r *memoryAnalytics) Save(analytics *models.UserAnalytics) error {
+	defer r.s.lock()()
+	return r.s.data.analytics.save(analytics)
+}
+
+func (r *memoryAnalytics) Delete(userID string) error {
+	defer r.s.lock()()
+	r.s.data.analytics.remove(func(a *models.UserAnalytics) bool { return a.UserID == userID })
+	return nil
+}
+
+func (r *memoryAnalytics) ItemsAdded(q TrendQuery) ([]TrendRow, error) {
+	return r.aggregate(q, false, func(i *models.Item) time.Time { return i.CreatedAt })
+}
+
+func (r *memoryAnalytics) Purchases(q TrendQuery) ([]TrendRow, error) {
+	return r.aggregate(q, true, func(i *models.Item) time.Time {
+		if i.PurchaseDate != nil {
+			return *i.PurchaseDate
+		}
+		return i.CreatedAt
+	})
+}
+
+// aggregate groups the user's active items by the period of at and the
+// trend group, and by currency when purchases is set
+func (r *memoryAnalytics) aggregate(q TrendQuery, purchases bool, at func(i *models.Item) time.Time) ([]TrendRow, error) {
+	groupKey, ok := trendGroupKeys[q.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported trend grouping %q", q.GroupBy)
+	}
+
+	defer r.s.lock()()
+	items := r.s.data.items.find(func(i *models.Item) bool {
+		t := at(i)
+		return i.UserID == q.UserID && i.ArchivedAt == nil &&
+			!t.Before(q.Start) && t.Before(q.End) &&
+			(!purchases || contains(q.Statuses, i.Status))
+	})
+
+	type key struct {
+		period   time.Time
+		group    string
+		currency string
+	}
+	rows := map[key]*TrendRow{}
+	for i := range items {
+		item := &items[i]
+		k := key{period: truncatePeriod(at(item), q.Interval), group: groupKey(item)}
+		if purchases {
+			k.currency = item.Currency
+		}
+		row, ok := rows[k]
+		if !ok {
+			row = &TrendRow{Period: k.period, GroupKey: k.group, Currency: k.currency}
+			rows[k] = row
+		}
+		row.Items++
+		if purchases {
+			row.Spend += floatOrZero(item.Price)
+		}
+	}
+
+	out := make([]TrendRow, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, *row)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if !a.Period.Equal(b.Period) {
+			return a.Period.Before(b.Period)
+		}
+		if a.GroupKey != b.GroupKey {
+			return a.GroupKey < b.GroupKey
+		}
+		return a.Currency < b.Currency
+	})
+	return out, nil
+}
+
+// trendGroupKeys mirrors trendGroupExprs in Go
+var trendGroupKeys = map[string]func(i *models.Item) string{
+	TrendGroupNone:     func(i *models.Item) string { return TrendAllKey },
+	TrendGroupCategory: func(i *models.Item) string { return i.Category },
+	TrendGroupBrand: func(i *models.Item) string {
+		if brand := strings.ToLower(strings.TrimSpace(stringOrEmpty(i.Brand))); brand != "" {
+			return brand
+		}
+		return TrendUnknownBrand
+	},
+}
+
+// truncatePeriod truncates t in UTC to the start of its day, week (from
+// Monday) or month, as date_trunc does
+func truncatePeriod(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case "week":
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
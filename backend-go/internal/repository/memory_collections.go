@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"sort"
+
+	"digital-wardrobe-backend/internal/models"
+)
+
+// memoryCollections implements Collections
+type memoryCollections struct {
+	s *MemoryStore
+}
+
+func (r *memoryCollections) List(userID string) ([]models.Collection, error) {
+	defer r.s.lock()()
+	collections := r.s.data.collections.find(func(c *models.Collection) bool { return c.UserID == userID })
+	sort.Slice(collections, func(i, j int) bool { return collections[i].CreatedAt.After(collections[j].CreatedAt) })
+	return collections, nil
+}
+
+func (r *memoryCollections) Get(userID, id string) (*models.Collection, error) {
+	defer r.s.lock()()
+	return r.get(userID, id)
+}
+
+func (r *memoryCollections) GetForUpdate(userID, id string) (*models.Collection, error) {
+	return r.Get(userID, id)
+}
+
+func (r *memoryCollections) GetWithItems(userID, id string) (*models.Collection, error) {
+	defer r.s.lock()()
+	collection, err := r.get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships := r.s.data.memberships.find(func(m *models.CollectionItem) bool { return m.CollectionID == id })
+	sort.Slice(memberships, func(i, j int) bool {
+		if memberships[i].Order != memberships[j].Order {
+			return memberships[i].Order < memberships[j].Order
+		}
+		return memberships[i].CreatedAt.Before(memberships[j].CreatedAt)
+	})
+	for i := range memberships {
+		if item, ok := r.s.data.items.get(memberships[i].ItemID); ok {
+			memberships[i].Item = *item
+		}
+	}
+
+	collection.Items = memberships
+	return collection, nil
+}
+
+func (r *memoryCollections) Create(collection *models.Collection) error {
+	defer r.s.lock()()
+	return r.s.data.collections.insert(collection)
+}
+
+func (r *memoryCollections) Save(collection *models.Collection) error {
+	defer r.s.lock()()
+	return r.s.data.collections.save(collection)
+}
+
+func (r *memoryCollections) Delete(id string) error {
+	defer r.s.lock()()
+	if r.s.data.collections.remove(func(c *models.Collection) bool { return c.ID == id }) == 0 {
+		return ErrNotFound
+	}
+	r.s.data.memberships.remove(func(m *models.CollectionItem) bool { return m.CollectionID == id })
+	return nil
+}
+
+func (r *memoryCollections) GetMembership(collectionID, itemID string) (*models.CollectionItem, error) {
+	defer r.s.lock()()
+	return r.s.data.memberships.first(func(m *models.CollectionItem) bool {
+		return m.CollectionID == collectionID && m.ItemID == itemID
+	})
+}
+
+func (r *memoryCollections) ListMemberships(collectionID string) ([]models.CollectionItem, error) {
+	defer r.s.lock()()
+	return r.s.data.memberships.find(func(m *models.CollectionItem) bool { return m.CollectionID == collectionID }), nil
+}
+
+func (r *memoryCollections) CountMemberships(collectionID string) (int64, error) {
+	memberships, err := r.ListMemberships(collectionID)
+	return int64(len(memberships)), err
+}
+
+func (r *memoryCollections) AddMembership(membership *models.CollectionItem) error {
+	defer r.s.lock()()
+	return r.s.data.memberships.insert(membership)
+}
+
+func (r *memoryCollections) UpdateMembership(id string, updates map[string]interface{}) error {
+	defer r.s.lock()()
+	_, err := r.s.data.memberships.update(id, updates)
+	return err
+}
+
+func (r *memoryCollections) DeleteMembership(id string) error {
+	defer r.s.lock()()
+	if r.s.data.memberships.remove(func(m *models.CollectionItem) bool { return m.ID == id }) == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *memoryCollections) ShiftOrder(collectionID string, from, delta int) error {
+	defer r.s.lock()()
+	shifted := r.s.data.memberships.find(func(m *models.CollectionItem) bool {
+		return m.CollectionID == collectionID && m.Order >= from
+	})
+	for _, m := range shifted {
+		if _, err := r.s.data.memberships.update(m.ID, map[string]interface{}{"order": m.Order + delta}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// get returns a collection owned by the user; the caller holds the lock
+func (r *memoryCollections) get(userID, id string) (*models.Collection, error) {
+	if collection, ok := r.s.data.collections.get(id); ok && collection.UserID == userID {
+		return collection, nil
+	}
+	return nil, ErrNotFound
+}
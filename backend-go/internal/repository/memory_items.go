@@ -0,0 +1,369 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"digital-wardrobe-backend/internal/models"
+)
+
+// memoryItems implements Items
+type memoryItems struct {
+	s *MemoryStore
+}
+
+func (r *memoryItems) Create(item *models.Item) error {
+	defer r.s.lock()()
+	return r.s.data.items.insert(item)
+}
+
+func (r *memoryItems) Get(userID, id string) (*models.Item, error) {
+	defer r.s.lock()()
+	if item, ok := r.s.data.items.get(id); ok && item.UserID == userID {
+		return item, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryItems) GetForUpdate(userID, id string) (*models.Item, error) {
+	return r.Get(userID, id)
+}
+
+func (r *memoryItems) Save(item *models.Item) error {
+	defer r.s.lock()()
+	return r.s.data.items.save(item)
+}
+
+func (r *memoryItems) Update(id string, updates map[string]interface{}) error {
+	defer r.s.lock()()
+	_, err := r.s.data.items.update(id, updates)
+	return err
+}
+
+func (r *memoryItems) Delete(id string) error {
+	defer r.s.lock()()
+	data := r.s.data
+	if data.items.remove(func(i *models.Item) bool { return i.ID == id }) == 0 {
+		return ErrNotFound
+	}
+	data.memberships.remove(func(m *models.CollectionItem) bool { return m.ItemID == id })
+	data.alerts.remove(func(a *models.PriceAlert) bool { return a.ItemID != nil && *a.ItemID == id })
+	data.snapshots.remove(func(s *models.PriceSnapshot) bool { return s.ItemID == id })
+	return nil
+}
+
+func (r *memoryItems) Count(filter ItemFilter) (int64, error) {
+	defer r.s.lock()()
+	return int64(len(r.filtered(filter))), nil
+}
+
+func (r *memoryItems) List(q ItemPageQuery) ([]models.Item, error) {
+	spec, ok := itemSortFields[q.Sort.Field]
+	if !ok {
+		return nil, fmt.Errorf("unsupported item sort field %q", q.Sort.Field)
+	}
+
+	defer r.s.lock()()
+	items := r.filtered(q.Filter)
+
+	// before reports whether a sorts ahead of b in the requested direction
+	before := func(aValue interface{}, aID string, bValue interface{}, bID string) bool {
+		c := compareSortValues(aValue, bValue)
+		if c == 0 {
+			c = strings.Compare(aID, bID)
+		}
+		if q.Sort.Desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return before(spec.value(&items[i]), items[i].ID, spec.value(&items[j]), items[j].ID)
+	})
+
+	if q.After != nil {
+		start := len(items)
+		for i := range items {
+			if before(q.After.Value, q.After.ID, spec.value(&items[i]), items[i].ID) {
+				start = i
+				break
+			}
+		}
+		items = items[start:]
+	} else {
+		items = items[min(q.Offset, len(items)):]
+	}
+
+	return items[:min(q.Limit, len(items))], nil
+}
+
+func (r *memoryItems) ListAll(userID string, includeArchived bool) ([]models.Item, error) {
+	defer r.s.lock()()
+	return r.s.data.items.find(func(i *models.Item) bool {
+		return i.UserID == userID && (includeArchived || i.ArchivedAt == nil)
+	}), nil
+}
+
+// memorySearchFields are the searchable item fields with the weights the
+// search vector gives them
+var memorySearchFields = []struct {
+	weight float64
+	text   func(i *models.Item) string
+}{
+	{1, func(i *models.Item) string { return i.Name }},
+	{0.4, func(i *models.Item) string { return stringOrEmpty(i.Brand) + " " + strings.Join(i.Tags, " ") }},
+	{0.2, func(i *models.Item) string { return stringOrEmpty(i.Color) + " " + stringOrEmpty(i.Material) }},
+	{0.1, func(i *models.Item) string { return stringOrEmpty(i.Description) + " " + stringOrEmpty(i.Notes) }},
+}
+
+// Search approximates the full-text search: terms match whole words, the
+// last one as a prefix, without stemming
+func (r *memoryItems) Search(q ItemSearchQuery) ([]ItemSearchHit, int64, error) {
+	if len(q.Terms) == 0 {
+		return []ItemSearchHit{}, 0, nil
+	}
+	terms := lowerAll(q.Terms)
+
+	defer r.s.lock()()
+	hits := []ItemSearchHit{}
+	for _, item := range r.filtered(q.Filter) {
+		rank := 0.0
+		for _, term := range terms {
+			matched := false
+			for _, field := range memorySearchFields {
+				if n := countTermMatches(field.text(&item), term, term == terms[len(terms)-1]); n > 0 {
+					rank += field.weight * float64(n)
+					matched = true
+				}
+			}
+			if !matched {
+				rank = 0
+				break
+			}
+		}
+		if rank == 0 {
+			continue
+		}
+
+		snippet := strings.Join(nonEmpty(
+			stringOrEmpty(item.Description),
+			stringOrEmpty(item.Notes),
+			stringOrEmpty(item.Material),
+			stringOrEmpty(item.Color),
+		), " ")
+		hits = append(hits, ItemSearchHit{
+			Item:          item,
+			Rank:          rank,
+			NameHighlight: highlightTerms(item.Name, terms),
+			Snippet:       highlightTerms(snippet, terms),
+		})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].Item.CreatedAt.After(hits[j].Item.CreatedAt)
+	})
+
+	total := int64(len(hits))
+	hits = hits[min(q.Offset, len(hits)):]
+	return hits[:min(q.Limit, len(hits))], total, nil
+}
+
+// filtered returns copies of the items matching filter
+func (r *memoryItems) filtered(filter ItemFilter) []models.Item {
+	brands, colors, sizes := lowerAll(filter.Brands), lowerAll(filter.Colors), lowerAll(filter.Sizes)
+
+	return r.s.data.items.find(func(i *models.Item) bool {
+		switch {
+		case i.UserID != filter.UserID,
+			filter.Archived != (i.ArchivedAt != nil),
+			filter.Category != "" && i.Category != filter.Category,
+			filter.Status != "" && i.Status != filter.Status,
+			filter.MinPrice != nil && (i.Price == nil || *i.Price < *filter.MinPrice),
+			filter.MaxPrice != nil && (i.Price == nil || *i.Price > *filter.MaxPrice),
+			len(brands) > 0 && (i.Brand == nil || !contains(brands, strings.ToLower(*i.Brand))),
+			len(colors) > 0 && (i.Color == nil || !contains(colors, strings.ToLower(*i.Color))),
+			len(sizes) > 0 && (i.Size == nil || !contains(sizes, strings.ToLower(*i.Size))),
+			filter.CreatedFrom != nil && i.CreatedAt.Before(*filter.CreatedFrom),
+			filter.CreatedBefore != nil && !i.CreatedAt.Before(*filter.CreatedBefore):
+			return false
+		}
+
+		if filter.OnSale != nil {
+			onSale := i.Price != nil && i.OriginalPrice != nil && *i.Price < *i.OriginalPrice
+			if onSale != *filter.OnSale {
+				return false
+			}
+		}
+		for _, tag := range filter.Tags {
+			if !contains(i.Tags, tag) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// compareSortValues compares two values of the same sort field
+func compareSortValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case float64:
+		return compareOrdered(a, b.(float64))
+	case int:
+		return compareOrdered(a, b.(int))
+	case string:
+		return strings.Compare(a, b.(string))
+	}
+	panic(fmt.Sprintf("repository: cannot compare sort values of type %T", a))
+}
+
+func compareOrdered[T int | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// searchWords splits text into lower-cased words of letters and digits
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// countTermMatches counts the words of text equal to term, or starting with
+// it when prefix is set
+func countTermMatches(text, term string, prefix bool) int {
+	n := 0
+	for _, word := range searchWords(text) {
+		if word == term || (prefix && strings.HasPrefix(word, term)) {
+			n++
+		}
+	}
+	return n
+}
+
+// highlightTerms wraps the words of text starting with any of terms in
+// HighlightStart and HighlightStop
+func highlightTerms(text string, terms []string) string {
+	var out strings.Builder
+	word := []rune{}
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		w, lower := string(word), strings.ToLower(string(word))
+		marked := false
+		for _, term := range terms {
+			if strings.HasPrefix(lower, term) {
+				marked = true
+				break
+			}
+		}
+		if marked {
+			out.WriteString(HighlightStart + w + HighlightStop)
+		} else {
+			out.WriteString(w)
+		}
+		word = word[:0]
+	}
+
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word = append(word, r)
+			continue
+		}
+		flush()
+		out.WriteRune(r)
+	}
+	flush()
+	return out.String()
+}
+
+// nonEmpty drops empty strings
+func nonEmpty(values ...string) []string {
+	out := []string{}
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// contains reports whether values holds v
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// memoryPriceSnapshots implements PriceSnapshots
+type memoryPriceSnapshots struct {
+	s *MemoryStore
+}
+
+func (r *memoryPriceSnapshots) Create(snapshot *models.PriceSnapshot) error {
+	defer r.s.lock()()
+	return r.s.data.snapshots.insert(snapshot)
+}
+
+func (r *memoryPriceSnapshots) Latest(itemID, currency string) (*models.PriceSnapshot, error) {
+	snapshots := r.list(itemID, currency, time.Time{})
+	if len(snapshots) == 0 {
+		return nil, ErrNotFound
+	}
+	return &snapshots[len(snapshots)-1], nil
+}
+
+func (r *memoryPriceSnapshots) Earliest(itemID, currency string) (*models.PriceSnapshot, error) {
+	snapshots := r.list(itemID, currency, time.Time{})
+	if len(snapshots) == 0 {
+		return nil, ErrNotFound
+	}
+	return &snapshots[0], nil
+}
+
+func (r *memoryPriceSnapshots) ListSince(itemID, currency string, since time.Time) ([]models.PriceSnapshot, error) {
+	return r.list(itemID, currency, since), nil
+}
+
+func (r *memoryPriceSnapshots) LatestForItems(itemIDs []string) ([]models.PriceSnapshot, error) {
+	defer r.s.lock()()
+	latest := map[string]models.PriceSnapshot{}
+	for _, snapshot := range r.s.data.snapshots.find(func(s *models.PriceSnapshot) bool { return contains(itemIDs, s.ItemID) }) {
+		if current, ok := latest[snapshot.ItemID]; !ok || snapshot.ObservedAt.After(current.ObservedAt) {
+			latest[snapshot.ItemID] = snapshot
+		}
+	}
+
+	snapshots := []models.PriceSnapshot{}
+	for _, snapshot := range latest {
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].ItemID < snapshots[j].ItemID })
+	return snapshots, nil
+}
+
+// list returns an item's snapshots in a currency observed at or after since, oldest first
+func (r *memoryPriceSnapshots) list(itemID, currency string, since time.Time) []models.PriceSnapshot {
+	defer r.s.lock()()
+	snapshots := r.s.data.snapshots.find(func(s *models.PriceSnapshot) bool {
+		return s.ItemID == itemID && s.Currency == currency && !s.ObservedAt.Before(since)
+	})
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].ObservedAt.Before(snapshots[j].ObservedAt) })
+	return snapshots
+}
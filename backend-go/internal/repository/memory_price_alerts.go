@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"digital-wardrobe-backend/internal/models"
+)
+
+// memoryPriceAlerts implements PriceAlerts
+type memoryPriceAlerts struct {
+	s *MemoryStore
+}
+
+func (r *memoryPriceAlerts) List(filter PriceAlertFilter) ([]models.PriceAlert, error) {
+	defer r.s.lock()()
+	alerts := r.s.data.alerts.find(func(a *models.PriceAlert) bool {
+		return a.UserID == filter.UserID &&
+			(filter.Active == nil || a.IsActive == *filter.Active) &&
+			(filter.Triggered == nil || a.IsTriggered == *filter.Triggered) &&
+			(filter.ItemID == "" || (a.ItemID != nil && *a.ItemID == filter.ItemID))
+	})
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].CreatedAt.After(alerts[j].CreatedAt) })
+	return alerts, nil
+}
+
+func (r *memoryPriceAlerts) Get(userID, id string) (*models.PriceAlert, error) {
+	defer r.s.lock()()
+	if alert, ok := r.s.data.alerts.get(id); ok && alert.UserID == userID {
+		return alert, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryPriceAlerts) Create(alert *models.PriceAlert) error {
+	defer r.s.lock()()
+	return r.s.data.alerts.insert(alert)
+}
+
+func (r *memoryPriceAlerts) Save(alert *models.PriceAlert) error {
+	defer r.s.lock()()
+	return r.s.data.alerts.save(alert)
+}
+
+func (r *memoryPriceAlerts) Update(id string, updates map[string]interface{}) error {
+	defer r.s.lock()()
+	_, err := r.s.data.alerts.update(id, updates)
+	return err
+}
+
+func (r *memoryPriceAlerts) Delete(userID, id string) error {
+	defer r.s.lock()()
+	if r.s.data.alerts.remove(func(a *models.PriceAlert) bool { return a.ID == id && a.UserID == userID }) == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *memoryPriceAlerts) CountWatching(userID string) (int64, error) {
+	defer r.s.lock()()
+	alerts := r.s.data.alerts.find(func(a *models.PriceAlert) bool {
+		return a.UserID == userID && a.IsActive && !a.IsTriggered
+	})
+	return int64(len(alerts)), nil
+}
+
+func (r *memoryPriceAlerts) Due(checkedBefore time.Time, limit int) ([]models.PriceAlert, error) {
+	defer r.s.lock()()
+	alerts := r.s.data.alerts.find(func(a *models.PriceAlert) bool {
+		return a.IsActive && !a.IsTriggered && (a.LastCheckedAt == nil || a.LastCheckedAt.Before(checkedBefore))
+	})
+	sort.Slice(alerts, func(i, j int) bool {
+		a, b := alerts[i].LastCheckedAt, alerts[j].LastCheckedAt
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		return a.Before(*b)
+	})
+	return alerts[:min(limit, len(alerts))], nil
+}
+
+func (r *memoryPriceAlerts) Trigger(id string, updates map[string]interface{}) (bool, error) {
+	defer r.s.lock()()
+	alert, ok := r.s.data.alerts.get(id)
+	if !ok || alert.IsTriggered {
+		return false, nil
+	}
+	if _, err := r.s.data.alerts.update(id, updates); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,377 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"digital-wardrobe-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// MemoryStore is a Store that keeps everything in memory, for tests. It
+// follows the GORM store's contract: column defaults, timestamps, unique
+// constraints, cascading deletes and JSON columns behave as in Postgres.
+// Transactions run one at a time and roll back by restoring a copy of the
+// data taken when they began.
+type MemoryStore struct {
+	mu   *sync.Mutex
+	data *memoryData
+	inTx bool
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// memoryData holds one table per model
+type memoryData struct {
+	users         *memoryTable[models.User]
+	sessions      *memoryTable[models.Session]
+	auditLogs     *memoryTable[models.AuditLog]
+	recoveryCodes *memoryTable[models.RecoveryCode]
+	oauthStates   *memoryTable[models.OAuthState]
+	items         *memoryTable[models.Item]
+	snapshots     *memoryTable[models.PriceSnapshot]
+	collections   *memoryTable[models.Collection]
+	memberships   *memoryTable[models.CollectionItem]
+	alerts        *memoryTable[models.PriceAlert]
+	analytics     *memoryTable[models.UserAnalytics]
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		mu: &sync.Mutex{},
+		data: &memoryData{
+			users:         newMemoryTable[models.User]([]string{"email"}, []string{"username"}, []string{"google_id"}, []string{"facebook_id"}, []string{"apple_id"}),
+			sessions:      newMemoryTable[models.Session]([]string{"token"}, []string{"refresh_token"}),
+			auditLogs:     newMemoryTable[models.AuditLog](),
+			recoveryCodes: newMemoryTable[models.RecoveryCode](),
+			oauthStates:   newMemoryTable[models.OAuthState](),
+			items:         newMemoryTable[models.Item](),
+			snapshots:     newMemoryTable[models.PriceSnapshot](),
+			collections:   newMemoryTable[models.Collection](),
+			memberships:   newMemoryTable[models.CollectionItem]([]string{"collection_id", "item_id"}),
+			alerts:        newMemoryTable[models.PriceAlert](),
+			analytics:     newMemoryTable[models.UserAnalytics]([]string{"user_id"}),
+		},
+	}
+}
+
+func (s *MemoryStore) Users() Users                   { return &memoryUsers{s} }
+func (s *MemoryStore) Sessions() Sessions             { return &memorySessions{s} }
+func (s *MemoryStore) AuditLogs() AuditLogs           { return &memoryAuditLogs{s} }
+func (s *MemoryStore) RecoveryCodes() RecoveryCodes   { return &memoryRecoveryCodes{s} }
+func (s *MemoryStore) OAuthStates() OAuthStates       { return &memoryOAuthStates{s} }
+func (s *MemoryStore) Items() Items                   { return &memoryItems{s} }
+func (s *MemoryStore) PriceSnapshots() PriceSnapshots { return &memoryPriceSnapshots{s} }
+func (s *MemoryStore) Collections() Collections       { return &memoryCollections{s} }
+func (s *MemoryStore) PriceAlerts() PriceAlerts       { return &memoryPriceAlerts{s} }
+func (s *MemoryStore) Analytics() Analytics           { return &memoryAnalytics{s} }
+
+// WithContext returns the store itself; in-memory operations don't block on I/O
+func (s *MemoryStore) WithContext(ctx context.Context) Store {
+	return s
+}
+
+// Transaction runs fn with the store locked. Nested calls join the outer
+// transaction rather than using savepoints.
+func (s *MemoryStore) Transaction(fn func(tx Store) error) (err error) {
+	if s.inTx {
+		return fn(s)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := s.data.clone()
+	defer func() {
+		if r := recover(); r != nil {
+			*s.data = *saved
+			panic(r)
+		}
+		if err != nil {
+			*s.data = *saved
+		}
+	}()
+
+	return fn(&MemoryStore{mu: s.mu, data: s.data, inTx: true})
+}
+
+// lock takes the store lock for one operation and returns its release.
+// Inside a transaction the lock is already held.
+func (s *MemoryStore) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// clone copies every table. Rows are never modified in place, so copying
+// the maps is enough.
+func (d *memoryData) clone() *memoryData {
+	return &memoryData{
+		users:         d.users.clone(),
+		sessions:      d.sessions.clone(),
+		auditLogs:     d.auditLogs.clone(),
+		recoveryCodes: d.recoveryCodes.clone(),
+		oauthStates:   d.oauthStates.clone(),
+		items:         d.items.clone(),
+		snapshots:     d.snapshots.clone(),
+		collections:   d.collections.clone(),
+		memberships:   d.memberships.clone(),
+		alerts:        d.alerts.clone(),
+		analytics:     d.analytics.clone(),
+	}
+}
+
+// schemaCache is shared by every memory table
+var schemaCache = &sync.Map{}
+
+// memoryTable holds the rows of one model by ID. It reads the model's GORM
+// schema to map column names to fields and to apply defaults.
+type memoryTable[T any] struct {
+	schema *schema.Schema
+	unique [][]string
+	rows   map[string]T
+}
+
+// newMemoryTable creates a table with the given unique column sets
+func newMemoryTable[T any](unique ...[]string) *memoryTable[T] {
+	sch, err := schema.Parse(new(T), schemaCache, schema.NamingStrategy{})
+	if err != nil {
+		panic(fmt.Sprintf("repository: failed to parse schema of %T: %v", *new(T), err))
+	}
+	return &memoryTable[T]{schema: sch, unique: unique, rows: map[string]T{}}
+}
+
+func (t *memoryTable[T]) clone() *memoryTable[T] {
+	rows := make(map[string]T, len(t.rows))
+	for id, row := range t.rows {
+		rows[id] = row
+	}
+	return &memoryTable[T]{schema: t.schema, unique: t.unique, rows: rows}
+}
+
+// insert stores a new row. Like GORM it runs BeforeCreate, fills zero values
+// with column defaults and sets the timestamps on row itself.
+func (t *memoryTable[T]) insert(row *T) error {
+	if hook, ok := interface{}(row).(interface{ BeforeCreate(*gorm.DB) error }); ok {
+		if err := hook.BeforeCreate(nil); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	rv := reflect.ValueOf(row).Elem()
+	now := time.Now()
+	for _, field := range t.schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		_, zero := field.ValueOf(ctx, rv)
+		switch {
+		case !zero:
+		case field.AutoCreateTime != 0 || field.AutoUpdateTime != 0:
+			if err := field.Set(ctx, rv, now); err != nil {
+				return err
+			}
+		case field.DefaultValueInterface != nil:
+			if err := field.Set(ctx, rv, field.DefaultValueInterface); err != nil {
+				return err
+			}
+		}
+	}
+
+	id := t.id(row)
+	if _, exists := t.rows[id]; exists {
+		return fmt.Errorf("%w: %s %s already exists", ErrDuplicate, t.schema.Table, id)
+	}
+	return t.put(id, row)
+}
+
+// save replaces an existing row, or inserts a new one
+func (t *memoryTable[T]) save(row *T) error {
+	id := t.id(row)
+	if _, exists := t.rows[id]; !exists {
+		return t.insert(row)
+	}
+	t.touch(row)
+	return t.put(id, row)
+}
+
+// update applies column updates to a row and returns the result
+func (t *memoryTable[T]) update(id string, updates map[string]interface{}) (*T, error) {
+	row, ok := t.rows[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	ctx := context.Background()
+	rv := reflect.ValueOf(&row).Elem()
+	for column, value := range updates {
+		field := t.schema.LookUpField(column)
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("%s has no column %q", t.schema.Table, column)
+		}
+		if err := field.Set(ctx, rv, value); err != nil {
+			return nil, fmt.Errorf("failed to set %s.%s: %w", t.schema.Table, column, err)
+		}
+	}
+	t.touch(&row)
+
+	if err := t.put(id, &row); err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// get returns a copy of the row with the given ID
+func (t *memoryTable[T]) get(id string) (*T, bool) {
+	row, ok := t.rows[id]
+	if !ok {
+		return nil, false
+	}
+	copied := t.copy(row)
+	return &copied, true
+}
+
+// find returns copies of the rows matching match, in no particular order
+func (t *memoryTable[T]) find(match func(row *T) bool) []T {
+	found := []T{}
+	for _, row := range t.rows {
+		if match(&row) {
+			found = append(found, t.copy(row))
+		}
+	}
+	return found
+}
+
+// first returns a copy of one row matching match
+func (t *memoryTable[T]) first(match func(row *T) bool) (*T, error) {
+	for _, row := range t.rows {
+		if match(&row) {
+			copied := t.copy(row)
+			return &copied, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// remove deletes the rows matching match and returns how many there were
+func (t *memoryTable[T]) remove(match func(row *T) bool) int64 {
+	var n int64
+	for id, row := range t.rows {
+		if match(&row) {
+			delete(t.rows, id)
+			n++
+		}
+	}
+	return n
+}
+
+// put checks the unique constraints and stores a copy of row
+func (t *memoryTable[T]) put(id string, row *T) error {
+	ctx := context.Background()
+	rv := reflect.ValueOf(row).Elem()
+	for _, columns := range t.unique {
+		key, ok := t.uniqueKey(ctx, rv, columns)
+		if !ok {
+			continue
+		}
+		for otherID, other := range t.rows {
+			if otherID == id {
+				continue
+			}
+			if otherKey, ok := t.uniqueKey(ctx, reflect.ValueOf(&other).Elem(), columns); ok && otherKey == key {
+				return fmt.Errorf("%w: %s %v is taken", ErrDuplicate, t.schema.Table, columns)
+			}
+		}
+	}
+
+	t.rows[id] = t.copy(*row)
+	return nil
+}
+
+// uniqueKey returns the values of a unique column set. Like SQL, rows with a
+// NULL in the set never conflict.
+func (t *memoryTable[T]) uniqueKey(ctx context.Context, rv reflect.Value, columns []string) (string, bool) {
+	key := ""
+	for _, column := range columns {
+		value, _ := t.schema.LookUpField(column).ValueOf(ctx, rv)
+		v := reflect.ValueOf(value)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return "", false
+			}
+			value = v.Elem().Interface()
+		}
+		key += fmt.Sprintf("%v\x00", value)
+	}
+	return key, true
+}
+
+// touch sets the auto-update timestamps of row
+func (t *memoryTable[T]) touch(row *T) {
+	ctx := context.Background()
+	rv := reflect.ValueOf(row).Elem()
+	now := time.Now()
+	for _, field := range t.schema.Fields {
+		if field.AutoUpdateTime != 0 {
+			_ = field.Set(ctx, rv, now)
+		}
+	}
+}
+
+// id returns the primary key of row
+func (t *memoryTable[T]) id(row *T) string {
+	value, _ := t.schema.PrioritizedPrimaryField.ValueOf(context.Background(), reflect.ValueOf(row).Elem())
+	return fmt.Sprint(value)
+}
+
+// copy returns row as a database round trip would: relationships are left
+// unloaded and JSON columns are re-decoded, so no maps or slices are shared
+func (t *memoryTable[T]) copy(row T) T {
+	rv := reflect.ValueOf(&row).Elem()
+
+	for _, rel := range t.schema.Relationships.Relations {
+		// GORM also registers the back-references of other models here
+		if rel.Field.Schema != t.schema {
+			continue
+		}
+		field := rv.FieldByIndex(rel.Field.StructField.Index)
+		field.Set(reflect.Zero(field.Type()))
+	}
+
+	for _, field := range t.schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		fv := rv.FieldByIndex(field.StructField.Index)
+		valuer, ok := fv.Interface().(driver.Valuer)
+		if !ok || fv.Kind() == reflect.Ptr {
+			continue
+		}
+		scanned := reflect.New(fv.Type())
+		scanner, ok := scanned.Interface().(sql.Scanner)
+		if !ok {
+			continue
+		}
+		value, err := valuer.Value()
+		if err != nil {
+			panic(fmt.Sprintf("repository: failed to encode %s.%s: %v", t.schema.Table, field.DBName, err))
+		}
+		if err := scanner.Scan(value); err != nil {
+			panic(fmt.Sprintf("repository: failed to decode %s.%s: %v", t.schema.Table, field.DBName, err))
+		}
+		fv.Set(scanned.Elem())
+	}
+
+	return row
+}
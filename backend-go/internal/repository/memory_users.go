@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"digital-wardrobe-backend/internal/models"
+)
+
+// memoryUsers implements Users
+type memoryUsers struct {
+	s *MemoryStore
+}
+
+func (r *memoryUsers) Create(user *models.User) error {
+	defer r.s.lock()()
+	return r.s.data.users.insert(user)
+}
+
+func (r *memoryUsers) Get(id string) (*models.User, error) {
+	defer r.s.lock()()
+	if user, ok := r.s.data.users.get(id); ok {
+		return user, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryUsers) GetForUpdate(id string) (*models.User, error) {
+	return r.Get(id)
+}
+
+func (r *memoryUsers) FindByEmail(email string) (*models.User, error) {
+	defer r.s.lock()()
+	return r.s.data.users.first(func(u *models.User) bool { return u.Email == email })
+}
+
+func (r *memoryUsers) FindByEmailFold(email string) (*models.User, error) {
+	defer r.s.lock()()
+	return r.s.data.users.first(func(u *models.User) bool { return strings.ToLower(u.Email) == email })
+}
+
+func (r *memoryUsers) FindByOAuthSubject(column, subject string) (*models.User, error) {
+	if !oauthSubjectColumns[column] {
+		return nil, fmt.Errorf("unknown oauth subject column %q", column)
+	}
+
+	defer r.s.lock()()
+	return r.s.data.users.first(func(u *models.User) bool {
+		var id *string
+		switch column {
+		case "google_id":
+			id = u.GoogleID
+		case "facebook_id":
+			id = u.FacebookID
+		case "apple_id":
+			id = u.AppleID
+		}
+		return id != nil && *id == subject
+	})
+}
+
+func (r *memoryUsers) GetByTokenForUpdate(token UserToken, tokenHash string, now time.Time) (*models.User, error) {
+	if _, ok := userTokenColumns[token]; !ok {
+		return nil, fmt.Errorf("unknown user token %d", token)
+	}
+
+	defer r.s.lock()()
+	return r.s.data.users.first(func(u *models.User) bool {
+		var hash *string
+		var expires *time.Time
+		switch token {
+		case EmailVerificationToken:
+			hash, expires = u.EmailVerificationToken, u.EmailVerificationExpires
+		case PasswordResetToken:
+			hash, expires = u.PasswordResetToken, u.PasswordResetExpires
+		case UnlockToken:
+			hash, expires = u.UnlockToken, u.LockedUntil
+		}
+		return hash != nil && *hash == tokenHash && expires != nil && expires.After(now)
+	})
+}
+
+func (r *memoryUsers) Update(id string, updates map[string]interface{}) error {
+	defer r.s.lock()()
+	_, err := r.s.data.users.update(id, updates)
+	return err
+}
+
+// memorySessions implements Sessions
+type memorySessions struct {
+	s *MemoryStore
+}
+
+func (r *memorySessions) Create(session *models.Session) error {
+	defer r.s.lock()()
+	return r.s.data.sessions.insert(session)
+}
+
+func (r *memorySessions) GetForUpdate(id string) (*models.Session, error) {
+	defer r.s.lock()()
+	if session, ok := r.s.data.sessions.get(id); ok {
+		return session, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memorySessions) GetActive(id string) (*models.Session, error) {
+	defer r.s.lock()()
+	return r.s.data.sessions.first(func(s *models.Session) bool { return s.ID == id && s.IsActive })
+}
+
+func (r *memorySessions) GetActiveByToken(token string) (*models.Session, error) {
+	defer r.s.lock()()
+	return r.s.data.sessions.first(func(s *models.Session) bool { return s.Token == token && s.IsActive })
+}
+
+func (r *memorySessions) ListActive(userID string, now time.Time) ([]models.Session, error) {
+	defer r.s.lock()()
+	sessions := r.s.data.sessions.find(func(s *models.Session) bool {
+		return s.UserID == userID && s.IsActive && s.ExpiresAt.After(now)
+	})
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt) })
+	return sessions, nil
+}
+
+func (r *memorySessions) Update(id string, updates map[string]interface{}) error {
+	defer r.s.lock()()
+	_, err := r.s.data.sessions.update(id, updates)
+	return err
+}
+
+func (r *memorySessions) Revoke(filter SessionFilter) (int64, error) {
+	if filter.ID == "" && filter.UserID == "" && filter.Token == "" {
+		return 0, errors.New("revoking sessions needs an ID, user or token")
+	}
+
+	defer r.s.lock()()
+	matches := r.s.data.sessions.find(func(s *models.Session) bool {
+		return s.IsActive &&
+			(filter.ID == "" || s.ID == filter.ID) &&
+			(filter.UserID == "" || s.UserID == filter.UserID) &&
+			(filter.Token == "" || s.Token == filter.Token) &&
+			(filter.ExceptID == "" || s.ID != filter.ExceptID)
+	})
+	for _, session := range matches {
+		if _, err := r.s.data.sessions.update(session.ID, map[string]interface{}{"is_active": false}); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(matches)), nil
+}
+
+// memoryAuditLogs implements AuditLogs
+type memoryAuditLogs struct {
+	s *MemoryStore
+}
+
+func (r *memoryAuditLogs) Create(entry *models.AuditLog) error {
+	defer r.s.lock()()
+	return r.s.data.auditLogs.insert(entry)
+}
+
+// memoryRecoveryCodes implements RecoveryCodes
+type memoryRecoveryCodes struct {
+	s *MemoryStore
+}
+
+func (r *memoryRecoveryCodes) Replace(userID string, codes []models.RecoveryCode) error {
+	return r.s.Transaction(func(tx Store) error {
+		table := r.s.data.recoveryCodes
+		table.remove(func(c *models.RecoveryCode) bool { return c.UserID == userID })
+		for i := range codes {
+			if err := table.insert(&codes[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *memoryRecoveryCodes) DeleteForUser(userID string) error {
+	defer r.s.lock()()
+	r.s.data.recoveryCodes.remove(func(c *models.RecoveryCode) bool { return c.UserID == userID })
+	return nil
+}
+
+func (r *memoryRecoveryCodes) CountUnused(userID string) (int64, error) {
+	defer r.s.lock()()
+	codes := r.s.data.recoveryCodes.find(func(c *models.RecoveryCode) bool {
+		return c.UserID == userID && c.UsedAt == nil
+	})
+	return int64(len(codes)), nil
+}
+
+func (r *memoryRecoveryCodes) Use(userID, codeHash string, at time.Time) (bool, error) {
+	defer r.s.lock()()
+	code, err := r.s.data.recoveryCodes.first(func(c *models.RecoveryCode) bool {
+		return c.UserID == userID && c.CodeHash == codeHash && c.UsedAt == nil
+	})
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if _, err := r.s.data.recoveryCodes.update(code.ID, map[string]interface{}{"used_at": at}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// memoryOAuthStates implements OAuthStates
+type memoryOAuthStates struct {
+	s *MemoryStore
+}
+
+func (r *memoryOAuthStates) Create(state *models.OAuthState) error {
+	defer r.s.lock()()
+	return r.s.data.oauthStates.insert(state)
+}
+
+func (r *memoryOAuthStates) Take(id, provider string) (*models.OAuthState, error) {
+	defer r.s.lock()()
+	state, ok := r.s.data.oauthStates.get(id)
+	if !ok || state.Provider != provider {
+		return nil, ErrNotFound
+	}
+	r.s.data.oauthStates.remove(func(s *models.OAuthState) bool { return s.ID == id })
+	return state, nil
+}
+
+func (r *memoryOAuthStates) DeleteExpired(now time.Time) error {
+	defer r.s.lock()()
+	r.s.data.oauthStates.remove(func(s *models.OAuthState) bool { return s.ExpiresAt.Before(now) })
+	return nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"time"
+
+	"digital-wardrobe-backend/internal/models"
+)
+
+// PriceAlertFilter selects a user's alerts. Nil and empty fields match any alert.
+type PriceAlertFilter struct {
+	UserID    string
+	Active    *bool
+	Triggered *bool
+	ItemID    string
+}
+
+// PriceAlerts stores price alerts
+type PriceAlerts interface {
+	// List returns the matching alerts, newest first
+	List(filter PriceAlertFilter) ([]models.PriceAlert, error)
+	// Get returns an alert owned by the user
+	Get(userID, id string) (*models.PriceAlert, error)
+	// Create inserts an alert. Zero values take the column defaults.
+	Create(alert *models.PriceAlert) error
+	// Save writes every column of an existing alert
+	Save(alert *models.PriceAlert) error
+	Update(id string, updates map[string]interface{}) error
+	// Delete removes an alert owned by the user
+	Delete(userID, id string) error
+	// CountWatching counts the user's active alerts that have not triggered
+	CountWatching(userID string) (int64, error)
+	// Due returns watching alerts last checked before checkedBefore, least
+	// recently checked first
+	Due(checkedBefore time.Time, limit int) ([]models.PriceAlert, error)
+	// Trigger applies updates to an alert that has not triggered yet and
+	// reports whether it did
+	Trigger(id string, updates map[string]interface{}) (bool, error)
+}
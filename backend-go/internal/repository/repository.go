@@ -0,0 +1,36 @@
+// Package repository is the storage layer behind the services. Every
+// repository has a GORM implementation backed by Postgres and an in-memory
+// one for tests, and both honor the same contract.
+package repository
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned when a record does not exist
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate is returned when a write would break a unique constraint
+	ErrDuplicate = errors.New("duplicate record")
+)
+
+// Store gives access to the repositories and runs transactions across them
+type Store interface {
+	Users() Users
+	Sessions() Sessions
+	AuditLogs() AuditLogs
+	RecoveryCodes() RecoveryCodes
+	OAuthStates() OAuthStates
+	Items() Items
+	PriceSnapshots() PriceSnapshots
+	Collections() Collections
+	PriceAlerts() PriceAlerts
+	Analytics() Analytics
+
+	// WithContext returns a Store whose operations run under ctx
+	WithContext(ctx context.Context) Store
+	// Transaction runs fn in a transaction that commits when fn returns nil.
+	// Rows read with a ForUpdate method stay locked until it ends.
+	Transaction(fn func(tx Store) error) error
+}
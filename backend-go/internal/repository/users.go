@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"time"
+
+	"digital-wardrobe-backend/internal/models"
+)
+
+// UserToken names one of the emailed single-use tokens stored on users
+type UserToken int
+
+const (
+	// EmailVerificationToken confirms the user's email address
+	EmailVerificationToken UserToken = iota
+	// PasswordResetToken lets the user choose a new password
+	PasswordResetToken
+	// UnlockToken lifts an account lock early
+	UnlockToken
+)
+
+// Users stores user accounts. Updates take column names, as GORM does.
+type Users interface {
+	// Create inserts a user. Zero values take the column defaults.
+	Create(user *models.User) error
+	Get(id string) (*models.User, error)
+	GetForUpdate(id string) (*models.User, error)
+	// FindByEmail matches the email exactly
+	FindByEmail(email string) (*models.User, error)
+	// FindByEmailFold matches the lower-cased email against email
+	FindByEmailFold(email string) (*models.User, error)
+	// FindByOAuthSubject finds the user whose provider column holds subject
+	FindByOAuthSubject(column, subject string) (*models.User, error)
+	// GetByTokenForUpdate finds the user holding an unexpired token hash
+	GetByTokenForUpdate(token UserToken, tokenHash string, now time.Time) (*models.User, error)
+	Update(id string, updates map[string]interface{}) error
+}
+
+// SessionFilter selects active sessions. Empty fields match any session.
+type SessionFilter struct {
+	ID       string
+	UserID   string
+	Token    string
+	ExceptID string
+}
+
+// Sessions stores sign-in sessions
+type Sessions interface {
+	Create(session *models.Session) error
+	GetForUpdate(id string) (*models.Session, error)
+	// GetActive returns the session if it has not been revoked
+	GetActive(id string) (*models.Session, error)
+	// GetActiveByToken returns the unrevoked session issued an access token
+	GetActiveByToken(token string) (*models.Session, error)
+	// ListActive returns a user's unrevoked, unexpired sessions, most recently updated first
+	ListActive(userID string, now time.Time) ([]models.Session, error)
+	Update(id string, updates map[string]interface{}) error
+	// Revoke deactivates the active sessions matching filter and returns how many there were
+	Revoke(filter SessionFilter) (int64, error)
+}
+
+// AuditLogs stores the security audit trail
+type AuditLogs interface {
+	Create(entry *models.AuditLog) error
+}
+
+// RecoveryCodes stores two-factor recovery codes
+type RecoveryCodes interface {
+	// Replace deletes the user's codes and stores codes in their place
+	Replace(userID string, codes []models.RecoveryCode) error
+	DeleteForUser(userID string) error
+	CountUnused(userID string) (int64, error)
+	// Use marks an unused code as used and reports whether there was one
+	Use(userID, codeHash string, at time.Time) (bool, error)
+}
+
+// OAuthStates stores pending OAuth authorizations
+type OAuthStates interface {
+	Create(state *models.OAuthState) error
+	// Take deletes and returns a provider's state. Each state can be taken once.
+	Take(id, provider string) (*models.OAuthState, error)
+	DeleteExpired(now time.Time) error
+}
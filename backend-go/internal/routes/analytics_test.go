@@ -0,0 +1,135 @@
+package routes_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"digital-wardrobe-backend/internal/testutil"
+)
+
+// seedPurchases adds three items purchased over the three months from
+// month and a wishlist item on sale
+func seedPurchases(t *testing.T, srv *testutil.Server, user *testutil.User, month time.Time) {
+	t.Helper()
+	for _, item := range []map[string]interface{}{
+		{"name": "Linen Shirt", "category": "tops", "brand": "Acme", "price": 40, "currency": "EUR", "status": "purchased", "purchaseDate": month.AddDate(0, 0, 9)},
+		{"name": "Chinos", "category": "bottoms", "brand": "Acme", "price": 60, "currency": "EUR", "status": "purchased", "purchaseDate": month.AddDate(0, 0, 24)},
+		{"name": "Loafers", "category": "shoes", "brand": "Stride", "price": 90, "currency": "EUR", "status": "purchased", "purchaseDate": month.AddDate(0, 2, 1)},
+		{"name": "Rain Jacket", "category": "outerwear", "brand": "Stride", "price": 80, "originalPrice": 120, "currency": "EUR"},
+	} {
+		createItem(t, srv, user, item)
+	}
+}
+
+// recentMonth is the start of the month four months ago: purchases from it
+// are over 30 days old but inside the six-month category window
+func recentMonth() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month()-4, 1, 12, 0, 0, 0, time.UTC)
+}
+
+func TestAnalyticsTrends(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+	seedPurchases(t, srv, user, time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC))
+
+	resp := srv.DoAs(user, http.MethodGet, "/analytics/trends?interval=month&startDate=2025-01-01&endDate=2025-03-31&groupBy=brand", nil)
+	resp.ExpectSuccess(http.StatusOK)
+	resp.Golden("analytics/trends")
+
+	var trends struct {
+		Series []struct {
+			Key    string `json:"key"`
+			Points []struct {
+				Period         time.Time          `json:"period"`
+				ItemsPurchased int                `json:"itemsPurchased"`
+				Spend          map[string]float64 `json:"spend"`
+			} `json:"points"`
+		} `json:"series"`
+	}
+	resp.Envelope().Decode(&trends)
+
+	want := map[string][]float64{
+		"acme":   {100, 0, 0},
+		"stride": {0, 0, 90},
+	}
+	for _, series := range trends.Series {
+		spend, ok := want[series.Key]
+		if !ok {
+			continue
+		}
+		delete(want, series.Key)
+		if len(series.Points) != len(spend) {
+			t.Fatalf("%s: %d points, want %d", series.Key, len(series.Points), len(spend))
+		}
+		for i, point := range series.Points {
+			if month := time.Month(i + 1); point.Period.Month() != month || point.Period.Year() != 2025 {
+				t.Errorf("%s point %d: period = %s, want %s 2025", series.Key, i, point.Period, month)
+			}
+			if point.Spend["EUR"] != spend[i] {
+				t.Errorf("%s %s: spend = %v, want %v", series.Key, point.Period.Month(), point.Spend["EUR"], spend[i])
+			}
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("missing series: %v", want)
+	}
+
+	srv.DoAs(user, http.MethodGet, "/analytics/trends?interval=year", nil).ExpectError(http.StatusBadRequest, "VALIDATION_ERROR")
+	srv.DoAs(user, http.MethodGet, "/analytics/trends?startDate=2025-03-01&endDate=2025-01-01", nil).
+		ExpectError(http.StatusBadRequest, "VALIDATION_ERROR")
+}
+
+func TestAnalyticsInsights(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+	seedPurchases(t, srv, user, recentMonth())
+
+	resp := srv.DoAs(user, http.MethodGet, "/analytics/insights", nil)
+	resp.ExpectSuccess(http.StatusOK)
+	resp.Golden("analytics/insights")
+}
+
+func TestAnalyticsOverview(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+	seedPurchases(t, srv, user, recentMonth())
+
+	type overviewData struct {
+		TotalItems        int                `json:"totalItems"`
+		TotalSpent        map[string]float64 `json:"totalSpent"`
+		AverageItemPrice  map[string]float64 `json:"averageItemPrice"`
+		CategoryBreakdown map[string]int     `json:"categoryBreakdown"`
+	}
+	var overview overviewData
+	srv.DoAs(user, http.MethodGet, "/analytics/overview", nil).ExpectSuccess(http.StatusOK).Decode(&overview)
+	if overview.TotalItems != 4 || overview.TotalSpent["EUR"] != 190 || len(overview.CategoryBreakdown) != 4 {
+		t.Errorf("overview = %+v, want 4 items in 4 categories and 190 EUR spent", overview)
+	}
+
+	// Stored analytics are updated as items change; currencies are never added up
+	createItem(t, srv, user, map[string]interface{}{"name": "Tee", "category": "tops", "price": 25, "currency": "USD", "status": "purchased"})
+	overview = overviewData{}
+	srv.DoAs(user, http.MethodGet, "/analytics/overview", nil).ExpectSuccess(http.StatusOK).Decode(&overview)
+	wantSpent := map[string]float64{"EUR": 190, "USD": 25}
+	wantAverage := map[string]float64{"EUR": 67.5, "USD": 25}
+	if overview.TotalItems != 5 || !reflect.DeepEqual(overview.TotalSpent, wantSpent) || !reflect.DeepEqual(overview.AverageItemPrice, wantAverage) {
+		t.Errorf("overview = %+v, want 5 items, %v spent and %v average prices", overview, wantSpent, wantAverage)
+	}
+
+	srv.Do(http.MethodGet, "/analytics/overview", nil).ExpectError(http.StatusUnauthorized, "AUTHENTICATION_REQUIRED")
+}
+
+func TestAnalyticsDisabled(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+	if err := srv.Store.Users().Update(user.ID, map[string]interface{}{"allow_analytics": false}); err != nil {
+		t.Fatalf("disable analytics: %v", err)
+	}
+
+	for _, path := range []string{"/analytics/overview", "/analytics/trends", "/analytics/insights"} {
+		srv.DoAs(user, http.MethodGet, path, nil).ExpectError(http.StatusForbidden, "ANALYTICS_DISABLED")
+	}
+}
@@ -0,0 +1,135 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"digital-wardrobe-backend/internal/testutil"
+)
+
+func TestAuthRegister(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	resp := srv.Do(http.MethodPost, "/auth/register", map[string]string{
+		"email":     "ada@example.com",
+		"password":  testutil.DefaultPassword,
+		"firstName": "Ada",
+		"lastName":  "Lovelace",
+	})
+	resp.ExpectSuccess(http.StatusCreated)
+	resp.Golden("auth/register")
+
+	if n := len(srv.Mailer.Messages()); n != 1 {
+		t.Errorf("sent %d emails, want the verification email", n)
+	}
+
+	srv.Do(http.MethodPost, "/auth/register", map[string]string{
+		"email":    "ada@example.com",
+		"password": testutil.DefaultPassword,
+	}).ExpectError(http.StatusConflict, "REGISTRATION_FAILED")
+
+	resp = srv.Do(http.MethodPost, "/auth/register", map[string]string{
+		"email":    "not-an-email",
+		"password": "short",
+	})
+	resp.ExpectError(http.StatusBadRequest, "VALIDATION_ERROR")
+	resp.Golden("auth/register-invalid")
+}
+
+func TestAuthLogin(t *testing.T) {
+	srv := testutil.NewServer(t)
+	srv.Register("ada@example.com")
+
+	resp := srv.Do(http.MethodPost, "/auth/login", map[string]string{
+		"email":    "ada@example.com",
+		"password": testutil.DefaultPassword,
+	})
+	resp.ExpectSuccess(http.StatusOK)
+	resp.Golden("auth/login")
+
+	srv.Do(http.MethodPost, "/auth/login", map[string]string{
+		"email":    "ada@example.com",
+		"password": "wrong-password",
+	}).ExpectError(http.StatusUnauthorized, "INVALID_CREDENTIALS")
+
+	srv.Do(http.MethodPost, "/auth/login", map[string]string{
+		"email":    "nobody@example.com",
+		"password": testutil.DefaultPassword,
+	}).ExpectError(http.StatusUnauthorized, "INVALID_CREDENTIALS")
+}
+
+func TestAuthLoginIgnoresSpoofedForwardedFor(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	// The per-IP limit is 50 attempts; a new email each time keeps the
+	// per-email limit out of the way
+	attempt := func(i int) *testutil.Response {
+		header := http.Header{}
+		header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i))
+		return srv.DoWithHeader(http.MethodPost, "/auth/login", map[string]string{
+			"email":    fmt.Sprintf("user%d@example.com", i),
+			"password": "wrong-password",
+		}, header)
+	}
+	for i := 0; i < 50; i++ {
+		attempt(i).ExpectError(http.StatusUnauthorized, "INVALID_CREDENTIALS")
+	}
+
+	resp := attempt(50)
+	resp.ExpectError(http.StatusTooManyRequests, "TOO_MANY_REQUESTS")
+	if resp.Header.Get("Retry-After") == "" {
+		t.Error("throttled response has no Retry-After header")
+	}
+}
+
+func TestAuthProfile(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+
+	resp := srv.DoAs(user, http.MethodGet, "/auth/profile", nil)
+	resp.ExpectSuccess(http.StatusOK)
+	resp.Golden("auth/profile")
+
+	srv.Do(http.MethodGet, "/auth/profile", nil).ExpectError(http.StatusUnauthorized, "AUTHENTICATION_REQUIRED")
+	srv.DoAs(&testutil.User{Token: "not-a-token"}, http.MethodGet, "/auth/profile", nil).
+		ExpectError(http.StatusUnauthorized, "INVALID_TOKEN")
+}
+
+func TestAuthRefreshRotatesTokens(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+
+	var refreshed struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refreshToken"`
+	}
+	srv.Do(http.MethodPost, "/auth/refresh", map[string]string{"refreshToken": user.RefreshToken}).
+		ExpectSuccess(http.StatusOK).
+		Decode(&refreshed)
+	if refreshed.RefreshToken == user.RefreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+
+	// Presenting the rotated token again revokes the session
+	srv.Do(http.MethodPost, "/auth/refresh", map[string]string{"refreshToken": user.RefreshToken}).
+		ExpectError(http.StatusUnauthorized, "REFRESH_TOKEN_REUSED")
+	srv.Do(http.MethodPost, "/auth/refresh", map[string]string{"refreshToken": refreshed.RefreshToken}).
+		ExpectError(http.StatusUnauthorized, "INVALID_REFRESH_TOKEN")
+	srv.DoAs(&testutil.User{Token: refreshed.Token}, http.MethodGet, "/auth/profile", nil).
+		ExpectError(http.StatusUnauthorized, "INVALID_TOKEN")
+}
+
+func TestAuthLogoutRevokesSession(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+	other := srv.Login(user.Email, user.Password)
+
+	resp := srv.DoAs(user, http.MethodGet, "/auth/sessions", nil)
+	resp.ExpectSuccess(http.StatusOK)
+	resp.Golden("auth/sessions")
+
+	srv.DoAs(user, http.MethodPost, "/auth/logout", nil).ExpectSuccess(http.StatusOK)
+	srv.DoAs(user, http.MethodGet, "/auth/profile", nil).ExpectError(http.StatusUnauthorized, "INVALID_TOKEN")
+	srv.DoAs(other, http.MethodGet, "/auth/profile", nil).ExpectSuccess(http.StatusOK)
+}
@@ -0,0 +1,91 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+
+	"digital-wardrobe-backend/internal/testutil"
+)
+
+// createCollection adds a collection for user and returns its ID
+func createCollection(t *testing.T, srv *testutil.Server, user *testutil.User, name string) string {
+	t.Helper()
+	var created struct {
+		ID string `json:"id"`
+	}
+	srv.DoAs(user, http.MethodPost, "/collections", map[string]interface{}{"name": name}).
+		ExpectSuccess(http.StatusCreated).
+		Decode(&created)
+	return created.ID
+}
+
+func TestCollectionsCreateAndList(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+
+	resp := srv.DoAs(user, http.MethodPost, "/collections", map[string]interface{}{
+		"name":        "Summer capsule",
+		"description": "Light layers for July",
+		"color":       "#f4a261",
+	})
+	resp.ExpectSuccess(http.StatusCreated)
+	resp.Golden("collections/create")
+
+	resp = srv.DoAs(user, http.MethodGet, "/collections", nil)
+	resp.ExpectSuccess(http.StatusOK)
+	resp.Golden("collections/list")
+
+	srv.DoAs(user, http.MethodPost, "/collections", map[string]interface{}{"description": "no name"}).
+		ExpectError(http.StatusBadRequest, "VALIDATION_ERROR")
+}
+
+func TestCollectionsMembership(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+	collection := createCollection(t, srv, user, "Capsule")
+	tee := createItem(t, srv, user, map[string]interface{}{"name": "Tee", "category": "tops"})
+	jeans := createItem(t, srv, user, map[string]interface{}{"name": "Jeans", "category": "bottoms"})
+	boots := createItem(t, srv, user, map[string]interface{}{"name": "Boots", "category": "shoes"})
+
+	for _, id := range []string{tee, jeans} {
+		srv.DoAs(user, http.MethodPost, "/collections/"+collection+"/items", map[string]interface{}{"itemId": id}).
+			ExpectSuccess(http.StatusCreated)
+	}
+	resp := srv.DoAs(user, http.MethodPost, "/collections/"+collection+"/items", map[string]interface{}{
+		"itemId": boots,
+		"notes":  "for rainy days",
+		"order":  0,
+	})
+	resp.ExpectSuccess(http.StatusCreated)
+	resp.Golden("collections/add-item")
+
+	srv.DoAs(user, http.MethodPost, "/collections/"+collection+"/items", map[string]interface{}{"itemId": tee}).
+		ExpectError(http.StatusConflict, "DUPLICATE_COLLECTION_ITEM")
+
+	srv.DoAs(user, http.MethodPut, "/collections/"+collection+"/items/order", map[string]interface{}{
+		"itemIds": []string{jeans, boots, tee},
+	}).ExpectSuccess(http.StatusOK)
+	srv.DoAs(user, http.MethodDelete, "/collections/"+collection+"/items/"+boots, nil).ExpectSuccess(http.StatusOK)
+
+	resp = srv.DoAs(user, http.MethodGet, "/collections/"+collection, nil)
+	resp.ExpectSuccess(http.StatusOK)
+	resp.Golden("collections/get")
+
+	other := srv.Register("grace@example.com")
+	srv.DoAs(other, http.MethodGet, "/collections/"+collection, nil).ExpectError(http.StatusNotFound, "COLLECTION_NOT_FOUND")
+	srv.DoAs(other, http.MethodPost, "/collections/"+createCollection(t, srv, other, "Mine")+"/items", map[string]interface{}{"itemId": tee}).
+		ExpectError(http.StatusNotFound, "ITEM_NOT_FOUND")
+}
+
+func TestCollectionsDelete(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+	collection := createCollection(t, srv, user, "Capsule")
+	tee := createItem(t, srv, user, map[string]interface{}{"name": "Tee", "category": "tops"})
+	srv.DoAs(user, http.MethodPost, "/collections/"+collection+"/items", map[string]interface{}{"itemId": tee}).
+		ExpectSuccess(http.StatusCreated)
+
+	srv.DoAs(user, http.MethodDelete, "/collections/"+collection, nil).ExpectSuccess(http.StatusOK)
+	srv.DoAs(user, http.MethodGet, "/collections/"+collection, nil).ExpectError(http.StatusNotFound, "COLLECTION_NOT_FOUND")
+	srv.DoAs(user, http.MethodGet, "/items/"+tee, nil).ExpectSuccess(http.StatusOK)
+}
@@ -0,0 +1,201 @@
+package routes_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"digital-wardrobe-backend/internal/testutil"
+)
+
+// itemRef is the part of an item the tests follow up on
+type itemRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// createItem adds an item for user and returns its ID
+func createItem(t *testing.T, srv *testutil.Server, user *testutil.User, item map[string]interface{}) string {
+	t.Helper()
+	var created itemRef
+	srv.DoAs(user, http.MethodPost, "/items", item).ExpectSuccess(http.StatusCreated).Decode(&created)
+	return created.ID
+}
+
+func TestItemsCreateAndGet(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+
+	resp := srv.DoAs(user, http.MethodPost, "/items", map[string]interface{}{
+		"name":          "Linen Shirt",
+		"brand":         "Acme",
+		"category":      "tops",
+		"price":         45.5,
+		"originalPrice": 60,
+		"currency":      "EUR",
+		"size":          "M",
+		"color":         "white",
+		"tags":          []string{"summer", "linen"},
+		"originalUrl":   "https://shop.example/linen-shirt",
+	})
+	resp.ExpectSuccess(http.StatusCreated)
+	resp.Golden("items/create")
+
+	var created itemRef
+	resp.Envelope().Decode(&created)
+
+	resp = srv.DoAs(user, http.MethodGet, "/items/"+created.ID, nil)
+	resp.ExpectSuccess(http.StatusOK)
+	resp.Golden("items/get")
+
+	other := srv.Register("grace@example.com")
+	srv.DoAs(other, http.MethodGet, "/items/"+created.ID, nil).ExpectError(http.StatusNotFound, "ITEM_NOT_FOUND")
+	resp = srv.DoAs(user, http.MethodGet, "/items/not-an-id", nil)
+	resp.ExpectError(http.StatusNotFound, "ITEM_NOT_FOUND")
+	resp.Golden("items/get-not-found")
+
+	srv.DoAs(user, http.MethodPost, "/items", map[string]interface{}{"category": "tops"}).
+		ExpectError(http.StatusBadRequest, "VALIDATION_ERROR")
+	srv.Do(http.MethodGet, "/items", nil).ExpectError(http.StatusUnauthorized, "AUTHENTICATION_REQUIRED")
+}
+
+func TestItemsListPages(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+	for _, item := range []map[string]interface{}{
+		{"name": "Tee", "category": "tops", "price": 20},
+		{"name": "Jeans", "category": "bottoms", "price": 80},
+		{"name": "Boots", "category": "shoes", "price": 120},
+		{"name": "Cap", "category": "accessories", "price": 15},
+	} {
+		createItem(t, srv, user, item)
+	}
+
+	resp := srv.DoAs(user, http.MethodGet, "/items?sortBy=price&sortOrder=desc&limit=3", nil)
+	resp.ExpectSuccess(http.StatusOK)
+	resp.Golden("items/list")
+
+	var page struct {
+		Data       []itemRef `json:"data"`
+		Pagination struct {
+			NextCursor string `json:"nextCursor"`
+			HasMore    bool   `json:"hasMore"`
+		} `json:"pagination"`
+	}
+	resp.Decode(&page)
+	if !page.Pagination.HasMore || page.Pagination.NextCursor == "" {
+		t.Fatalf("first page should continue: %+v", page.Pagination)
+	}
+
+	next := srv.DoAs(user, http.MethodGet, "/items?sortBy=price&sortOrder=desc&limit=3&cursor="+url.QueryEscape(page.Pagination.NextCursor), nil)
+	next.ExpectSuccess(http.StatusOK)
+	page.Data = nil
+	next.Decode(&page)
+	if len(page.Data) != 1 || page.Data[0].Name != "Cap" || page.Pagination.HasMore {
+		t.Errorf("second page = %+v, want only Cap", page)
+	}
+
+	// A cursor edited to carry a non-UUID id is rejected, not passed to the database
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"price:desc","v":80,"id":"not-a-uuid"}`))
+	srv.DoAs(user, http.MethodGet, "/items?sortBy=price&sortOrder=desc&limit=3&cursor="+forged, nil).
+		ExpectError(http.StatusBadRequest, "VALIDATION_ERROR")
+
+	resp = srv.DoAs(user, http.MethodGet, "/items?sortBy=shoeSize", nil)
+	resp.ExpectError(http.StatusBadRequest, "VALIDATION_ERROR")
+	resp.Golden("items/list-invalid-sort")
+}
+
+func TestItemsUpdateArchiveRestoreDelete(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+	id := createItem(t, srv, user, map[string]interface{}{"name": "Tee", "category": "tops", "price": 20})
+
+	resp := srv.DoAs(user, http.MethodPut, "/items/"+id, map[string]interface{}{
+		"name":     "Organic Tee",
+		"category": "tops",
+		"price":    18,
+		"status":   "owned",
+	})
+	resp.ExpectSuccess(http.StatusOK)
+	resp.Golden("items/update")
+
+	resp = srv.DoAs(user, http.MethodDelete, "/items/"+id, nil)
+	resp.ExpectSuccess(http.StatusOK)
+	resp.Golden("items/archive")
+
+	var archived struct {
+		Data []itemRef `json:"data"`
+	}
+	srv.DoAs(user, http.MethodGet, "/items?archived=true", nil).Decode(&archived)
+	if len(archived.Data) != 1 || archived.Data[0].ID != id {
+		t.Errorf("archived items = %+v", archived.Data)
+	}
+
+	srv.DoAs(user, http.MethodPost, "/items/"+id+"/restore", nil).ExpectSuccess(http.StatusOK)
+	srv.DoAs(user, http.MethodDelete, "/items/"+id+"?permanent=true", nil).ExpectSuccess(http.StatusOK)
+	srv.DoAs(user, http.MethodGet, "/items/"+id, nil).ExpectError(http.StatusNotFound, "ITEM_NOT_FOUND")
+}
+
+func TestItemsSearch(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+	createItem(t, srv, user, map[string]interface{}{"name": "Linen Shirt", "category": "tops", "brand": "Acme"})
+	createItem(t, srv, user, map[string]interface{}{"name": "Plain Tee", "category": "tops", "description": "Pairs well with linen trousers"})
+	createItem(t, srv, user, map[string]interface{}{"name": "Wool Sweater", "category": "tops"})
+
+	resp := srv.DoAs(user, http.MethodGet, "/items/search?q=linen", nil)
+	resp.ExpectSuccess(http.StatusOK)
+	resp.Golden("items/search")
+}
+
+func TestItemsSearchEscapesHighlights(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+	createItem(t, srv, user, map[string]interface{}{
+		"name":        "<script>alert(1)</script> Linen Shirt",
+		"category":    "tops",
+		"description": `Linen <img src=x onerror="alert(1)">`,
+	})
+
+	var found struct {
+		Data []struct {
+			Highlights struct {
+				Name    string `json:"name"`
+				Snippet string `json:"snippet"`
+			} `json:"highlights"`
+		} `json:"data"`
+	}
+	srv.DoAs(user, http.MethodGet, "/items/search?q=linen", nil).Decode(&found)
+	if len(found.Data) != 1 {
+		t.Fatalf("search found %d items, want 1", len(found.Data))
+	}
+
+	highlights := found.Data[0].Highlights
+	if want := "&lt;script&gt;alert(1)&lt;/script&gt; <mark>Linen</mark> Shirt"; highlights.Name != want {
+		t.Errorf("name highlight = %q, want %q", highlights.Name, want)
+	}
+	if want := "<mark>Linen</mark> &lt;img src=x onerror=&#34;alert(1)&#34;&gt;"; highlights.Snippet != want {
+		t.Errorf("snippet = %q, want %q", highlights.Snippet, want)
+	}
+}
+
+func TestItemsRecordPrice(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+	id := createItem(t, srv, user, map[string]interface{}{"name": "Linen Shirt", "category": "tops", "price": 45.5})
+
+	var snapshot struct {
+		Price float64 `json:"price"`
+	}
+	srv.DoAs(user, http.MethodPost, "/items/"+id+"/price-history", map[string]interface{}{"price": 0}).
+		ExpectSuccess(http.StatusCreated).Decode(&snapshot)
+	if snapshot.Price != 0 {
+		t.Errorf("recorded price = %v, want 0", snapshot.Price)
+	}
+
+	srv.DoAs(user, http.MethodPost, "/items/"+id+"/price-history", map[string]interface{}{"currency": "USD"}).
+		ExpectError(http.StatusBadRequest, "VALIDATION_ERROR")
+	srv.DoAs(user, http.MethodPost, "/items/"+id+"/price-history", map[string]interface{}{"price": -1}).
+		ExpectError(http.StatusBadRequest, "VALIDATION_ERROR")
+}
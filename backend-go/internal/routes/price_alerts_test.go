@@ -0,0 +1,66 @@
+package routes_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"digital-wardrobe-backend/internal/ids"
+	"digital-wardrobe-backend/internal/testutil"
+)
+
+func TestPriceAlertsRejectPrivateURLs(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+
+	for _, productURL := range []string{
+		"http://localhost/shirt",
+		"http://10.0.0.5/shirt",
+		"http://192.168.1.20:8080/shirt",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/shirt",
+		"http://[fd00::1]/shirt",
+	} {
+		env := srv.DoAs(user, http.MethodPost, "/alerts", map[string]interface{}{
+			"productUrl":  productURL,
+			"targetPrice": 25,
+		}).ExpectError(http.StatusBadRequest, "VALIDATION_ERROR")
+		if !strings.Contains(string(env.Details), "productUrl") {
+			t.Errorf("%s: details = %s, want a productUrl entry", productURL, env.Details)
+		}
+	}
+
+	srv.DoAs(user, http.MethodPost, "/alerts", map[string]interface{}{
+		"productUrl":  "https://shop.example/shirt",
+		"targetPrice": 25,
+	}).ExpectSuccess(http.StatusCreated)
+}
+
+func TestPriceAlertsListByItem(t *testing.T) {
+	srv := testutil.NewServer(t)
+	user := srv.Register("ada@example.com")
+	id := createItem(t, srv, user, map[string]interface{}{
+		"name":        "Linen Shirt",
+		"category":    "tops",
+		"originalUrl": "https://shop.example/shirt",
+	})
+
+	srv.DoAs(user, http.MethodPost, "/alerts", map[string]interface{}{
+		"itemId":      id,
+		"targetPrice": 25,
+	}).ExpectSuccess(http.StatusCreated)
+
+	for query, want := range map[string]int{
+		id:           1,
+		ids.New():    0,
+		"not-a-uuid": 0,
+	} {
+		var alerts []struct {
+			ID string `json:"id"`
+		}
+		srv.DoAs(user, http.MethodGet, "/alerts?itemId="+query, nil).ExpectSuccess(http.StatusOK).Decode(&alerts)
+		if len(alerts) != want {
+			t.Errorf("itemId=%s listed %d alerts, want %d", query, len(alerts), want)
+		}
+	}
+}
@@ -1,21 +1,29 @@
 package routes
 
 import (
+	"time"
+
+	"digital-wardrobe-backend/internal/extraction"
 	"digital-wardrobe-backend/internal/handlers"
 	"digital-wardrobe-backend/internal/middleware"
 	"digital-wardrobe-backend/internal/services"
+	"digital-wardrobe-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Handlers holds all handlers
 type Handlers struct {
-	Auth        *handlers.AuthHandler
-	User        *handlers.UserHandler
-	Item        *handlers.ItemHandler
-	Collection  *handlers.CollectionHandler
-	Analytics   *handlers.AnalyticsHandler
-	AuthService *services.AuthService
+	Auth         *handlers.AuthHandler
+	OAuth        *handlers.OAuthHandler
+	MFA          *handlers.MFAHandler
+	User         *handlers.UserHandler
+	Item         *handlers.ItemHandler
+	Collection   *handlers.CollectionHandler
+	Analytics    *handlers.AnalyticsHandler
+	PriceHistory *handlers.PriceHistoryHandler
+	PriceAlert   *handlers.PriceAlertHandler
+	AuthService  *services.AuthService
 }
 
 // New creates new handlers
@@ -25,18 +33,74 @@ func New(
 	itemService *services.ItemService,
 	collectionService *services.CollectionService,
 	analyticsService *services.AnalyticsService,
+	priceHistoryService *services.PriceHistoryService,
+	priceAlertService *services.PriceAlertService,
+	extractor *extraction.Extractor,
 	redisClient *services.RedisClient,
 ) *Handlers {
+	// Name fields in validation errors the way clients send them
+	handlers.RegisterFieldNames()
+
 	return &Handlers{
-		Auth:        handlers.NewAuthHandler(authService),
-		User:        handlers.NewUserHandler(userService),
-		Item:        handlers.NewItemHandler(itemService),
-		Collection:  handlers.NewCollectionHandler(collectionService),
-		Analytics:   handlers.NewAnalyticsHandler(analyticsService),
-		AuthService: authService, // Keep reference for middleware
+		Auth:         handlers.NewAuthHandler(authService),
+		OAuth:        handlers.NewOAuthHandler(authService),
+		MFA:          handlers.NewMFAHandler(authService),
+		User:         handlers.NewUserHandler(userService),
+		Item:         handlers.NewItemHandler(itemService, extractor),
+		Collection:   handlers.NewCollectionHandler(collectionService),
+		Analytics:    handlers.NewAnalyticsHandler(analyticsService),
+		PriceHistory: handlers.NewPriceHistoryHandler(priceHistoryService),
+		PriceAlert:   handlers.NewPriceAlertHandler(priceAlertService),
+		AuthService:  authService, // Keep reference for middleware
 	}
 }
 
+// RouterOptions configures the middleware around the routes
+type RouterOptions struct {
+	APIPrefix      string
+	CORSOrigins    []string
+	RequestTimeout time.Duration
+	Logger         logger.Logger
+	// TrustedProxies may set the client IP through X-Forwarded-For; none by default
+	TrustedProxies []string
+}
+
+// NewRouter creates a router with the global middleware, all routes and the health check
+func NewRouter(handlers *Handlers, opts RouterOptions) *gin.Engine {
+	router := gin.New()
+
+	// Gin trusts every proxy by default, which would let any client pick the
+	// IP used for sign-in rate limits and session records
+	if err := router.SetTrustedProxies(opts.TrustedProxies); err != nil {
+		opts.Logger.Errorf("Invalid trusted proxies, trusting none: %v", err)
+		_ = router.SetTrustedProxies(nil)
+	}
+
+	// Global middleware
+	router.Use(middleware.Logger(opts.Logger))
+	router.Use(middleware.Recovery(opts.Logger))
+	router.Use(middleware.CORS(opts.CORSOrigins))
+	router.Use(middleware.Compression())
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Timeout(opts.RequestTimeout))
+	// Innermost, so error responses are written before compression finishes
+	router.Use(middleware.ErrorHandler(opts.Logger))
+
+	Setup(router, handlers, opts.APIPrefix)
+
+	// Health check
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"status":    "healthy",
+			"timestamp": time.Now().UTC(),
+			"service":   "Digital Wardrobe API",
+			"version":   "1.0.0",
+		})
+	})
+
+	return router
+}
+
 // Setup sets up all routes
 func Setup(router *gin.Engine, handlers *Handlers, apiPrefix string) {
 	// API v1 group
@@ -47,8 +111,43 @@ func Setup(router *gin.Engine, handlers *Handlers, apiPrefix string) {
 		{
 			auth.POST("/register", handlers.Auth.Register)
 			auth.POST("/login", handlers.Auth.Login)
+			auth.POST("/refresh", handlers.Auth.Refresh)
+			auth.POST("/google", handlers.OAuth.GoogleLogin)
+			auth.POST("/verify-email", handlers.Auth.VerifyEmail)
+			auth.POST("/verify-email/resend", middleware.AuthMiddleware(handlers.AuthService), handlers.Auth.ResendVerification)
+			auth.POST("/forgot-password", handlers.Auth.ForgotPassword)
+			auth.POST("/unlock", handlers.Auth.UnlockAccount)
+			auth.POST("/reset-password", handlers.Auth.ResetPassword)
+			auth.POST("/change-password", middleware.AuthMiddleware(handlers.AuthService), handlers.Auth.ChangePassword)
 			auth.GET("/profile", middleware.AuthMiddleware(handlers.AuthService), handlers.Auth.GetProfile)
 			auth.POST("/logout", middleware.AuthMiddleware(handlers.AuthService), handlers.Auth.Logout)
+			auth.GET("/sessions", middleware.AuthMiddleware(handlers.AuthService), handlers.Auth.GetSessions)
+			auth.DELETE("/sessions", middleware.AuthMiddleware(handlers.AuthService), handlers.Auth.RevokeOtherSessions)
+			auth.DELETE("/sessions/:id", middleware.AuthMiddleware(handlers.AuthService), handlers.Auth.RevokeSession)
+
+			// Two-factor authentication
+			mfa := auth.Group("/mfa")
+			{
+				mfa.POST("/verify", handlers.MFA.Verify)
+				mfa.GET("", middleware.AuthMiddleware(handlers.AuthService), handlers.MFA.GetStatus)
+				mfa.POST("/totp", middleware.AuthMiddleware(handlers.AuthService), handlers.MFA.EnrollTOTP)
+				mfa.POST("/totp/confirm", middleware.AuthMiddleware(handlers.AuthService), handlers.MFA.ConfirmTOTP)
+				mfa.DELETE("/totp", middleware.AuthMiddleware(handlers.AuthService), handlers.MFA.DisableTOTP)
+				mfa.POST("/recovery-codes", middleware.AuthMiddleware(handlers.AuthService), handlers.MFA.RegenerateRecoveryCodes)
+			}
+
+			// Third-party sign-in and account linking
+			oauth := auth.Group("/oauth")
+			{
+				oauth.GET("/providers", handlers.OAuth.GetProviders)
+				oauth.GET("/accounts", middleware.AuthMiddleware(handlers.AuthService), handlers.OAuth.GetAccounts)
+				oauth.POST("/link/confirm", middleware.AuthMiddleware(handlers.AuthService), handlers.OAuth.ConfirmLink)
+				oauth.GET("/:provider/authorize", handlers.OAuth.Authorize)
+				oauth.GET("/:provider/callback", handlers.OAuth.Callback)
+				oauth.POST("/:provider/callback", handlers.OAuth.Callback)
+				oauth.POST("/:provider/link", middleware.AuthMiddleware(handlers.AuthService), handlers.OAuth.StartLink)
+				oauth.DELETE("/:provider", middleware.AuthMiddleware(handlers.AuthService), handlers.OAuth.Unlink)
+			}
 		}
 
 		// User routes (auth required)
@@ -66,9 +165,13 @@ func Setup(router *gin.Engine, handlers *Handlers, apiPrefix string) {
 		{
 			items.GET("", handlers.Item.GetItems)
 			items.POST("", handlers.Item.CreateItem)
+			items.POST("/extract", handlers.Item.ExtractItem)
 			items.GET("/:id", handlers.Item.GetItem)
 			items.PUT("/:id", handlers.Item.UpdateItem)
 			items.DELETE("/:id", handlers.Item.DeleteItem)
+			items.POST("/:id/restore", handlers.Item.RestoreItem)
+			items.GET("/:id/price-history", handlers.PriceHistory.GetPriceHistory)
+			items.POST("/:id/price-history", handlers.PriceHistory.RecordPrice)
 			items.GET("/search", handlers.Item.SearchItems)
 		}
 
@@ -82,9 +185,24 @@ func Setup(router *gin.Engine, handlers *Handlers, apiPrefix string) {
 			collections.PUT("/:id", handlers.Collection.UpdateCollection)
 			collections.DELETE("/:id", handlers.Collection.DeleteCollection)
 			collections.POST("/:id/items", handlers.Collection.AddItemToCollection)
+			collections.PUT("/:id/items/order", handlers.Collection.ReorderCollectionItems)
+			collections.PATCH("/:id/items/:itemId", handlers.Collection.UpdateCollectionItem)
 			collections.DELETE("/:id/items/:itemId", handlers.Collection.RemoveItemFromCollection)
 		}
 
+		// Price alert routes (auth required)
+		alerts := v1.Group("/alerts")
+		alerts.Use(middleware.AuthMiddleware(handlers.AuthService))
+		{
+			alerts.GET("", handlers.PriceAlert.GetPriceAlerts)
+			alerts.POST("", handlers.PriceAlert.CreatePriceAlert)
+			alerts.GET("/:id", handlers.PriceAlert.GetPriceAlert)
+			alerts.PATCH("/:id", handlers.PriceAlert.UpdatePriceAlert)
+			alerts.DELETE("/:id", handlers.PriceAlert.DeletePriceAlert)
+			alerts.POST("/:id/pause", handlers.PriceAlert.PausePriceAlert)
+			alerts.POST("/:id/resume", handlers.PriceAlert.ResumePriceAlert)
+		}
+
 		// Analytics routes (auth required)
 		analytics := v1.Group("/analytics")
 		analytics.Use(middleware.AuthMiddleware(handlers.AuthService))
@@ -0,0 +1,28 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+
+	"digital-wardrobe-backend/internal/testutil"
+)
+
+func TestHealthRunsGlobalMiddleware(t *testing.T) {
+	srv := testutil.NewServer(t)
+
+	resp := srv.Do(http.MethodGet, "//health", nil)
+	if resp.Status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.Status)
+	}
+	if resp.Header.Get("X-Request-ID") == "" {
+		t.Error("response has no X-Request-ID header")
+	}
+
+	var health struct {
+		Status string `json:"status"`
+	}
+	resp.Decode(&health)
+	if health.Status != "healthy" {
+		t.Errorf("status = %q, want healthy", health.Status)
+	}
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"digital-wardrobe-backend/internal/models"
+)
+
+// Insight severities
+const (
+	InsightInfo        = "info"
+	InsightOpportunity = "opportunity"
+	InsightWarning     = "warning"
+)
+
+// Insight is an actionable observation about a user's wardrobe. Evidence
+// holds the numbers behind the message so clients can explain it.
+type Insight struct {
+	Rule     string                 `json:"rule"`
+	Severity string                 `json:"severity"`
+	Title    string                 `json:"title"`
+	Message  string                 `json:"message"`
+	Evidence map[string]interface{} `json:"evidence"`
+	ItemIDs  []string               `json:"itemIds,omitempty"`
+}
+
+// InsightContext is the wardrobe data insight rules evaluate
+type InsightContext struct {
+	UserID string
+	Now    time.Time
+	// Items are the user's non-archived items
+	Items []models.Item
+	// LatestPrices holds the most recent price snapshot of each wishlist item, by item ID
+	LatestPrices map[string]models.PriceSnapshot
+}
+
+// InsightRule produces insights from a user's wardrobe
+type InsightRule interface {
+	// Name identifies the rule in the insights it produces
+	Name() string
+	Evaluate(ctx *InsightContext) []Insight
+}
+
+// InsightRuleFunc adapts a function to the InsightRule interface
+type InsightRuleFunc struct {
+	RuleName string
+	Fn       func(ctx *InsightContext) []Insight
+}
+
+// Name returns the rule name
+func (r InsightRuleFunc) Name() string { return r.RuleName }
+
+// Evaluate runs the rule
+func (r InsightRuleFunc) Evaluate(ctx *InsightContext) []Insight { return r.Fn(ctx) }
+
+// DefaultInsightRules returns the built-in insight rules
+func DefaultInsightRules() []InsightRule {
+	return []InsightRule{
+		InsightRuleFunc{"wishlist_on_sale", wishlistOnSaleRule},
+		InsightRuleFunc{"purchased_not_owned", purchasedNotOwnedRule},
+		InsightRuleFunc{"brand_spend_concentration", brandSpendConcentrationRule},
+		InsightRuleFunc{"category_gap", categoryGapRule},
+		InsightRuleFunc{"color_category_concentration", colorCategoryConcentrationRule},
+	}
+}
+
+// RegisterInsightRule adds a rule to the insights engine
+func (s *AnalyticsService) RegisterInsightRule(rule InsightRule) {
+	s.insightRules = append(s.insightRules, rule)
+}
+
+// GetInsights evaluates every registered insight rule against the user's wardrobe
+func (s *AnalyticsService) GetInsights(userID string) ([]Insight, error) {
+	user, err := s.store.Users().Get(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.AllowAnalytics {
+		return nil, ErrAnalyticsDisabled
+	}
+
+	ctx := &InsightContext{
+		UserID:       userID,
+		Now:          time.Now(),
+		LatestPrices: map[string]models.PriceSnapshot{},
+	}
+
+	if ctx.Items, err = s.store.Items().ListAll(userID, false); err != nil {
+		return nil, fmt.Errorf("failed to get items: %w", err)
+	}
+
+	var wishlist []string
+	for _, item := range ctx.Items {
+		if item.Status == models.StatusWant {
+			wishlist = append(wishlist, item.ID)
+		}
+	}
+	if len(wishlist) > 0 {
+		latest, err := s.store.PriceSnapshots().LatestForItems(wishlist)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get latest prices: %w", err)
+		}
+		for _, snap := range latest {
+			ctx.LatestPrices[snap.ItemID] = snap
+		}
+	}
+
+	insights := []Insight{}
+	for _, rule := range s.insightRules {
+		for _, insight := range rule.Evaluate(ctx) {
+			insight.Rule = rule.Name()
+			if insight.Evidence == nil {
+				insight.Evidence = map[string]interface{}{}
+			}
+			insights = append(insights, insight)
+		}
+	}
+
+	return insights, nil
+}
+
+// wishlistOnSaleRule reports wishlist items priced below their original price,
+// or below the price they were saved at
+func wishlistOnSaleRule(ctx *InsightContext) []Insight {
+	var ids []string
+	savings := 0.0
+
+	for _, item := range ctx.Items {
+		if item.Status != models.StatusWant || item.Price == nil {
+			continue
+		}
+
+		reference := floatOrZero(item.OriginalPrice)
+		current := *item.Price
+		if snap, ok := ctx.LatestPrices[item.ID]; ok && snap.Currency == item.Currency && snap.Price < current {
+			reference = math.Max(reference, current)
+			current = snap.Price
+		}
+
+		if reference > current {
+			ids = append(ids, item.ID)
+			savings += reference - current
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return []Insight{{
+		Severity: InsightOpportunity,
+		Title:    "Wishlist items on sale",
+		Message:  fmt.Sprintf("%d wishlist %s now on sale", len(ids), plural(len(ids), "item is", "items are")),
+		Evidence: map[string]interface{}{"count": len(ids), "totalSavings": roundCents(savings)},
+		ItemIDs:  ids,
+	}}
+}
+
+// purchasedNotOwnedRule reports items marked purchased a month ago or more
+// that were never marked owned
+func purchasedNotOwnedRule(ctx *InsightContext) []Insight {
+	cutoff := ctx.Now.AddDate(0, 0, -30)
+
+	var ids []string
+	for _, item := range ctx.Items {
+		if item.Status != models.StatusPurchased {
+			continue
+		}
+		purchased := item.UpdatedAt
+		if item.PurchaseDate != nil {
+			purchased = *item.PurchaseDate
+		}
+		if purchased.Before(cutoff) {
+			ids = append(ids, item.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return []Insight{{
+		Severity: InsightWarning,
+		Title:    "Purchases never marked owned",
+		Message:  fmt.Sprintf("%d %s marked purchased over 30 days ago but never marked owned. Did they arrive?", len(ids), plural(len(ids), "item was", "items were")),
+		Evidence: map[string]interface{}{"count": len(ids), "olderThanDays": 30},
+		ItemIDs:  ids,
+	}}
+}
+
+// brandSpendConcentrationRule reports when one brand takes at least 40% of
+// spending in the user's main currency
+func brandSpendConcentrationRule(ctx *InsightContext) []Insight {
+	const threshold = 0.4
+	const minPurchases = 3
+
+	byCurrency := map[string]float64{}
+	for _, item := range ctx.Items {
+		if isPurchasedStatus(item.Status) {
+			byCurrency[item.Currency] += floatOrZero(item.Price)
+		}
+	}
+	currency := ""
+	for c, total := range byCurrency {
+		if currency == "" || total > byCurrency[currency] || (total == byCurrency[currency] && c < currency) {
+			currency = c
+		}
+	}
+
+	total := 0.0
+	purchases := 0
+	byBrand := map[string]float64{}
+	names := map[string]string{}
+	for _, item := range ctx.Items {
+		if !isPurchasedStatus(item.Status) || item.Currency != currency || item.Price == nil {
+			continue
+		}
+		total += *item.Price
+		purchases++
+		if brand := strings.TrimSpace(stringOrEmpty(item.Brand)); brand != "" {
+			key := strings.ToLower(brand)
+			byBrand[key] += *item.Price
+			names[key] = brand
+		}
+	}
+	if purchases < minPurchases || total <= 0 {
+		return nil
+	}
+
+	top := ""
+	for key, spent := range byBrand {
+		if top == "" || spent > byBrand[top] || (spent == byBrand[top] && key < top) {
+			top = key
+		}
+	}
+	if top == "" || byBrand[top]/total < threshold {
+		return nil
+	}
+
+	share := byBrand[top] / total
+	return []Insight{{
+		Severity: InsightInfo,
+		Title:    "Brand concentration",
+		Message:  fmt.Sprintf("%.0f%% of your spend goes to %s", share*100, names[top]),
+		Evidence: map[string]interface{}{
+			"brand":      names[top],
+			"share":      math.Round(share*1000) / 1000,
+			"brandSpend": roundCents(byBrand[top]),
+			"totalSpend": roundCents(total),
+			"currency":   currency,
+		},
+	}}
+}
+
+// categoryGapRule reports categories the user used to buy in but hasn't for six months
+func categoryGapRule(ctx *InsightContext) []Insight {
+	const months = 6
+	cutoff := ctx.Now.AddDate(0, -months, 0)
+
+	last := map[string]time.Time{}
+	for _, item := range ctx.Items {
+		if !isPurchasedStatus(item.Status) {
+			continue
+		}
+		purchased := item.CreatedAt
+		if item.PurchaseDate != nil {
+			purchased = *item.PurchaseDate
+		}
+		if purchased.After(last[item.Category]) {
+			last[item.Category] = purchased
+		}
+	}
+
+	var insights []Insight
+	for _, category := range models.ItemCategories {
+		at, ok := last[category]
+		if !ok || category == models.CategoryOther || !at.Before(cutoff) {
+			continue
+		}
+		insights = append(insights, Insight{
+			Severity: InsightInfo,
+			Title:    "Category gap",
+			Message:  fmt.Sprintf("No %s bought in %d months", category, months),
+			Evidence: map[string]interface{}{
+				"category":       category,
+				"lastPurchaseAt": at,
+				"monthsSince":    monthsBetween(at, ctx.Now),
+			},
+		})
+	}
+	return insights
+}
+
+// colorCategoryConcentrationRule reports colors the user owns many of in one category
+func colorCategoryConcentrationRule(ctx *InsightContext) []Insight {
+	const threshold = 5
+	const maxInsights = 3
+
+	type key struct{ color, category string }
+	counts := map[key][]string{}
+	for _, item := range ctx.Items {
+		if item.Status != models.StatusOwned && item.Status != models.StatusPurchased {
+			continue
+		}
+		color := strings.ToLower(strings.TrimSpace(stringOrEmpty(item.Color)))
+		if color == "" {
+			continue
+		}
+		k := key{color, item.Category}
+		counts[k] = append(counts[k], item.ID)
+	}
+
+	var keys []key
+	for k, ids := range counts {
+		if len(ids) >= threshold {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(counts[keys[i]]) != len(counts[keys[j]]) {
+			return len(counts[keys[i]]) > len(counts[keys[j]])
+		}
+		if keys[i].category != keys[j].category {
+			return keys[i].category < keys[j].category
+		}
+		return keys[i].color < keys[j].color
+	})
+	if len(keys) > maxInsights {
+		keys = keys[:maxInsights]
+	}
+
+	var insights []Insight
+	for _, k := range keys {
+		ids := counts[k]
+		insights = append(insights, Insight{
+			Severity: InsightInfo,
+			Title:    "Lots of the same",
+			Message:  fmt.Sprintf("You own %d %s %s", len(ids), k.color, k.category),
+			Evidence: map[string]interface{}{"color": k.color, "category": k.category, "count": len(ids)},
+			ItemIDs:  ids,
+		})
+	}
+	return insights
+}
+
+// isPurchasedStatus reports whether status means the item was bought
+func isPurchasedStatus(status string) bool {
+	for _, purchased := range purchasedStatuses {
+		if purchased == status {
+			return true
+		}
+	}
+	return false
+}
+
+// monthsBetween counts whole calendar months from a to b
+func monthsBetween(a, b time.Time) int {
+	months := (b.Year()-a.Year())*12 + int(b.Month()-a.Month())
+	if b.Day() < a.Day() {
+		months--
+	}
+	return months
+}
+
+// plural picks the singular or plural form for n
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return singular
+	}
+	return pluralForm
+}
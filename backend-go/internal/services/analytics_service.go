@@ -1,29 +1,365 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"digital-wardrobe-backend/internal/apperror"
 	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
 	"digital-wardrobe-backend/pkg/logger"
-
-	"gorm.io/gorm"
 )
 
+// preferredBrandCount is how many brands are reported as preferred
+const preferredBrandCount = 5
+
+// analyticsMonthFormat keys the monthly breakdowns
+const analyticsMonthFormat = "2006-01"
+
+// ErrAnalyticsDisabled is returned when the user has opted out of analytics
+var ErrAnalyticsDisabled = apperror.Forbidden("ANALYTICS_DISABLED", "Analytics are disabled for this account")
+
 // AnalyticsService handles analytics operations
 type AnalyticsService struct {
-	db     *gorm.DB
-	logger logger.Logger
+	store        repository.Store
+	logger       logger.Logger
+	insightRules []InsightRule
 }
 
 // NewAnalyticsService creates a new AnalyticsService
-func NewAnalyticsService(db *gorm.DB) *AnalyticsService {
+func NewAnalyticsService(store repository.Store) *AnalyticsService {
 	return &AnalyticsService{
-		db:     db,
-		logger: logger.New("analytics"),
+		store:        store,
+		logger:       logger.New("analytics"),
+		insightRules: DefaultInsightRules(),
 	}
 }
 
-// GetOverview gets analytics overview for a user
+// GetOverview gets analytics overview for a user. The stored analytics are
+// kept current as items change; they are only computed from scratch the first
+// time they are requested.
 func (s *AnalyticsService) GetOverview(userID string) (*models.UserAnalytics, error) {
-	var analytics models.UserAnalytics
-	err := s.db.Where("user_id = ?", userID).First(&analytics).Error
-	return &analytics, err
-} 
\ No newline at end of file
+	var analytics *models.UserAnalytics
+
+	err := s.store.Transaction(func(tx repository.Store) error {
+		user, err := lockAnalyticsUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if !user.AllowAnalytics {
+			if err := tx.Analytics().Delete(userID); err != nil {
+				return fmt.Errorf("failed to clear analytics: %w", err)
+			}
+			return ErrAnalyticsDisabled
+		}
+
+		existing, err := tx.Analytics().Get(userID)
+		if err == nil {
+			// Averages over elapsed months move with the calendar, not just with items
+			deriveAnalytics(existing, time.Now())
+			analytics = existing
+			return nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("failed to get analytics: %w", err)
+		}
+
+		if analytics, err = s.computeAnalytics(tx, userID); err != nil {
+			return err
+		}
+		if err := tx.Analytics().Create(analytics); err != nil {
+			return fmt.Errorf("failed to save analytics: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return analytics, nil
+}
+
+// computeAnalytics builds a user's analytics from all of their items
+func (s *AnalyticsService) computeAnalytics(tx repository.Store, userID string) (*models.UserAnalytics, error) {
+	analytics := newUserAnalytics(userID)
+
+	items, err := tx.Items().ListAll(userID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute analytics: %w", err)
+	}
+	for i := range items {
+		applyItemContribution(analytics, itemContributionOf(&items[i]), 1)
+	}
+
+	deriveAnalytics(analytics, time.Now())
+	s.logger.Infof("Analytics computed for user %s", userID)
+	return analytics, nil
+}
+
+// itemContribution is what a single item adds to its owner's analytics
+type itemContribution struct {
+	Counted       bool
+	Category      string
+	Brand         string
+	Color         string
+	Currency      string
+	HasPrice      bool
+	Price         float64
+	Value         float64 // price of items currently in the wardrobe
+	Spent         float64 // price of items that were bought
+	AddedMonth    string
+	PurchaseMonth string
+}
+
+// itemContributionOf describes an item's contribution. Archived items contribute nothing.
+func itemContributionOf(item *models.Item) itemContribution {
+	if item == nil || item.IsArchived() {
+		return itemContribution{}
+	}
+
+	c := itemContribution{
+		Counted:    true,
+		Category:   item.Category,
+		Brand:      strings.ToLower(strings.TrimSpace(stringOrEmpty(item.Brand))),
+		Color:      strings.ToLower(strings.TrimSpace(stringOrEmpty(item.Color))),
+		Currency:   item.Currency,
+		HasPrice:   item.Price != nil,
+		Price:      floatOrZero(item.Price),
+		AddedMonth: item.CreatedAt.Format(analyticsMonthFormat),
+	}
+
+	switch item.Status {
+	case models.StatusPurchased, models.StatusOwned:
+		c.Value = c.Price
+		c.Spent = c.Price
+	case models.StatusSold, models.StatusDonated:
+		c.Spent = c.Price
+	}
+
+	if c.Spent > 0 {
+		purchased := item.CreatedAt
+		if item.PurchaseDate != nil {
+			purchased = *item.PurchaseDate
+		}
+		c.PurchaseMonth = purchased.Format(analyticsMonthFormat)
+	}
+
+	return c
+}
+
+// updateItemAnalytics moves an item's contribution from before to after in
+// its owner's stored analytics. Creates and deletes pass a zero contribution
+// for the missing side.
+// Nothing is stored for users who have not requested analytics yet; their
+// first overview is computed from scratch.
+func updateItemAnalytics(tx repository.Store, userID string, before, after itemContribution) error {
+	if before == after {
+		return nil
+	}
+
+	user, err := lockAnalyticsUser(tx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.AllowAnalytics {
+		return tx.Analytics().Delete(userID)
+	}
+
+	analytics, err := tx.Analytics().Get(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get analytics: %w", err)
+	}
+
+	applyItemContribution(analytics, before, -1)
+	applyItemContribution(analytics, after, 1)
+	deriveAnalytics(analytics, time.Now())
+	analytics.LastCalculatedAt = time.Now()
+
+	if err := tx.Analytics().Save(analytics); err != nil {
+		return fmt.Errorf("failed to update analytics: %w", err)
+	}
+	return nil
+}
+
+// lockAnalyticsUser locks the user row so an item change and a first-time
+// computation cannot interleave
+func lockAnalyticsUser(tx repository.Store, userID string) (*models.User, error) {
+	user, err := tx.Users().GetForUpdate(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// newUserAnalytics returns empty analytics for a user
+func newUserAnalytics(userID string) *models.UserAnalytics {
+	return &models.UserAnalytics{
+		UserID:                 userID,
+		TotalValue:             models.JSONMap{},
+		TotalSpent:             models.JSONMap{},
+		AverageItemPrice:       models.JSONMap{},
+		CategoryBreakdown:      models.JSONMap{},
+		BrandBreakdown:         models.JSONMap{},
+		ColorBreakdown:         models.JSONMap{},
+		AverageMonthlySpending: models.JSONMap{},
+		MonthlyActivity:        models.JSONMap{},
+		MonthlySpending:        models.JSONMap{},
+		PricedItems:            models.JSONMap{},
+		PriceTotal:             models.JSONMap{},
+		PreferredBrands:        models.StringSlice{},
+		LastCalculatedAt:       time.Now(),
+	}
+}
+
+// applyItemContribution adds (sign 1) or removes (sign -1) a contribution.
+// Money is kept per currency, like the trends' spend, since amounts in
+// different currencies cannot be added up.
+func applyItemContribution(a *models.UserAnalytics, c itemContribution, sign int) {
+	if !c.Counted {
+		return
+	}
+	delta := float64(sign)
+
+	a.TotalItems += sign
+	a.TotalValue = addToBreakdown(a.TotalValue, c.Currency, delta*c.Value)
+	a.TotalSpent = addToBreakdown(a.TotalSpent, c.Currency, delta*c.Spent)
+	if c.HasPrice {
+		a.PricedItems = addToBreakdown(a.PricedItems, c.Currency, delta)
+		a.PriceTotal = addToBreakdown(a.PriceTotal, c.Currency, delta*c.Price)
+	}
+
+	a.CategoryBreakdown = addToBreakdown(a.CategoryBreakdown, c.Category, delta)
+	a.BrandBreakdown = addToBreakdown(a.BrandBreakdown, c.Brand, delta)
+	a.ColorBreakdown = addToBreakdown(a.ColorBreakdown, c.Color, delta)
+	a.MonthlyActivity = addToBreakdown(a.MonthlyActivity, c.AddedMonth, delta)
+	if c.Spent > 0 {
+		spending := addToBreakdown(breakdownMap(a.MonthlySpending[c.PurchaseMonth]), c.Currency, delta*c.Spent)
+		a.MonthlySpending = setBreakdown(a.MonthlySpending, c.PurchaseMonth, spending)
+	}
+}
+
+// deriveAnalytics recomputes the fields that follow from the running totals
+func deriveAnalytics(a *models.UserAnalytics, now time.Time) {
+	a.AverageItemPrice = models.JSONMap{}
+	for currency, count := range a.PricedItems {
+		if n := breakdownValue(count); n > 0 {
+			a.AverageItemPrice[currency] = roundCents(breakdownValue(a.PriceTotal[currency]) / n)
+		}
+	}
+
+	a.MostActiveMonth = ""
+	best := 0.0
+	for month, count := range a.MonthlyActivity {
+		n := breakdownValue(count)
+		if n > best || (n == best && month > a.MostActiveMonth) {
+			a.MostActiveMonth, best = month, n
+		}
+	}
+
+	brands := make([]string, 0, len(a.BrandBreakdown))
+	for brand := range a.BrandBreakdown {
+		brands = append(brands, brand)
+	}
+	sort.Slice(brands, func(i, j int) bool {
+		ci, cj := breakdownValue(a.BrandBreakdown[brands[i]]), breakdownValue(a.BrandBreakdown[brands[j]])
+		if ci != cj {
+			return ci > cj
+		}
+		return brands[i] < brands[j]
+	})
+	if len(brands) > preferredBrandCount {
+		brands = brands[:preferredBrandCount]
+	}
+	a.PreferredBrands = models.StringSlice(brands)
+
+	// Each currency is averaged from the first month it was spent in
+	firstMonths := map[string]string{}
+	for month, spending := range a.MonthlySpending {
+		for currency := range breakdownMap(spending) {
+			if first, ok := firstMonths[currency]; !ok || month < first {
+				firstMonths[currency] = month
+			}
+		}
+	}
+	a.AverageMonthlySpending = models.JSONMap{}
+	for currency, first := range firstMonths {
+		start, err := time.Parse(analyticsMonthFormat, first)
+		if err != nil {
+			continue
+		}
+		months := (now.Year()-start.Year())*12 + int(now.Month()-start.Month()) + 1
+		if months < 1 {
+			months = 1
+		}
+		a.AverageMonthlySpending[currency] = roundCents(breakdownValue(a.TotalSpent[currency]) / float64(months))
+	}
+}
+
+// addToBreakdown adjusts key by delta, dropping keys that reach zero
+func addToBreakdown(m models.JSONMap, key string, delta float64) models.JSONMap {
+	if m == nil {
+		m = models.JSONMap{}
+	}
+	if key == "" {
+		return m
+	}
+
+	value := roundCents(breakdownValue(m[key]) + delta)
+	if value <= 0 {
+		delete(m, key)
+	} else {
+		m[key] = value
+	}
+	return m
+}
+
+// breakdownValue reads a breakdown entry, which is float64 once loaded from JSON
+func breakdownValue(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	}
+	return 0
+}
+
+// breakdownMap reads a nested breakdown, such as one month's spending by currency
+func breakdownMap(v interface{}) models.JSONMap {
+	switch m := v.(type) {
+	case models.JSONMap:
+		return m
+	case map[string]interface{}:
+		return models.JSONMap(m)
+	}
+	return nil
+}
+
+// setBreakdown stores a nested breakdown under key, dropping it once empty
+func setBreakdown(m models.JSONMap, key string, nested models.JSONMap) models.JSONMap {
+	if m == nil {
+		m = models.JSONMap{}
+	}
+	if key == "" {
+		return m
+	}
+
+	if len(nested) == 0 {
+		delete(m, key)
+	} else {
+		m[key] = nested
+	}
+	return m
+}
+
+// roundCents rounds to two decimal places to keep running totals from drifting
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
+)
+
+// Trend bucket intervals
+const (
+	TrendIntervalDay   = "day"
+	TrendIntervalWeek  = "week"
+	TrendIntervalMonth = "month"
+)
+
+// maxTrendBuckets caps how many buckets a single trends request may return
+const maxTrendBuckets = 400
+
+// trendGroups are the supported groupBy parameters
+var trendGroups = map[string]bool{
+	repository.TrendGroupNone:     true,
+	repository.TrendGroupCategory: true,
+	repository.TrendGroupBrand:    true,
+}
+
+// purchasedStatuses are the statuses of items that were bought
+var purchasedStatuses = []string{models.StatusPurchased, models.StatusOwned, models.StatusSold, models.StatusDonated}
+
+// ErrInvalidTrendOptions is returned when trend parameters fail validation
+var ErrInvalidTrendOptions = apperror.Validation(apperror.CodeValidation, "invalid trend options")
+
+// TrendOptions selects the window, bucket size and breakdown of a trends request
+type TrendOptions struct {
+	Interval  string `form:"interval"`  // day, week or month (default month)
+	StartDate string `form:"startDate"` // YYYY-MM-DD or RFC3339
+	EndDate   string `form:"endDate"`   // inclusive when a date is given
+	GroupBy   string `form:"groupBy"`   // category or brand
+}
+
+// TrendPoint is one bucket of a trend series
+type TrendPoint struct {
+	Period         time.Time          `json:"period"`
+	ItemsAdded     int                `json:"itemsAdded"`
+	ItemsPurchased int                `json:"itemsPurchased"`
+	Spend          map[string]float64 `json:"spend"` // by currency
+}
+
+// TrendSeries is the series for one category or brand, or for all items
+type TrendSeries struct {
+	Key    string       `json:"key"`
+	Points []TrendPoint `json:"points"`
+}
+
+// Trends is a bucketed time series of wardrobe activity
+type Trends struct {
+	Interval   string        `json:"interval"`
+	Start      time.Time     `json:"start"`
+	End        time.Time     `json:"end"`
+	GroupBy    string        `json:"groupBy,omitempty"`
+	Currencies []string      `json:"currencies"`
+	Series     []TrendSeries `json:"series"`
+}
+
+// GetTrends returns items added, items purchased and spend per currency,
+// bucketed by day, week or month. Items added are bucketed by when they were
+// added; purchases by PurchaseDate, falling back to CreatedAt.
+func (s *AnalyticsService) GetTrends(userID string, opts TrendOptions) (*Trends, error) {
+	start, end, err := normalizeTrendOptions(&opts, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.store.Users().Get(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.AllowAnalytics {
+		return nil, ErrAnalyticsDisabled
+	}
+
+	query := repository.TrendQuery{
+		UserID:   userID,
+		Interval: opts.Interval,
+		GroupBy:  opts.GroupBy,
+		Start:    start,
+		End:      end,
+	}
+	added, err := s.store.Analytics().ItemsAdded(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get items added: %w", err)
+	}
+
+	query.Statuses = purchasedStatuses
+	purchased, err := s.store.Analytics().Purchases(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purchases: %w", err)
+	}
+
+	return buildTrends(opts, start, end, added, purchased), nil
+}
+
+// normalizeTrendOptions applies defaults, validates options and returns the
+// window aligned to bucket boundaries
+func normalizeTrendOptions(opts *TrendOptions, now time.Time) (time.Time, time.Time, error) {
+	if opts.Interval == "" {
+		opts.Interval = TrendIntervalMonth
+	}
+	if opts.Interval != TrendIntervalDay && opts.Interval != TrendIntervalWeek && opts.Interval != TrendIntervalMonth {
+		return time.Time{}, time.Time{}, ErrInvalidTrendOptions.Field("interval", "interval must be day, week or month")
+	}
+	if !trendGroups[opts.GroupBy] {
+		return time.Time{}, time.Time{}, ErrInvalidTrendOptions.Field("groupBy", "groupBy must be category or brand")
+	}
+
+	end := now
+	if opts.EndDate != "" {
+		t, dateOnly, err := parseDateParam(opts.EndDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidTrendOptions.Field("endDate", "invalid endDate")
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		end = t.UTC()
+	}
+
+	var start time.Time
+	if opts.StartDate != "" {
+		t, _, err := parseDateParam(opts.StartDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidTrendOptions.Field("startDate", "invalid startDate")
+		}
+		start = t.UTC()
+	} else {
+		switch opts.Interval {
+		case TrendIntervalDay:
+			start = end.AddDate(0, 0, -29)
+		case TrendIntervalWeek:
+			start = end.AddDate(0, 0, -7*11)
+		default:
+			start = end.AddDate(0, -11, 0)
+		}
+	}
+
+	start = truncateToInterval(start, opts.Interval)
+	end = nextInterval(truncateToInterval(end, opts.Interval), opts.Interval)
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, ErrInvalidTrendOptions.Field("startDate", "startDate must be before endDate")
+	}
+	if len(trendPeriods(start, end, opts.Interval)) > maxTrendBuckets {
+		return time.Time{}, time.Time{}, ErrInvalidTrendOptions.Field("interval", "at most %d %s buckets may be requested", maxTrendBuckets, opts.Interval)
+	}
+
+	return start, end, nil
+}
+
+// buildTrends turns grouped rows into dense series with a point for every bucket
+func buildTrends(opts TrendOptions, start, end time.Time, added, purchased []repository.TrendRow) *Trends {
+	periods := trendPeriods(start, end, opts.Interval)
+	index := make(map[time.Time]int, len(periods))
+	for i, p := range periods {
+		index[p] = i
+	}
+
+	series := map[string][]TrendPoint{}
+	pointsFor := func(key string) []TrendPoint {
+		points, ok := series[key]
+		if !ok {
+			points = make([]TrendPoint, len(periods))
+			for i, p := range periods {
+				points[i] = TrendPoint{Period: p, Spend: map[string]float64{}}
+			}
+			series[key] = points
+		}
+		return points
+	}
+
+	currencies := map[string]bool{}
+	for _, row := range added {
+		if i, ok := index[truncateToInterval(row.Period.UTC(), opts.Interval)]; ok {
+			pointsFor(row.GroupKey)[i].ItemsAdded += row.Items
+		}
+	}
+	for _, row := range purchased {
+		i, ok := index[truncateToInterval(row.Period.UTC(), opts.Interval)]
+		if !ok {
+			continue
+		}
+		point := &pointsFor(row.GroupKey)[i]
+		point.ItemsPurchased += row.Items
+		if row.Spend != 0 {
+			point.Spend[row.Currency] = roundCents(point.Spend[row.Currency] + row.Spend)
+			currencies[row.Currency] = true
+		}
+	}
+	if opts.GroupBy == "" {
+		pointsFor(repository.TrendAllKey)
+	}
+
+	trends := &Trends{
+		Interval:   opts.Interval,
+		Start:      start,
+		End:        end,
+		GroupBy:    opts.GroupBy,
+		Currencies: []string{},
+		Series:     []TrendSeries{},
+	}
+	for currency := range currencies {
+		trends.Currencies = append(trends.Currencies, currency)
+	}
+	sort.Strings(trends.Currencies)
+
+	for key, points := range series {
+		trends.Series = append(trends.Series, TrendSeries{Key: key, Points: points})
+	}
+	sort.Slice(trends.Series, func(i, j int) bool {
+		return trends.Series[i].Key < trends.Series[j].Key
+	})
+
+	return trends
+}
+
+// trendPeriods lists the bucket start times in [start, end)
+func trendPeriods(start, end time.Time, interval string) []time.Time {
+	var periods []time.Time
+	for p := start; p.Before(end); p = nextInterval(p, interval) {
+		periods = append(periods, p)
+		if len(periods) > maxTrendBuckets {
+			break
+		}
+	}
+	return periods
+}
+
+// truncateToInterval returns the start of the bucket containing t, matching
+// Postgres date_trunc (weeks start on Monday)
+func truncateToInterval(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch interval {
+	case TrendIntervalWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case TrendIntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// nextInterval returns the start of the bucket after the one starting at t
+func nextInterval(t time.Time, interval string) time.Time {
+	switch interval {
+	case TrendIntervalWeek:
+		return t.AddDate(0, 0, 7)
+	case TrendIntervalMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
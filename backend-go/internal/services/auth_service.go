@@ -1,34 +1,88 @@
 package services
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/ids"
 	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
 	"digital-wardrobe-backend/pkg/logger"
 
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/argon2"
-	"gorm.io/gorm"
+)
+
+// Token types carried in the "typ" claim
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned when a refresh token is malformed, expired or revoked
+	ErrInvalidRefreshToken = apperror.Unauthorized("INVALID_REFRESH_TOKEN", "Invalid or expired refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented.
+	// The whole session is revoked when this happens.
+	ErrRefreshTokenReused = apperror.Unauthorized("REFRESH_TOKEN_REUSED", "Refresh token has already been used; please log in again")
+	// ErrSessionNotFound is returned when a session does not exist or belongs to another user
+	ErrSessionNotFound = apperror.NotFound("SESSION_NOT_FOUND", "Session not found")
+	// ErrInvalidToken is returned when an access token is malformed, expired or its session was revoked
+	ErrInvalidToken = apperror.Unauthorized("INVALID_TOKEN", "Invalid or expired token")
+	// ErrEmailTaken is returned when registering an email that already has an account
+	ErrEmailTaken = apperror.Conflict("REGISTRATION_FAILED", "An account with this email already exists")
 )
 
 // AuthService handles authentication operations
 type AuthService struct {
-	db         *gorm.DB
-	jwtSecret  string
-	expiration time.Duration
-	logger     logger.Logger
+	store             repository.Store
+	jwtSecret         string
+	expiration        time.Duration
+	refreshExpiration time.Duration
+	google            *GoogleVerifier
+	oauthProviders    map[string]OAuthProvider
+	mailer            Mailer
+	appURL            string
+	verification      EmailTokenOptions
+	passwordReset     EmailTokenOptions
+	loginProtection   LoginProtectionOptions
+	mfaBox            *secretBox
+	hasher            *PasswordHasher
+	logger            logger.Logger
 }
 
-// NewAuthService creates a new AuthService
-func NewAuthService(db *gorm.DB, jwtSecret string, expiration time.Duration) *AuthService {
+// NewAuthService creates a new AuthService. expiration is the access token
+// lifetime; refreshExpiration is how long a session survives without a refresh.
+func NewAuthService(store repository.Store, jwtSecret string, expiration, refreshExpiration time.Duration) *AuthService {
+	// TOTP secrets are encrypted with the JWT secret until SetMFAEncryptionKey is called
+	mfaBox, _ := newSecretBox(jwtSecret)
 	return &AuthService{
-		db:         db,
-		jwtSecret:  jwtSecret,
-		expiration: expiration,
-		logger:     logger.New("auth"),
+		store:             store,
+		jwtSecret:         jwtSecret,
+		expiration:        expiration,
+		refreshExpiration: refreshExpiration,
+		mailer:            NewOutboxMailer("", "no-reply@localhost"),
+		appURL:            "http://localhost:3000",
+		verification:      EmailTokenOptions{TokenTTL: 24 * time.Hour, ResendInterval: time.Minute},
+		passwordReset:     EmailTokenOptions{TokenTTL: time.Hour, ResendInterval: time.Minute},
+		loginProtection: LoginProtectionOptions{
+			Limiter:            NewMemoryRateLimiter(),
+			Window:             15 * time.Minute,
+			IPLimit:            50,
+			EmailLimit:         10,
+			LockoutThreshold:   5,
+			LockoutDuration:    time.Minute,
+			MaxLockoutDuration: time.Hour,
+		},
+		mfaBox: mfaBox,
+		hasher: &PasswordHasher{params: DefaultArgon2Params},
+		logger: logger.New("auth"),
 	}
 }
 
@@ -46,25 +100,61 @@ type LoginCredentials struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// ClientInfo describes the device a session was started from
+type ClientInfo struct {
+	DeviceInfo string
+	IPAddress  string
+	UserAgent  string
+}
+
+// SessionInfo describes an active session for session management
+type SessionInfo struct {
+	ID           string    `json:"id"`
+	DeviceInfo   *string   `json:"deviceInfo"`
+	IPAddress    *string   `json:"ipAddress"`
+	UserAgent    *string   `json:"userAgent"`
+	CreatedAt    time.Time `json:"createdAt"`
+	LastActiveAt time.Time `json:"lastActiveAt"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	Current      bool      `json:"current"`
+}
+
+// RefreshRequest represents a token refresh request
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
 // AuthResult represents authentication result
 type AuthResult struct {
-	User  *models.SafeUser `json:"user"`
-	Token string           `json:"token"`
+	User             *models.SafeUser `json:"user"`
+	Token            string           `json:"token"`
+	ExpiresAt        time.Time        `json:"expiresAt"`
+	RefreshToken     string           `json:"refreshToken"`
+	RefreshExpiresAt time.Time        `json:"refreshExpiresAt"`
 }
 
 // JWTClaims represents JWT claims
 type JWTClaims struct {
-	UserID string `json:"userId"`
-	Email  string `json:"email"`
+	UserID    string `json:"userId"`
+	Email     string `json:"email"`
+	SessionID string `json:"sid,omitempty"`
+	TokenType string `json:"typ,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// refreshClaims are the claims of a refresh token. Each refresh token belongs
+// to one session; rotating it replaces the hash stored on the session.
+type refreshClaims struct {
+	SessionID string `json:"sid"`
+	TokenType string `json:"typ"`
 	jwt.RegisteredClaims
 }
 
 // Register registers a new user
-func (s *AuthService) Register(creds RegisterCredentials) (*AuthResult, error) {
+func (s *AuthService) Register(creds RegisterCredentials, client ClientInfo) (*AuthResult, error) {
 	// Check if user already exists
-	var existingUser models.User
-	if err := s.db.Where("email = ?", creds.Email).First(&existingUser).Error; err == nil {
-		return nil, fmt.Errorf("user with email %s already exists", creds.Email)
+	if _, err := s.store.Users().FindByEmail(creds.Email); err == nil {
+		return nil, ErrEmailTaken
 	}
 
 	// Hash password
@@ -82,67 +172,150 @@ func (s *AuthService) Register(creds RegisterCredentials) (*AuthResult, error) {
 		DisplayName:  &creds.FirstName, // Default to first name
 	}
 
-	if err := s.db.Create(&user).Error; err != nil {
+	if err := s.store.Users().Create(&user); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrEmailTaken
+		}
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	// Generate JWT token
-	token, err := s.generateToken(user.ID, user.Email)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+	// A failed send shouldn't fail registration; the user can ask for a resend
+	if err := s.sendVerificationEmail(context.Background(), &user); err != nil {
+		s.logger.Errorf("Failed to send verification email to %s: %v", user.Email, err)
 	}
 
 	// Create session
-	if err := s.createSession(user.ID, token); err != nil {
-		s.logger.Warnf("Failed to create session for user %s: %v", user.ID, err)
+	result, err := s.createSession(&user, client)
+	if err != nil {
+		return nil, err
 	}
 
 	s.logger.Infof("User registered successfully: %s", user.Email)
 
-	return &AuthResult{
-		User:  user.ToSafeUser(),
-		Token: token,
-	}, nil
+	return result, nil
 }
 
-// Login authenticates a user
-func (s *AuthService) Login(creds LoginCredentials) (*AuthResult, error) {
-	var user models.User
-	if err := s.db.Where("email = ?", creds.Email).First(&user).Error; err != nil {
-		return nil, fmt.Errorf("invalid credentials")
+// Login authenticates a user. Attempts are rate limited per IP address and
+// email, and repeated wrong passwords lock the account. Users with two-factor
+// authentication get ErrMFARequired carrying an MFAChallenge to redeem with
+// VerifyMFA instead of an AuthResult.
+func (s *AuthService) Login(ctx context.Context, creds LoginCredentials, client ClientInfo) (*AuthResult, error) {
+	// Checked before the password so throttled attempts don't cost a hash
+	if err := s.checkLoginRate(ctx, creds.Email, client); err != nil {
+		return nil, err
+	}
+
+	user, err := s.store.WithContext(ctx).Users().FindByEmail(creds.Email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.audit(auditLoginFailed, nil, client, models.JSONMap{"email": creds.Email, "reason": "unknown_email"})
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		s.audit(auditLoginLocked, &user.ID, client, models.JSONMap{"email": user.Email, "lockedUntil": user.LockedUntil})
+		return nil, accountLockedError(*user.LockedUntil)
 	}
 
 	// Verify password
 	if user.PasswordHash == nil {
-		return nil, fmt.Errorf("invalid credentials")
+		s.audit(auditLoginFailed, &user.ID, client, models.JSONMap{"email": user.Email, "reason": "no_password"})
+		return nil, ErrInvalidCredentials
 	}
 
-	if !s.verifyPassword(creds.Password, *user.PasswordHash) {
-		return nil, fmt.Errorf("invalid credentials")
+	ok, needsRehash := s.verifyPassword(creds.Password, *user.PasswordHash)
+	if !ok {
+		if err := s.recordFailedLogin(ctx, user.ID, client); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
 	}
 
-	// Generate JWT token
-	token, err := s.generateToken(user.ID, user.Email)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+	s.clearLockout(user)
+	s.resetLoginRate(ctx, creds.Email)
+	if needsRehash {
+		s.rehashPassword(user, creds.Password)
+	}
+
+	if user.TOTPEnabled {
+		return nil, s.mfaRequired(user)
 	}
 
 	// Create session
-	if err := s.createSession(user.ID, token); err != nil {
-		s.logger.Warnf("Failed to create session for user %s: %v", user.ID, err)
+	result, err := s.createSession(user, client)
+	if err != nil {
+		return nil, err
 	}
 
 	// Update last login
 	now := time.Now()
 	user.LastLoginAt = &now
-	s.db.Save(&user)
+	if err := s.store.Users().Update(user.ID, map[string]interface{}{"last_login_at": now}); err != nil {
+		s.logger.Warnf("Failed to record last login for %s: %v", user.ID, err)
+	}
 
 	s.logger.Infof("User logged in successfully: %s", user.Email)
 
-	return &AuthResult{
-		User:  user.ToSafeUser(),
-		Token: token,
-	}, nil
+	return result, nil
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. Each refresh token can be used once; presenting one that was already
+// rotated revokes the session, since the token has probably been stolen.
+func (s *AuthService) Refresh(refreshToken string, client ClientInfo) (*AuthResult, error) {
+	claims, err := s.parseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	var result *AuthResult
+	err = s.store.Transaction(func(tx repository.Store) error {
+		session, err := tx.Sessions().GetForUpdate(claims.SessionID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return fmt.Errorf("failed to get session: %w", err)
+		}
+
+		if !session.IsActive || !session.ExpiresAt.After(time.Now()) {
+			return ErrInvalidRefreshToken
+		}
+
+		// A validly signed token that is no longer current was rotated earlier
+		if session.RefreshToken == nil || *session.RefreshToken != hashToken(refreshToken) {
+			return ErrRefreshTokenReused
+		}
+
+		user, err := tx.Users().Get(session.UserID)
+		if errors.Is(err, repository.ErrNotFound) || (err == nil && !user.IsActive) {
+			return ErrInvalidRefreshToken
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		if result, err = s.issueTokens(tx, session, user); err != nil {
+			return err
+		}
+		if updates := clientInfoUpdates(client); len(updates) > 0 {
+			return tx.Sessions().Update(session.ID, updates)
+		}
+		return nil
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		if _, revokeErr := s.store.Sessions().Revoke(repository.SessionFilter{ID: claims.SessionID}); revokeErr != nil {
+			return nil, fmt.Errorf("failed to revoke session: %w", revokeErr)
+		}
+		s.logger.Warnf("Refresh token reuse detected; session %s revoked", claims.SessionID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
 // ValidateToken validates a JWT token
@@ -155,88 +328,185 @@ func (s *AuthService) ValidateToken(tokenString string) (*JWTClaims, error) {
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("invalid token: %w", err)
+		return nil, ErrInvalidToken.Wrap(err)
 	}
 
 	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		// Check if session exists
-		var session models.Session
-		if err := s.db.Where("token = ? AND is_active = ?", tokenString, true).First(&session).Error; err != nil {
-			return nil, fmt.Errorf("session not found")
+		// Tokens from before token types were added have none
+		if claims.TokenType != "" && claims.TokenType != tokenTypeAccess {
+			return nil, ErrInvalidToken
+		}
+
+		// Check if session is still active. Tokens issued before sessions
+		// carried an ID are matched by the token itself.
+		var err error
+		if claims.SessionID == "" {
+			_, err = s.store.Sessions().GetActiveByToken(tokenString)
+		} else if !ids.Valid(claims.SessionID) {
+			return nil, ErrInvalidToken
+		} else {
+			_, err = s.store.Sessions().GetActive(claims.SessionID)
+		}
+		if err != nil {
+			return nil, ErrInvalidToken
 		}
 
 		return claims, nil
 	}
 
-	return nil, fmt.Errorf("invalid token")
+	return nil, ErrInvalidToken
 }
 
-// GetUserByID gets a user by ID
-func (s *AuthService) GetUserByID(userID string) (*models.SafeUser, error) {
-	var user models.User
-	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
-		return nil, fmt.Errorf("user not found")
+// Logout revokes the session the request was authenticated with
+func (s *AuthService) Logout(userID, sessionID, token string) error {
+	filter := repository.SessionFilter{UserID: userID, ID: sessionID}
+	if sessionID == "" {
+		filter.Token = token
 	}
 
-	return user.ToSafeUser(), nil
+	if _, err := s.store.Sessions().Revoke(filter); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
 }
 
-// hashPassword hashes a password using Argon2
-func (s *AuthService) hashPassword(password string) (string, error) {
-	salt := make([]byte, 16)
-	if _, err := rand.Read(salt); err != nil {
-		return "", err
+// ListSessions lists the user's active sessions, most recently used first
+func (s *AuthService) ListSessions(userID, currentSessionID string) ([]SessionInfo, error) {
+	sessions, err := s.store.Sessions().ListActive(userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
 	}
 
-	hash := argon2.IDKey([]byte(password), salt, 1, 64*1024, 4, 32)
+	infos := make([]SessionInfo, len(sessions))
+	for i, session := range sessions {
+		infos[i] = SessionInfo{
+			ID:           session.ID,
+			DeviceInfo:   session.DeviceInfo,
+			IPAddress:    session.IPAddress,
+			UserAgent:    session.UserAgent,
+			CreatedAt:    session.CreatedAt,
+			LastActiveAt: session.UpdatedAt,
+			ExpiresAt:    session.ExpiresAt,
+			Current:      session.ID == currentSessionID,
+		}
+	}
+	return infos, nil
+}
 
-	// Combine salt and hash
-	combined := make([]byte, 16+32)
-	copy(combined[:16], salt)
-	copy(combined[16:], hash)
+// RevokeSession revokes one of the user's sessions
+func (s *AuthService) RevokeSession(userID, sessionID string) error {
+	if !ids.Valid(sessionID) {
+		return ErrSessionNotFound
+	}
+
+	revoked, err := s.store.Sessions().Revoke(repository.SessionFilter{ID: sessionID, UserID: userID})
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if revoked == 0 {
+		return ErrSessionNotFound
+	}
 
-	return base64.StdEncoding.EncodeToString(combined), nil
+	s.logger.Infof("Session %s of user %s revoked", sessionID, userID)
+	return nil
 }
 
-// verifyPassword verifies a password against a hash
-func (s *AuthService) verifyPassword(password, hash string) bool {
-	combined, err := base64.StdEncoding.DecodeString(hash)
+// RevokeOtherSessions revokes every session of the user except the current one
+// and returns how many were revoked
+func (s *AuthService) RevokeOtherSessions(userID, currentSessionID string) (int64, error) {
+	revoked, err := s.store.Sessions().Revoke(repository.SessionFilter{UserID: userID, ExceptID: currentSessionID})
 	if err != nil {
-		return false
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
 	}
 
-	if len(combined) != 48 {
-		return false
+	s.logger.Infof("%d other sessions of user %s revoked", revoked, userID)
+	return revoked, nil
+}
+
+// GetUserByID gets a user by ID
+func (s *AuthService) GetUserByID(userID string) (*models.SafeUser, error) {
+	user, err := s.store.Users().Get(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
 	}
 
-	salt := combined[:16]
-	storedHash := combined[16:]
+	return user.ToSafeUser(), nil
+}
 
-	computedHash := argon2.IDKey([]byte(password), salt, 1, 64*1024, 4, 32)
+// hashPassword hashes a password using Argon2id
+func (s *AuthService) hashPassword(password string) (string, error) {
+	return s.hasher.Hash(password)
+}
 
-	// Constant time comparison
-	if len(computedHash) != len(storedHash) {
-		return false
+// verifyPassword verifies a password against a hash. needsRehash reports a
+// match against a hash made with outdated parameters.
+func (s *AuthService) verifyPassword(password, hash string) (ok, needsRehash bool) {
+	ok, needsRehash, err := s.hasher.Verify(password, hash)
+	if err != nil {
+		s.logger.Warnf("Failed to verify password hash: %v", err)
+		return false, false
 	}
+	return ok, needsRehash
+}
 
-	for i := 0; i < len(computedHash); i++ {
-		if computedHash[i] != storedHash[i] {
-			return false
+// rehashPassword upgrades a user's stored hash to the current parameters
+func (s *AuthService) rehashPassword(user *models.User, password string) {
+	hashed, err := s.hashPassword(password)
+	if err != nil {
+		s.logger.Errorf("Failed to rehash password for %s: %v", user.ID, err)
+		return
+	}
+	// Only replace the hash that was verified, in case the password changed meanwhile
+	err = s.store.Transaction(func(tx repository.Store) error {
+		current, err := tx.Users().GetForUpdate(user.ID)
+		if err != nil {
+			return err
 		}
+		if current.PasswordHash == nil || *current.PasswordHash != *user.PasswordHash {
+			return nil
+		}
+		return tx.Users().Update(user.ID, map[string]interface{}{"password_hash": hashed})
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to store rehashed password for %s: %v", user.ID, err)
+		return
 	}
-
-	return true
+	user.PasswordHash = &hashed
 }
 
-// generateToken generates a JWT token
-func (s *AuthService) generateToken(userID, email string) (string, error) {
+// generateToken generates a JWT access token for a session
+func (s *AuthService) generateToken(userID, email, sessionID string, expiresAt time.Time) (string, error) {
+	now := time.Now()
 	claims := &JWTClaims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		SessionID: sessionID,
+		TokenType: tokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.expiration)),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(s.jwtSecret))
+}
+
+// generateRefreshToken generates a signed refresh token for a session
+func (s *AuthService) generateRefreshToken(sessionID string, expiresAt time.Time) (string, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	claims := &refreshClaims{
+		SessionID: sessionID,
+		TokenType: tokenTypeRefresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        base64.RawURLEncoding.EncodeToString(jti),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
@@ -244,14 +514,122 @@ func (s *AuthService) generateToken(userID, email string) (string, error) {
 	return token.SignedString([]byte(s.jwtSecret))
 }
 
-// createSession creates a new session
-func (s *AuthService) createSession(userID, token string) error {
-	session := models.Session{
-		UserID:    userID,
-		Token:     token,
-		ExpiresAt: time.Now().Add(s.expiration),
-		IsActive:  true,
+// parseRefreshToken verifies a refresh token's signature and expiry
+func (s *AuthService) parseRefreshToken(tokenString string) (*refreshClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &refreshClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(s.jwtSecret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*refreshClaims)
+	if !ok || !token.Valid || claims.TokenType != tokenTypeRefresh || !ids.Valid(claims.SessionID) {
+		return nil, fmt.Errorf("invalid refresh token")
 	}
+	return claims, nil
+}
+
+// createSession starts a new session for the user and issues its first tokens
+func (s *AuthService) createSession(user *models.User, client ClientInfo) (*AuthResult, error) {
+	var result *AuthResult
+	err := s.store.Transaction(func(tx repository.Store) error {
+		// The token columns are filled in once the session ID is known
+		placeholder := make([]byte, 16)
+		if _, err := rand.Read(placeholder); err != nil {
+			return err
+		}
 
-	return s.db.Create(&session).Error
+		session := models.Session{
+			UserID:     user.ID,
+			Token:      "pending_" + hex.EncodeToString(placeholder),
+			ExpiresAt:  time.Now().Add(s.refreshExpiration),
+			DeviceInfo: optionalString(client.DeviceInfo),
+			IPAddress:  optionalString(client.IPAddress),
+			UserAgent:  optionalString(client.UserAgent),
+			IsActive:   true,
+		}
+		if err := tx.Sessions().Create(&session); err != nil {
+			return fmt.Errorf("failed to create session: %w", err)
+		}
+
+		var err error
+		result, err = s.issueTokens(tx, &session, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// issueTokens issues a new access/refresh token pair for a session and
+// extends the session to the new refresh token's expiry
+func (s *AuthService) issueTokens(tx repository.Store, session *models.Session, user *models.User) (*AuthResult, error) {
+	now := time.Now()
+	accessExpires := now.Add(s.expiration)
+	refreshExpires := now.Add(s.refreshExpiration)
+
+	accessToken, err := s.generateToken(user.ID, user.Email, session.ID, accessExpires)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	refreshToken, err := s.generateRefreshToken(session.ID, refreshExpires)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	refreshHash := hashToken(refreshToken)
+	session.Token = accessToken
+	session.RefreshToken = &refreshHash
+	session.ExpiresAt = refreshExpires
+
+	if err := tx.Sessions().Update(session.ID, map[string]interface{}{
+		"token":         accessToken,
+		"refresh_token": refreshHash,
+		"expires_at":    refreshExpires,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	return &AuthResult{
+		User:             user.ToSafeUser(),
+		Token:            accessToken,
+		ExpiresAt:        accessExpires,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpires,
+	}, nil
+}
+
+// hashToken returns the hex SHA-256 of a token, as stored on sessions
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// clientInfoUpdates returns the session columns to refresh from client, skipping unknown values
+func clientInfoUpdates(client ClientInfo) map[string]interface{} {
+	updates := map[string]interface{}{}
+	if client.DeviceInfo != "" {
+		updates["device_info"] = client.DeviceInfo
+	}
+	if client.IPAddress != "" {
+		updates["ip_address"] = client.IPAddress
+	}
+	if client.UserAgent != "" {
+		updates["user_agent"] = client.UserAgent
+	}
+	return updates
+}
+
+// optionalString returns nil for an empty string
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
 }
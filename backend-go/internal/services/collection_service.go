@@ -1,29 +1,351 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/ids"
 	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
 	"digital-wardrobe-backend/pkg/logger"
+)
 
-	"gorm.io/gorm"
+var (
+	// ErrCollectionNotFound is returned when a collection does not exist or belongs to another user
+	ErrCollectionNotFound = apperror.NotFound("COLLECTION_NOT_FOUND", "Collection not found")
+	// ErrCollectionItemNotFound is returned when an item is not a member of the collection
+	ErrCollectionItemNotFound = apperror.NotFound("COLLECTION_ITEM_NOT_FOUND", "item is not in this collection")
+	// ErrDuplicateCollectionItem is returned when an item is already in the collection
+	ErrDuplicateCollectionItem = apperror.Conflict("DUPLICATE_COLLECTION_ITEM", "item is already in this collection")
+	// ErrInvalidCollection is returned when collection data fails validation
+	ErrInvalidCollection = apperror.Validation(apperror.CodeValidation, "invalid collection data")
 )
 
 // CollectionService handles collection operations
 type CollectionService struct {
-	db     *gorm.DB
-	logger logger.Logger
+	store                  repository.Store
+	requireVerifiedToShare bool
+	logger                 logger.Logger
 }
 
 // NewCollectionService creates a new CollectionService
-func NewCollectionService(db *gorm.DB) *CollectionService {
+func NewCollectionService(store repository.Store) *CollectionService {
 	return &CollectionService{
-		db:     db,
+		store:  store,
 		logger: logger.New("collection"),
 	}
 }
 
+// SetRequireVerifiedEmail sets whether only users with a verified email may make collections public
+func (s *CollectionService) SetRequireVerifiedEmail(required bool) {
+	s.requireVerifiedToShare = required
+}
+
 // GetCollections gets collections for a user
 func (s *CollectionService) GetCollections(userID string) ([]models.Collection, error) {
-	var collections []models.Collection
-	err := s.db.Where("user_id = ?", userID).Find(&collections).Error
-	return collections, err
-} 
\ No newline at end of file
+	return s.store.Collections().List(userID)
+}
+
+// GetCollection gets a collection owned by the user with its items in order
+func (s *CollectionService) GetCollection(userID, collectionID string) (*models.Collection, error) {
+	if !ids.Valid(collectionID) {
+		return nil, ErrCollectionNotFound
+	}
+
+	collection, err := s.store.Collections().GetWithItems(userID, collectionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	return collection, nil
+}
+
+// CreateCollection creates a new collection for the user
+func (s *CollectionService) CreateCollection(userID string, data models.CollectionData) (*models.Collection, error) {
+	if strings.TrimSpace(data.Name) == "" {
+		return nil, ErrInvalidCollection.Field("name", "name is required")
+	}
+
+	collection := models.Collection{UserID: userID}
+	applyCollectionData(&collection, data)
+	if err := s.checkCanShare(userID, false, collection.IsPublic); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Collections().Create(&collection); err != nil {
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	s.logger.Infof("Collection %s created for user %s", collection.ID, userID)
+	return &collection, nil
+}
+
+// UpdateCollection updates a collection owned by the user. Nil fields in data are left unchanged.
+func (s *CollectionService) UpdateCollection(userID, collectionID string, data models.CollectionData) (*models.Collection, error) {
+	if strings.TrimSpace(data.Name) == "" {
+		return nil, ErrInvalidCollection.Field("name", "name is required")
+	}
+
+	collection, err := s.findCollection(s.store, userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	wasPublic := collection.IsPublic
+	applyCollectionData(collection, data)
+	if err := s.checkCanShare(userID, wasPublic, collection.IsPublic); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Collections().Save(collection); err != nil {
+		return nil, fmt.Errorf("failed to update collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+// DeleteCollection deletes a collection owned by the user along with its memberships.
+// The items themselves are not deleted.
+func (s *CollectionService) DeleteCollection(userID, collectionID string) error {
+	return s.store.Transaction(func(tx repository.Store) error {
+		if _, err := s.lockCollection(tx, userID, collectionID); err != nil {
+			return err
+		}
+
+		if err := tx.Collections().Delete(collectionID); err != nil {
+			return fmt.Errorf("failed to delete collection: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// AddItemToCollection adds one of the user's items to one of their collections.
+// Items are appended unless data.Order is set, in which case later items shift down.
+func (s *CollectionService) AddItemToCollection(userID, collectionID string, data models.CollectionItemData) (*models.CollectionItem, error) {
+	if data.Order != nil && *data.Order < 0 {
+		return nil, ErrInvalidCollection.Field("order", "order must not be negative")
+	}
+
+	var membership models.CollectionItem
+	err := s.store.Transaction(func(tx repository.Store) error {
+		if _, err := s.lockCollection(tx, userID, collectionID); err != nil {
+			return err
+		}
+
+		if !ids.Valid(data.ItemID) {
+			return ErrItemNotFound
+		}
+		if _, err := tx.Items().Get(userID, data.ItemID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrItemNotFound
+			}
+			return fmt.Errorf("failed to get item: %w", err)
+		}
+
+		if _, err := s.findMembership(tx, collectionID, data.ItemID); err == nil {
+			return ErrDuplicateCollectionItem
+		} else if !errors.Is(err, ErrCollectionItemNotFound) {
+			return err
+		}
+
+		count, err := tx.Collections().CountMemberships(collectionID)
+		if err != nil {
+			return fmt.Errorf("failed to count collection items: %w", err)
+		}
+
+		order := int(count)
+		if data.Order != nil && *data.Order < order {
+			order = *data.Order
+			if err := tx.Collections().ShiftOrder(collectionID, order, 1); err != nil {
+				return fmt.Errorf("failed to shift collection items: %w", err)
+			}
+		}
+
+		membership = models.CollectionItem{
+			CollectionID: collectionID,
+			ItemID:       data.ItemID,
+			Order:        order,
+			Notes:        data.Notes,
+		}
+		if err := tx.Collections().AddMembership(&membership); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return ErrDuplicateCollectionItem
+			}
+			return fmt.Errorf("failed to add item to collection: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &membership, nil
+}
+
+// UpdateCollectionItem updates the notes on an item's membership in a collection
+func (s *CollectionService) UpdateCollectionItem(userID, collectionID, itemID string, data models.CollectionItemUpdate) (*models.CollectionItem, error) {
+	if _, err := s.findCollection(s.store, userID, collectionID); err != nil {
+		return nil, err
+	}
+
+	membership, err := s.findMembership(s.store, collectionID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	membership.Notes = data.Notes
+	if err := s.store.Collections().UpdateMembership(membership.ID, map[string]interface{}{"notes": data.Notes}); err != nil {
+		return nil, fmt.Errorf("failed to update collection item: %w", err)
+	}
+
+	return membership, nil
+}
+
+// RemoveItemFromCollection removes an item from a collection and closes the gap in ordering
+func (s *CollectionService) RemoveItemFromCollection(userID, collectionID, itemID string) error {
+	return s.store.Transaction(func(tx repository.Store) error {
+		if _, err := s.lockCollection(tx, userID, collectionID); err != nil {
+			return err
+		}
+
+		membership, err := s.findMembership(tx, collectionID, itemID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Collections().DeleteMembership(membership.ID); err != nil {
+			return fmt.Errorf("failed to remove item from collection: %w", err)
+		}
+
+		if err := tx.Collections().ShiftOrder(collectionID, membership.Order+1, -1); err != nil {
+			return fmt.Errorf("failed to shift collection items: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ReorderCollectionItems rewrites the order of every item in a collection in one transaction.
+// itemIDs must list each current member exactly once.
+func (s *CollectionService) ReorderCollectionItems(userID, collectionID string, itemIDs []string) (*models.Collection, error) {
+	err := s.store.Transaction(func(tx repository.Store) error {
+		if _, err := s.lockCollection(tx, userID, collectionID); err != nil {
+			return err
+		}
+
+		memberships, err := tx.Collections().ListMemberships(collectionID)
+		if err != nil {
+			return fmt.Errorf("failed to get collection items: %w", err)
+		}
+
+		if len(itemIDs) != len(memberships) {
+			return ErrInvalidCollection.Field("itemIds", "itemIds must list every item in the collection exactly once")
+		}
+
+		byItem := make(map[string]*models.CollectionItem, len(memberships))
+		for i := range memberships {
+			byItem[memberships[i].ItemID] = &memberships[i]
+		}
+
+		seen := make(map[string]bool, len(itemIDs))
+		for _, id := range itemIDs {
+			if byItem[id] == nil || seen[id] {
+				return ErrInvalidCollection.Field("itemIds", "itemIds must list every item in the collection exactly once")
+			}
+			seen[id] = true
+		}
+
+		for order, id := range itemIDs {
+			membership := byItem[id]
+			if membership.Order == order {
+				continue
+			}
+			if err := tx.Collections().UpdateMembership(membership.ID, map[string]interface{}{"order": order}); err != nil {
+				return fmt.Errorf("failed to reorder collection items: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCollection(userID, collectionID)
+}
+
+// findCollection loads a collection owned by the user
+func (s *CollectionService) findCollection(store repository.Store, userID, collectionID string) (*models.Collection, error) {
+	if !ids.Valid(collectionID) {
+		return nil, ErrCollectionNotFound
+	}
+	return collectionOrNotFound(store.Collections().Get(userID, collectionID))
+}
+
+// lockCollection loads a collection owned by the user and locks its row for the
+// rest of the transaction, so concurrent membership changes serialize
+func (s *CollectionService) lockCollection(tx repository.Store, userID, collectionID string) (*models.Collection, error) {
+	if !ids.Valid(collectionID) {
+		return nil, ErrCollectionNotFound
+	}
+	return collectionOrNotFound(tx.Collections().GetForUpdate(userID, collectionID))
+}
+
+// collectionOrNotFound maps a missing collection to ErrCollectionNotFound
+func collectionOrNotFound(collection *models.Collection, err error) (*models.Collection, error) {
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	return collection, nil
+}
+
+// findMembership loads an item's membership in a collection
+func (s *CollectionService) findMembership(store repository.Store, collectionID, itemID string) (*models.CollectionItem, error) {
+	if !ids.Valid(itemID) {
+		return nil, ErrCollectionItemNotFound
+	}
+
+	membership, err := store.Collections().GetMembership(collectionID, itemID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCollectionItemNotFound
+		}
+		return nil, fmt.Errorf("failed to get collection item: %w", err)
+	}
+	return membership, nil
+}
+
+// checkCanShare enforces the verified-email requirement when a collection becomes public
+func (s *CollectionService) checkCanShare(userID string, wasPublic, isPublic bool) error {
+	if !s.requireVerifiedToShare || wasPublic || !isPublic {
+		return nil
+	}
+	return requireVerifiedEmail(s.store, userID)
+}
+
+// applyCollectionData copies the provided fields of data onto collection
+func applyCollectionData(collection *models.Collection, data models.CollectionData) {
+	collection.Name = strings.TrimSpace(data.Name)
+
+	if data.Description != nil {
+		collection.Description = data.Description
+	}
+	if data.Color != nil {
+		collection.Color = data.Color
+	}
+	if data.Icon != nil {
+		collection.Icon = data.Icon
+	}
+	if data.IsPublic != nil {
+		collection.IsPublic = *data.IsPublic
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// domainLimiter spaces out requests to each domain and backs off
+// exponentially after failures
+type domainLimiter struct {
+	mu         sync.Mutex
+	interval   time.Duration
+	maxBackoff time.Duration
+	domains    map[string]*domainState
+}
+
+// domainState tracks when a domain may next be requested
+type domainState struct {
+	nextAllowed time.Time
+	failures    int
+}
+
+// newDomainLimiter creates a limiter allowing one request per interval per domain
+func newDomainLimiter(interval, maxBackoff time.Duration) *domainLimiter {
+	return &domainLimiter{
+		interval:   interval,
+		maxBackoff: maxBackoff,
+		domains:    make(map[string]*domainState),
+	}
+}
+
+// Reserve claims the next request slot for domain. It returns how long the
+// caller must wait before sending the request.
+func (l *domainLimiter) Reserve(domain string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := l.state(domain)
+	at := now
+	if state.nextAllowed.After(now) {
+		at = state.nextAllowed
+	}
+	state.nextAllowed = at.Add(l.interval)
+
+	return at.Sub(now)
+}
+
+// Delay reports how long until domain may next be requested, without reserving
+func (l *domainLimiter) Delay(domain string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if state, ok := l.domains[domain]; ok && state.nextAllowed.After(now) {
+		return state.nextAllowed.Sub(now)
+	}
+	return 0
+}
+
+// Success clears the failure count for domain
+func (l *domainLimiter) Success(domain string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.state(domain).failures = 0
+}
+
+// Failure records a failed request to domain and pushes its next slot back by
+// interval * 2^failures, capped at maxBackoff. A longer retryAfter from the
+// server takes precedence.
+func (l *domainLimiter) Failure(domain string, now time.Time, retryAfter time.Duration) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := l.state(domain)
+	state.failures++
+
+	backoff := l.interval
+	for i := 0; i < state.failures && backoff < l.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > l.maxBackoff {
+		backoff = l.maxBackoff
+	}
+	if retryAfter > backoff {
+		backoff = retryAfter
+	}
+
+	if next := now.Add(backoff); next.After(state.nextAllowed) {
+		state.nextAllowed = next
+	}
+
+	return backoff
+}
+
+// state returns the state for domain, creating it if needed. Callers must hold l.mu.
+func (l *domainLimiter) state(domain string) *domainState {
+	state, ok := l.domains[domain]
+	if !ok {
+		state = &domainState{}
+		l.domains[domain] = state
+	}
+	return state
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
+)
+
+var (
+	// ErrInvalidVerificationToken is returned when a verification token is unknown, used or expired
+	ErrInvalidVerificationToken = apperror.Validation("INVALID_VERIFICATION_TOKEN", "Invalid or expired verification token")
+	// ErrEmailAlreadyVerified is returned when resending to a verified address
+	ErrEmailAlreadyVerified = apperror.Conflict("EMAIL_ALREADY_VERIFIED", "Email is already verified")
+	// ErrEmailNotVerified is returned when a feature requires a verified email
+	ErrEmailNotVerified = apperror.Forbidden("EMAIL_NOT_VERIFIED", "Verify your email address to share collections")
+	// ErrThrottled is returned when an email is requested again too soon
+	ErrThrottled = apperror.RateLimited("TOO_MANY_REQUESTS", "too many requests")
+)
+
+// EmailTokenOptions configures emailed single-use tokens
+type EmailTokenOptions struct {
+	TokenTTL       time.Duration
+	ResendInterval time.Duration // minimum time between emails to one user
+}
+
+// VerifyEmailRequest represents an email verification request
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// SetMailer sets how account emails are sent. appURL is the web app base
+// URL that emailed links point at.
+func (s *AuthService) SetMailer(mailer Mailer, appURL string) {
+	s.mailer = mailer
+	s.appURL = strings.TrimRight(appURL, "/")
+}
+
+// SetEmailVerification configures verification tokens
+func (s *AuthService) SetEmailVerification(opts EmailTokenOptions) {
+	s.verification = opts
+}
+
+// VerifyEmail marks the email of the user a token was sent to as verified.
+// Tokens work once.
+func (s *AuthService) VerifyEmail(token string) (*models.SafeUser, error) {
+	var user *models.User
+	err := s.store.Transaction(func(tx repository.Store) error {
+		var err error
+		user, err = tx.Users().GetByTokenForUpdate(repository.EmailVerificationToken, hashToken(token), time.Now())
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrInvalidVerificationToken
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		if err := tx.Users().Update(user.ID, map[string]interface{}{
+			"is_email_verified":          true,
+			"email_verification_token":   nil,
+			"email_verification_expires": nil,
+		}); err != nil {
+			return fmt.Errorf("failed to verify email: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user.IsEmailVerified = true
+	user.EmailVerificationToken = nil
+	user.EmailVerificationExpires = nil
+	s.logger.Infof("Email verified for user %s", user.ID)
+	return user.ToSafeUser(), nil
+}
+
+// ResendVerificationEmail sends the user a new verification link, replacing
+// the previous one. Sends are throttled per user.
+func (s *AuthService) ResendVerificationEmail(ctx context.Context, userID string) error {
+	user, err := s.store.WithContext(ctx).Users().Get(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.IsEmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	if wait := s.verification.resendWait(user.EmailVerificationExpires); wait > 0 {
+		return ErrThrottled.Withf("try again in %s", wait.Round(time.Second)).WithRetryAfter(wait)
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// sendVerificationEmail issues a new verification token and emails it
+func (s *AuthService) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	token, err := randomURLToken(32)
+	if err != nil {
+		return err
+	}
+	tokenHash := hashToken(token)
+	expiresAt := time.Now().Add(s.verification.TokenTTL)
+
+	if err := s.store.WithContext(ctx).Users().Update(user.ID, map[string]interface{}{
+		"email_verification_token":   tokenHash,
+		"email_verification_expires": expiresAt,
+	}); err != nil {
+		return fmt.Errorf("failed to save verification token: %w", err)
+	}
+	user.EmailVerificationToken = &tokenHash
+	user.EmailVerificationExpires = &expiresAt
+
+	err = s.mailer.Send(ctx, MailMessage{
+		To:      user.Email,
+		Subject: "Verify your Digital Wardrobe email",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening this link:\n\n%s\n\nThe link expires in %s. If you didn't create an account, you can ignore this email.\n",
+			greetingName(user), s.appLink("/verify-email", token), s.verification.TokenTTL.Round(time.Minute)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+	return nil
+}
+
+// resendWait returns how long to wait before emailing a new token, given the
+// expiry of the current one. The last send time follows from that expiry.
+func (o EmailTokenOptions) resendWait(expiresAt *time.Time) time.Duration {
+	if expiresAt == nil {
+		return 0
+	}
+	sentAt := expiresAt.Add(-o.TokenTTL)
+	return o.ResendInterval - time.Since(sentAt)
+}
+
+// appLink returns a web app URL carrying a token
+func (s *AuthService) appLink(path, token string) string {
+	return s.appURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// greetingName returns the name to address a user by in emails
+func greetingName(user *models.User) string {
+	if user.FirstName != nil && *user.FirstName != "" {
+		return *user.FirstName
+	}
+	return user.Email
+}
+
+// requireVerifiedEmail returns ErrEmailNotVerified unless the user has verified their email
+func requireVerifiedEmail(store repository.Store, userID string) error {
+	user, err := store.Users().Get(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.IsEmailVerified {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
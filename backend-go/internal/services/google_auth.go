@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"digital-wardrobe-backend/internal/apperror"
+)
+
+// googleIssuers are the issuers Google signs ID tokens as
+var googleIssuers = []string{"accounts.google.com", "https://accounts.google.com"}
+
+var (
+	// ErrGoogleSignInDisabled is returned when no Google client ID is configured
+	ErrGoogleSignInDisabled = apperror.Unavailable("GOOGLE_SIGNIN_DISABLED", "Google sign-in is not available")
+	// ErrInvalidGoogleToken is returned when a Google ID token fails verification
+	ErrInvalidGoogleToken = apperror.Unauthorized("INVALID_GOOGLE_TOKEN", "Invalid Google credential")
+)
+
+// GoogleLoginRequest represents a Google sign-in request
+type GoogleLoginRequest struct {
+	// Credential is the ID token returned by Google Identity Services
+	Credential string `json:"credential" binding:"required"`
+}
+
+// GoogleVerifier verifies Google ID tokens against a key source
+type GoogleVerifier struct {
+	tokens *IDTokenVerifier
+}
+
+// NewGoogleVerifier creates a verifier accepting tokens issued to any of clientIDs
+func NewGoogleVerifier(keys KeySource, clientIDs []string) *GoogleVerifier {
+	return &GoogleVerifier{tokens: NewIDTokenVerifier(keys, googleIssuers, clientIDs)}
+}
+
+// Verify checks an ID token's signature, issuer, audience and expiry
+func (v *GoogleVerifier) Verify(ctx context.Context, idToken string) (*OAuthIdentity, error) {
+	identity, err := v.tokens.Verify(ctx, idToken, "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGoogleToken, err)
+	}
+	if identity.Email == "" {
+		return nil, fmt.Errorf("%w: missing email", ErrInvalidGoogleToken)
+	}
+	return identity, nil
+}
+
+// SetGoogleVerifier enables Google sign-in
+func (s *AuthService) SetGoogleVerifier(verifier *GoogleVerifier) {
+	s.google = verifier
+}
+
+// LoginWithGoogle signs a user in with a Google ID token from Google Identity
+// Services. Accounts are matched and linked as for the Google OAuth provider.
+func (s *AuthService) LoginWithGoogle(ctx context.Context, credential string, client ClientInfo) (*AuthResult, error) {
+	if s.google == nil {
+		return nil, ErrGoogleSignInDisabled
+	}
+
+	identity, err := s.google.Verify(ctx, credential)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.signInWithOAuth(OAuthProviderGoogle, identity, client)
+}
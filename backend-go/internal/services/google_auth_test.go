@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testGoogleClientID = "test-client.apps.googleusercontent.com"
+
+// testSigningKey is an RSA key published in a test JWKS
+type testSigningKey struct {
+	kid string
+	key *rsa.PrivateKey
+}
+
+func newTestSigningKey(t *testing.T, kid string) testSigningKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return testSigningKey{kid: kid, key: key}
+}
+
+// jwks renders the public halves of keys as a JWK set
+func jwks(keys ...testSigningKey) []byte {
+	set := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	for _, k := range keys {
+		set.Keys = append(set.Keys, jsonWebKey{
+			Kid: k.kid,
+			Kty: "RSA",
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(k.key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.key.E)).Bytes()),
+		})
+	}
+	data, _ := json.Marshal(set)
+	return data
+}
+
+// testJWKSServer serves a JWK set that tests can rotate
+type testJWKSServer struct {
+	mu       sync.Mutex
+	body     []byte
+	requests int32
+}
+
+func newTestJWKSServer(t *testing.T, keys ...testSigningKey) (*testJWKSServer, *httptest.Server) {
+	server := &testJWKSServer{body: jwks(keys...)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&server.requests, 1)
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		w.Write(server.body)
+	}))
+	t.Cleanup(srv.Close)
+	return server, srv
+}
+
+func (s *testJWKSServer) publish(keys ...testSigningKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body = jwks(keys...)
+}
+
+// googleToken signs an ID token; edit adjusts the default claims
+func googleToken(t *testing.T, key testSigningKey, edit func(*idTokenClaims)) string {
+	t.Helper()
+	now := time.Now()
+	claims := &idTokenClaims{
+		Email:         "Jane@Example.com",
+		EmailVerified: true,
+		Name:          "Jane Doe",
+		GivenName:     "Jane",
+		FamilyName:    "Doe",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "https://accounts.google.com",
+			Subject:   "1234567890",
+			Audience:  jwt.ClaimStrings{testGoogleClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+	if edit != nil {
+		edit(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = key.kid
+	signed, err := token.SignedString(key.key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func TestGoogleVerifierAcceptsValidToken(t *testing.T) {
+	key := newTestSigningKey(t, "key-1")
+	_, srv := newTestJWKSServer(t, key)
+	verifier := NewGoogleVerifier(NewRemoteKeySource(srv.URL, srv.Client(), time.Hour), []string{testGoogleClientID})
+
+	identity, err := verifier.Verify(context.Background(), googleToken(t, key, nil))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if identity.Subject != "1234567890" || identity.Email != "jane@example.com" || !identity.EmailVerified {
+		t.Errorf("unexpected identity: %+v", identity)
+	}
+	if identity.GivenName != "Jane" || identity.FamilyName != "Doe" {
+		t.Errorf("names not copied: %+v", identity)
+	}
+}
+
+func TestGoogleVerifierRejectsInvalidTokens(t *testing.T) {
+	key := newTestSigningKey(t, "key-1")
+	other := newTestSigningKey(t, "key-1")
+	_, srv := newTestJWKSServer(t, key)
+	verifier := NewGoogleVerifier(NewRemoteKeySource(srv.URL, srv.Client(), time.Hour), []string{testGoogleClientID})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"wrong audience", googleToken(t, key, func(c *idTokenClaims) {
+			c.Audience = jwt.ClaimStrings{"someone-else.apps.googleusercontent.com"}
+		})},
+		{"expired", googleToken(t, key, func(c *idTokenClaims) {
+			c.IssuedAt = jwt.NewNumericDate(time.Now().Add(-2 * time.Hour))
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		})},
+		{"no expiry", googleToken(t, key, func(c *idTokenClaims) {
+			c.ExpiresAt = nil
+		})},
+		{"wrong issuer", googleToken(t, key, func(c *idTokenClaims) {
+			c.Issuer = "https://evil.example.com"
+		})},
+		{"signed by another key", googleToken(t, other, nil)},
+		{"unknown key id", googleToken(t, testSigningKey{kid: "key-2", key: key.key}, nil)},
+		{"missing email", googleToken(t, key, func(c *idTokenClaims) {
+			c.Email = ""
+		})},
+		{"malformed", "not-a-jwt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(context.Background(), tt.token)
+			if !errors.Is(err, ErrInvalidGoogleToken) {
+				t.Fatalf("expected ErrInvalidGoogleToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGoogleVerifierRejectsHMACToken(t *testing.T) {
+	key := newTestSigningKey(t, "key-1")
+	_, srv := newTestJWKSServer(t, key)
+	verifier := NewGoogleVerifier(NewRemoteKeySource(srv.URL, srv.Client(), time.Hour), []string{testGoogleClientID})
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Issuer:    "https://accounts.google.com",
+		Subject:   "1234567890",
+		Audience:  jwt.ClaimStrings{testGoogleClientID},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	token.Header["kid"] = key.kid
+	signed, err := token.SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	if _, err := verifier.Verify(context.Background(), signed); !errors.Is(err, ErrInvalidGoogleToken) {
+		t.Fatalf("expected ErrInvalidGoogleToken, got %v", err)
+	}
+}
+
+func TestRemoteKeySourceCachesAndPicksUpRotation(t *testing.T) {
+	first := newTestSigningKey(t, "key-1")
+	second := newTestSigningKey(t, "key-2")
+	server, srv := newTestJWKSServer(t, first)
+	source := NewRemoteKeySource(srv.URL, srv.Client(), time.Hour)
+	verifier := NewGoogleVerifier(source, []string{testGoogleClientID})
+
+	for i := 0; i < 3; i++ {
+		if _, err := verifier.Verify(context.Background(), googleToken(t, first, nil)); err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&server.requests); n != 1 {
+		t.Fatalf("expected the key set to be fetched once, got %d fetches", n)
+	}
+
+	// A token signed by a newly published key forces a refetch, but only
+	// once the minimum refresh interval has passed
+	server.publish(first, second)
+	if _, err := verifier.Verify(context.Background(), googleToken(t, second, nil)); !errors.Is(err, ErrInvalidGoogleToken) {
+		t.Fatalf("expected the unknown key to be rejected within the refresh interval, got %v", err)
+	}
+
+	source.mu.Lock()
+	source.fetchedAt = source.fetchedAt.Add(-2 * minJWKSRefresh)
+	source.mu.Unlock()
+
+	if _, err := verifier.Verify(context.Background(), googleToken(t, second, nil)); err != nil {
+		t.Fatalf("Verify with rotated key: %v", err)
+	}
+	if n := atomic.LoadInt32(&server.requests); n != 2 {
+		t.Fatalf("expected one refetch after rotation, got %d fetches", n)
+	}
+}
+
+func TestFileKeySource(t *testing.T) {
+	key := newTestSigningKey(t, "key-1")
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks(key), 0o600); err != nil {
+		t.Fatalf("write jwks: %v", err)
+	}
+
+	source, err := NewFileKeySource(path)
+	if err != nil {
+		t.Fatalf("NewFileKeySource: %v", err)
+	}
+	verifier := NewGoogleVerifier(source, []string{"other-client", testGoogleClientID})
+
+	if _, err := verifier.Verify(context.Background(), googleToken(t, key, nil)); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if _, err := source.Key(context.Background(), "missing"); !errors.Is(err, ErrUnknownSigningKey) {
+		t.Fatalf("expected ErrUnknownSigningKey, got %v", err)
+	}
+}
+
+func TestLoginWithGoogleDisabled(t *testing.T) {
+	auth := NewAuthService(nil, "test-secret-that-is-long-enough-for-hs256", time.Minute, time.Hour)
+	if _, err := auth.LoginWithGoogle(context.Background(), "token", ClientInfo{}); !errors.Is(err, ErrGoogleSignInDisabled) {
+		t.Fatalf("expected ErrGoogleSignInDisabled, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidIDToken is returned when an OpenID Connect ID token fails verification
+var ErrInvalidIDToken = errors.New("invalid id token")
+
+// idTokenClaims are the standard claims of an OpenID Connect ID token
+type idTokenClaims struct {
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	Name          string   `json:"name"`
+	GivenName     string   `json:"given_name"`
+	FamilyName    string   `json:"family_name"`
+	Picture       string   `json:"picture"`
+	Nonce         string   `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// flexBool accepts JSON booleans and the "true"/"false" strings some providers send
+type flexBool bool
+
+// UnmarshalJSON implements json.Unmarshaler
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch t := v.(type) {
+	case bool:
+		*b = flexBool(t)
+	case string:
+		*b = flexBool(strings.EqualFold(t, "true"))
+	default:
+		*b = false
+	}
+	return nil
+}
+
+// IDTokenVerifier verifies RS256 ID tokens from one issuer
+type IDTokenVerifier struct {
+	keys      KeySource
+	issuers   []string
+	audiences []string
+	leeway    time.Duration
+}
+
+// NewIDTokenVerifier creates a verifier accepting tokens from any of issuers
+// addressed to any of audiences
+func NewIDTokenVerifier(keys KeySource, issuers, audiences []string) *IDTokenVerifier {
+	return &IDTokenVerifier{
+		keys:      keys,
+		issuers:   issuers,
+		audiences: audiences,
+		leeway:    30 * time.Second,
+	}
+}
+
+// Verify checks an ID token's signature, issuer, audience and expiry, and its
+// nonce when one is given
+func (v *IDTokenVerifier) Verify(ctx context.Context, rawToken, nonce string) (*OAuthIdentity, error) {
+	var claims idTokenClaims
+	token, err := jwt.ParseWithClaims(rawToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(v.leeway),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if !token.Valid {
+		return nil, ErrInvalidIDToken
+	}
+
+	if !containsString(v.issuers, claims.Issuer) {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, claims.Issuer)
+	}
+	audienceOK := false
+	for _, aud := range claims.Audience {
+		if containsString(v.audiences, aud) {
+			audienceOK = true
+			break
+		}
+	}
+	if !audienceOK {
+		return nil, fmt.Errorf("%w: unexpected audience", ErrInvalidIDToken)
+	}
+	if nonce != "" && claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	return &OAuthIdentity{
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(claims.Email),
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+		GivenName:     claims.GivenName,
+		FamilyName:    claims.FamilyName,
+		Picture:       claims.Picture,
+	}, nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode"
+
+	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
+)
+
+// ItemSearchOptions holds the query and filters for item search.
+// The fields mirror the webapp's SearchFilters type.
+type ItemSearchOptions struct {
+	Query     string   `form:"q"`
+	Category  string   `form:"category"`
+	Status    string   `form:"status"`
+	MinPrice  *float64 `form:"minPrice"`
+	MaxPrice  *float64 `form:"maxPrice"`
+	Brands    []string `form:"brands"`
+	Tags      []string `form:"tags"`
+	StartDate string   `form:"startDate"`
+	EndDate   string   `form:"endDate"`
+	Page      int      `form:"page"`
+	Limit     int      `form:"limit"`
+}
+
+// ItemSearchHighlights holds highlighted fragments for a search hit
+type ItemSearchHighlights struct {
+	Name    string `json:"name"`
+	Snippet string `json:"snippet"`
+}
+
+// ItemSearchResult is a single ranked search hit
+type ItemSearchResult struct {
+	Item       models.Item          `json:"item"`
+	Rank       float64              `json:"rank"`
+	Highlights ItemSearchHighlights `json:"highlights"`
+}
+
+// ItemSearchPage is a single page of search results
+type ItemSearchPage struct {
+	Data       []ItemSearchResult `json:"data"`
+	Pagination Pagination         `json:"pagination"`
+}
+
+// SearchItems runs a ranked full-text search over a user's active items.
+// The final query term is prefix-matched so results update as the user types.
+func (s *ItemService) SearchItems(userID string, opts ItemSearchOptions) (*ItemSearchPage, error) {
+	listOpts := ItemListOptions{
+		Category: opts.Category,
+		Status:   opts.Status,
+		MinPrice: opts.MinPrice,
+		MaxPrice: opts.MaxPrice,
+		Brands:   opts.Brands,
+		Page:     opts.Page,
+		Limit:    opts.Limit,
+	}
+	if err := normalizeItemListOptions(&listOpts); err != nil {
+		return nil, err
+	}
+
+	start, end, err := parseDateRange(opts.StartDate, opts.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ItemSearchPage{
+		Data: []ItemSearchResult{},
+		Pagination: Pagination{
+			Page:  listOpts.Page,
+			Limit: listOpts.Limit,
+		},
+	}
+
+	terms := searchTerms(opts.Query)
+	if len(terms) == 0 {
+		return result, nil
+	}
+
+	filter := itemFilter(userID, listOpts)
+	filter.Tags = splitListParam(opts.Tags)
+	filter.CreatedFrom = start
+	filter.CreatedBefore = end
+
+	hits, total, err := s.store.Items().Search(repository.ItemSearchQuery{
+		Filter: filter,
+		Terms:  terms,
+		Offset: (listOpts.Page - 1) * listOpts.Limit,
+		Limit:  listOpts.Limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search items: %w", err)
+	}
+	result.Pagination.Total = total
+	result.Pagination.TotalPages = totalPages(total, listOpts.Limit)
+	result.Pagination.HasMore = int64(listOpts.Page*listOpts.Limit) < total
+
+	for _, hit := range hits {
+		result.Data = append(result.Data, ItemSearchResult{
+			Item: hit.Item,
+			Rank: hit.Rank,
+			Highlights: ItemSearchHighlights{
+				Name:    markHighlights(hit.NameHighlight),
+				Snippet: markHighlights(hit.Snippet),
+			},
+		})
+	}
+
+	return result, nil
+}
+
+// highlightMarkup replaces the repository's highlight delimiters with <mark>
+var highlightMarkup = strings.NewReplacer(
+	repository.HighlightStart, "<mark>",
+	repository.HighlightStop, "</mark>",
+)
+
+// markHighlights HTML-escapes scraped item text and only then adds <mark>
+// tags, so the highlights are safe to render as HTML
+func markHighlights(text string) string {
+	return highlightMarkup.Replace(html.EscapeString(text))
+}
+
+// searchTerms splits free text into lower-cased search terms of letters and
+// digits only, so they are safe to use in a tsquery
+func searchTerms(input string) []string {
+	var terms []string
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		terms = append(terms, strings.ToLower(field))
+	}
+	return terms
+}
+
+// parseDateRange parses optional RFC 3339 or YYYY-MM-DD bounds. A date-only
+// end bound is inclusive of that whole day.
+func parseDateRange(startRaw, endRaw string) (*time.Time, *time.Time, error) {
+	var start, end *time.Time
+
+	if startRaw != "" {
+		t, _, err := parseDateParam(startRaw)
+		if err != nil {
+			return nil, nil, ErrInvalidItem.Field("startDate", "invalid startDate")
+		}
+		start = &t
+	}
+
+	if endRaw != "" {
+		t, dateOnly, err := parseDateParam(endRaw)
+		if err != nil {
+			return nil, nil, ErrInvalidItem.Field("endDate", "invalid endDate")
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		end = &t
+	}
+
+	if start != nil && end != nil && !start.Before(*end) {
+		return nil, nil, ErrInvalidItem.Field("startDate", "startDate must be before endDate")
+	}
+
+	return start, end, nil
+}
+
+// parseDateParam parses an RFC 3339 timestamp or a YYYY-MM-DD date
+func parseDateParam(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	return t, true, err
+}
@@ -1,29 +1,510 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/ids"
 	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
 	"digital-wardrobe-backend/pkg/logger"
+)
 
-	"gorm.io/gorm"
+var (
+	// ErrItemNotFound is returned when an item does not exist or belongs to another user
+	ErrItemNotFound = apperror.NotFound("ITEM_NOT_FOUND", "Item not found")
+	// ErrInvalidItem is returned when item data fails validation
+	ErrInvalidItem = apperror.Validation(apperror.CodeValidation, "invalid item data")
 )
 
 // ItemService handles item operations
 type ItemService struct {
-	db     *gorm.DB
+	store  repository.Store
 	logger logger.Logger
 }
 
 // NewItemService creates a new ItemService
-func NewItemService(db *gorm.DB) *ItemService {
+func NewItemService(store repository.Store) *ItemService {
 	return &ItemService{
-		db:     db,
+		store:  store,
 		logger: logger.New("item"),
 	}
 }
 
-// GetItems gets items for a user
-func (s *ItemService) GetItems(userID string) ([]models.Item, error) {
-	var items []models.Item
-	err := s.db.Where("user_id = ?", userID).Find(&items).Error
-	return items, err
-} 
\ No newline at end of file
+// ItemListOptions holds the filters, sort and paging for listing items.
+// The filters mirror the webapp's FilterOptions type.
+type ItemListOptions struct {
+	Category  string   `form:"category"`
+	Status    string   `form:"status"`
+	MinPrice  *float64 `form:"minPrice"`
+	MaxPrice  *float64 `form:"maxPrice"`
+	Brands    []string `form:"brands"`
+	Colors    []string `form:"colors"`
+	Sizes     []string `form:"sizes"`
+	OnSale    *bool    `form:"onSale"`
+	Archived  bool     `form:"archived"`
+	SortBy    string   `form:"sortBy"`
+	SortOrder string   `form:"sortOrder"`
+	Page      int      `form:"page"`
+	Limit     int      `form:"limit"`
+	Cursor    string   `form:"cursor"`
+}
+
+// ItemPage is a single page of items
+type ItemPage struct {
+	Data       []models.Item `json:"data"`
+	Pagination Pagination    `json:"pagination"`
+}
+
+// itemCursorDecoders parse a cursor value back into the Go type of each
+// supported sortBy option
+var itemCursorDecoders = map[string]func(raw json.RawMessage) (interface{}, error){
+	"dateAdded":   decodeCursorValue[time.Time],
+	"dateUpdated": decodeCursorValue[time.Time],
+	"price":       decodeCursorValue[float64],
+	"brand":       decodeCursorValue[string],
+	"category":    decodeCursorValue[string],
+	"status":      decodeCursorValue[string],
+	"likes":       decodeCursorValue[int],
+	"popularity":  decodeCursorValue[int],
+}
+
+// ListItems lists a user's items one page at a time. When opts.Cursor is set
+// the page continues after the cursor (keyset pagination); otherwise opts.Page
+// selects an offset page.
+func (s *ItemService) ListItems(userID string, opts ItemListOptions) (*ItemPage, error) {
+	if err := normalizeItemListOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	cursorKey := opts.SortBy + ":" + opts.SortOrder
+	filter := itemFilter(userID, opts)
+
+	total, err := s.store.Items().Count(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count items: %w", err)
+	}
+
+	page := repository.ItemPageQuery{
+		Filter: filter,
+		Sort:   repository.ItemSort{Field: opts.SortBy, Desc: opts.SortOrder == "desc"},
+		Limit:  opts.Limit + 1,
+	}
+
+	if opts.Cursor != "" {
+		cursor, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.SortBy != cursorKey {
+			return nil, cursorMismatchError(opts.SortBy)
+		}
+		value, err := itemCursorDecoders[opts.SortBy](cursor.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		page.After = &repository.ItemCursor{Value: value, ID: cursor.ID}
+	} else {
+		page.Offset = (opts.Page - 1) * opts.Limit
+	}
+
+	items, err := s.store.Items().List(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+
+	hasMore := len(items) > opts.Limit
+	if hasMore {
+		items = items[:opts.Limit]
+	}
+
+	result := &ItemPage{
+		Data: items,
+		Pagination: Pagination{
+			Page:       opts.Page,
+			Limit:      opts.Limit,
+			Total:      total,
+			TotalPages: totalPages(total, opts.Limit),
+			HasMore:    hasMore,
+		},
+	}
+
+	if hasMore {
+		last := &items[len(items)-1]
+		next, err := encodeCursor(cursorKey, repository.ItemSortValue(opts.SortBy, last), last.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cursor: %w", err)
+		}
+		result.Pagination.NextCursor = next
+	}
+
+	return result, nil
+}
+
+// GetItem gets a single item owned by the user
+func (s *ItemService) GetItem(userID, itemID string) (*models.Item, error) {
+	if !ids.Valid(itemID) {
+		return nil, ErrItemNotFound
+	}
+
+	item, err := s.store.Items().Get(userID, itemID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrItemNotFound
+		}
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+	return item, nil
+}
+
+// CreateItem creates a new item for the user
+func (s *ItemService) CreateItem(userID string, data models.ItemData) (*models.Item, error) {
+	if err := validateItemData(data); err != nil {
+		return nil, err
+	}
+
+	item := models.Item{
+		UserID:   userID,
+		Currency: "USD",
+		Status:   models.StatusWant,
+	}
+	applyItemData(&item, data)
+
+	err := s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Items().Create(&item); err != nil {
+			return fmt.Errorf("failed to create item: %w", err)
+		}
+		if err := recordItemPrice(tx, &item); err != nil {
+			return err
+		}
+		return updateItemAnalytics(tx, userID, itemContribution{}, itemContributionOf(&item))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Item %s created for user %s", item.ID, userID)
+	return &item, nil
+}
+
+// UpdateItem updates an item owned by the user. Nil fields in data are left unchanged.
+func (s *ItemService) UpdateItem(userID, itemID string, data models.ItemData) (*models.Item, error) {
+	if err := validateItemData(data); err != nil {
+		return nil, err
+	}
+
+	var item *models.Item
+	err := s.store.Transaction(func(tx repository.Store) error {
+		var err error
+		if item, err = lockItem(tx, userID, itemID); err != nil {
+			return err
+		}
+
+		before := itemContributionOf(item)
+		applyItemData(item, data)
+
+		if err := tx.Items().Save(item); err != nil {
+			return fmt.Errorf("failed to update item: %w", err)
+		}
+		if err := recordItemPrice(tx, item); err != nil {
+			return err
+		}
+		return updateItemAnalytics(tx, userID, before, itemContributionOf(item))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// ArchiveItem soft-archives an item owned by the user
+func (s *ItemService) ArchiveItem(userID, itemID string) (*models.Item, error) {
+	var item *models.Item
+	err := s.store.Transaction(func(tx repository.Store) error {
+		var err error
+		if item, err = lockItem(tx, userID, itemID); err != nil {
+			return err
+		}
+		if item.IsArchived() {
+			return nil
+		}
+
+		before := itemContributionOf(item)
+		now := time.Now()
+		item.ArchivedAt = &now
+
+		if err := tx.Items().Update(item.ID, map[string]interface{}{"archived_at": now}); err != nil {
+			return fmt.Errorf("failed to archive item: %w", err)
+		}
+		return updateItemAnalytics(tx, userID, before, itemContribution{})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// RestoreItem clears the archived state of an item owned by the user
+func (s *ItemService) RestoreItem(userID, itemID string) (*models.Item, error) {
+	var item *models.Item
+	err := s.store.Transaction(func(tx repository.Store) error {
+		var err error
+		if item, err = lockItem(tx, userID, itemID); err != nil {
+			return err
+		}
+		if !item.IsArchived() {
+			return nil
+		}
+
+		item.ArchivedAt = nil
+
+		if err := tx.Items().Update(item.ID, map[string]interface{}{"archived_at": nil}); err != nil {
+			return fmt.Errorf("failed to restore item: %w", err)
+		}
+		return updateItemAnalytics(tx, userID, itemContribution{}, itemContributionOf(item))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// DeleteItem permanently deletes an item owned by the user
+func (s *ItemService) DeleteItem(userID, itemID string) error {
+	err := s.store.Transaction(func(tx repository.Store) error {
+		item, err := lockItem(tx, userID, itemID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Items().Delete(item.ID); err != nil {
+			return fmt.Errorf("failed to delete item: %w", err)
+		}
+		return updateItemAnalytics(tx, userID, itemContributionOf(item), itemContribution{})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infof("Item %s deleted for user %s", itemID, userID)
+	return nil
+}
+
+// lockItem loads an item owned by the user and locks it for the rest of the transaction
+func lockItem(tx repository.Store, userID, itemID string) (*models.Item, error) {
+	if !ids.Valid(itemID) {
+		return nil, ErrItemNotFound
+	}
+
+	item, err := tx.Items().GetForUpdate(userID, itemID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrItemNotFound
+		}
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+	return item, nil
+}
+
+// normalizeItemListOptions applies defaults and validates list options
+func normalizeItemListOptions(opts *ItemListOptions) error {
+	if opts.Category == "all" {
+		opts.Category = ""
+	}
+	if opts.Category != "" && !models.IsValidCategory(opts.Category) {
+		return ErrInvalidItem.Field("category", "category must be one of %s", strings.Join(models.ItemCategories, ", "))
+	}
+
+	if opts.Status == "all" {
+		opts.Status = ""
+	}
+	if opts.Status != "" && !models.IsValidStatus(opts.Status) {
+		return ErrInvalidItem.Field("status", "status must be one of %s", strings.Join(models.ItemStatuses, ", "))
+	}
+
+	if opts.MinPrice != nil && opts.MaxPrice != nil && *opts.MinPrice > *opts.MaxPrice {
+		return ErrInvalidItem.Field("minPrice", "minPrice must not exceed maxPrice")
+	}
+
+	if opts.SortBy == "" {
+		opts.SortBy = "dateAdded"
+	}
+	if _, ok := itemCursorDecoders[opts.SortBy]; !ok {
+		return ErrInvalidItem.Field("sortBy", "unsupported sortBy %q", opts.SortBy)
+	}
+
+	opts.SortOrder = strings.ToLower(opts.SortOrder)
+	if opts.SortOrder == "" {
+		opts.SortOrder = "desc"
+	}
+	if opts.SortOrder != "asc" && opts.SortOrder != "desc" {
+		return ErrInvalidItem.Field("sortOrder", "sortOrder must be asc or desc")
+	}
+
+	opts.Brands = splitListParam(opts.Brands)
+	opts.Colors = splitListParam(opts.Colors)
+	opts.Sizes = splitListParam(opts.Sizes)
+
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	opts.Limit = normalizeLimit(opts.Limit)
+
+	return nil
+}
+
+// itemFilter turns the list filters into a repository filter
+func itemFilter(userID string, opts ItemListOptions) repository.ItemFilter {
+	return repository.ItemFilter{
+		UserID:   userID,
+		Archived: opts.Archived,
+		Category: opts.Category,
+		Status:   opts.Status,
+		MinPrice: opts.MinPrice,
+		MaxPrice: opts.MaxPrice,
+		Brands:   opts.Brands,
+		Colors:   opts.Colors,
+		Sizes:    opts.Sizes,
+		OnSale:   opts.OnSale,
+	}
+}
+
+// validateItemData validates item data against the documented enums
+func validateItemData(data models.ItemData) error {
+	if strings.TrimSpace(data.Name) == "" {
+		return ErrInvalidItem.Field("name", "name is required")
+	}
+
+	if !models.IsValidCategory(data.Category) {
+		return ErrInvalidItem.Field("category", "category must be one of %s", strings.Join(models.ItemCategories, ", "))
+	}
+
+	if data.Status != nil && !models.IsValidStatus(*data.Status) {
+		return ErrInvalidItem.Field("status", "status must be one of %s", strings.Join(models.ItemStatuses, ", "))
+	}
+
+	if data.Price != nil && *data.Price < 0 {
+		return ErrInvalidItem.Field("price", "price must not be negative")
+	}
+
+	if data.OriginalPrice != nil && *data.OriginalPrice < 0 {
+		return ErrInvalidItem.Field("originalPrice", "originalPrice must not be negative")
+	}
+
+	return nil
+}
+
+// applyItemData copies the provided fields of data onto item
+func applyItemData(item *models.Item, data models.ItemData) {
+	item.Name = strings.TrimSpace(data.Name)
+	item.Category = data.Category
+
+	if data.Brand != nil {
+		item.Brand = data.Brand
+	}
+	if data.Description != nil {
+		item.Description = data.Description
+	}
+	if data.Subcategory != nil {
+		item.Subcategory = data.Subcategory
+	}
+	if data.Price != nil {
+		item.Price = data.Price
+	}
+	if data.OriginalPrice != nil {
+		item.OriginalPrice = data.OriginalPrice
+	}
+	if data.Currency != nil && *data.Currency != "" {
+		item.Currency = strings.ToUpper(*data.Currency)
+	}
+	if data.SKU != nil {
+		item.SKU = data.SKU
+	}
+	if data.Size != nil {
+		item.Size = data.Size
+	}
+	if data.Color != nil {
+		item.Color = data.Color
+	}
+	if data.Material != nil {
+		item.Material = data.Material
+	}
+	if data.CareInstructions != nil {
+		item.CareInstructions = data.CareInstructions
+	}
+	if data.Status != nil {
+		item.Status = *data.Status
+	}
+	if data.PurchaseDate != nil {
+		item.PurchaseDate = data.PurchaseDate
+	}
+	if data.PurchaseLocation != nil {
+		item.PurchaseLocation = data.PurchaseLocation
+	}
+	if data.Images != nil {
+		item.Images = data.Images
+	}
+	if data.PrimaryImage != nil {
+		item.PrimaryImage = data.PrimaryImage
+	}
+	if data.OriginalURL != nil {
+		item.OriginalURL = data.OriginalURL
+	}
+	if data.AffiliateURL != nil {
+		item.AffiliateURL = data.AffiliateURL
+	}
+	if data.Tags != nil {
+		item.Tags = data.Tags
+	}
+	if data.Notes != nil {
+		item.Notes = data.Notes
+	}
+	if data.IsPublic != nil {
+		item.IsPublic = *data.IsPublic
+	}
+}
+
+// decodeCursorValue unmarshals a cursor sort value into T
+func decodeCursorValue[T any](raw json.RawMessage) (interface{}, error) {
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// splitListParam flattens repeated and comma-separated query values
+func splitListParam(values []string) []string {
+	var out []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+// floatOrZero dereferences f, returning 0 when nil
+func floatOrZero(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+	return *f
+}
+
+// stringOrEmpty dereferences s, returning "" when nil
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownSigningKey is returned when a key set has no key with the requested ID
+var ErrUnknownSigningKey = errors.New("unknown signing key")
+
+// minJWKSRefresh limits how often an unknown key ID can force a refetch
+const minJWKSRefresh = time.Minute
+
+// KeySource looks up token verification keys by key ID
+type KeySource interface {
+	Key(ctx context.Context, kid string) (*rsa.PublicKey, error)
+}
+
+// jsonWebKey is an RSA key in a JWK set
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// parseJWKS parses a JWK set, keeping the RSA signing keys
+func parseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus for key %s: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent for key %s: %w", k.Kid, err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid exponent for key %s", k.Kid)
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS contains no RSA signing keys")
+	}
+	return keys, nil
+}
+
+// StaticKeySource serves keys from a JWK set loaded once, such as a local file
+type StaticKeySource struct {
+	keys map[string]*rsa.PublicKey
+}
+
+// NewFileKeySource loads a JWK set from a file
+func NewFileKeySource(path string) (*StaticKeySource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+	return &StaticKeySource{keys: keys}, nil
+}
+
+// Key returns the key with the given ID
+func (s *StaticKeySource) Key(_ context.Context, kid string) (*rsa.PublicKey, error) {
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSigningKey, kid)
+	}
+	return key, nil
+}
+
+// RemoteKeySource serves keys from a JWKS URL. Keys are cached for the
+// response's max-age (or ttl when none is given) and refetched early when a
+// token names a key that isn't cached, so key rotation is picked up.
+type RemoteKeySource struct {
+	url    string
+	client *http.Client
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	expiresAt time.Time
+	fetchedAt time.Time
+}
+
+// NewRemoteKeySource creates a key source for a JWKS URL
+func NewRemoteKeySource(url string, client *http.Client, ttl time.Duration) *RemoteKeySource {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &RemoteKeySource{url: url, client: client, ttl: ttl}
+}
+
+// Key returns the key with the given ID, fetching the key set when needed
+func (s *RemoteKeySource) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if key, ok := s.keys[kid]; ok && now.Before(s.expiresAt) {
+		return key, nil
+	}
+	if s.keys != nil && now.Before(s.expiresAt) && now.Sub(s.fetchedAt) < minJWKSRefresh {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSigningKey, kid)
+	}
+
+	if err := s.fetch(ctx, now); err != nil {
+		return nil, err
+	}
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSigningKey, kid)
+	}
+	return key, nil
+}
+
+// fetch downloads the key set; the caller holds s.mu
+func (s *RemoteKeySource) fetch(ctx context.Context, now time.Time) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build JWKS request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var body json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("failed to read JWKS: %w", err)
+	}
+	keys, err := parseJWKS(body)
+	if err != nil {
+		return err
+	}
+
+	ttl := s.ttl
+	if maxAge, ok := cacheMaxAge(resp.Header.Get("Cache-Control")); ok {
+		ttl = maxAge
+	}
+
+	s.keys = keys
+	s.fetchedAt = now
+	s.expiresAt = now.Add(ttl)
+	return nil
+}
+
+// cacheMaxAge reads max-age from a Cache-Control header
+func cacheMaxAge(header string) (time.Duration, bool) {
+	for _, directive := range strings.Split(header, ",") {
+		value, ok := strings.CutPrefix(strings.TrimSpace(directive), "max-age=")
+		if !ok {
+			continue
+		}
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	return 0, false
+}
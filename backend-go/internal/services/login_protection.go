@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
+)
+
+// Audit log actions for sign-in attempts
+const (
+	auditLoginFailed     = "login_failed"
+	auditLoginLocked     = "login_locked"
+	auditAccountLocked   = "account_locked"
+	auditAccountUnlocked = "account_unlocked"
+)
+
+var (
+	// ErrInvalidCredentials is returned when an email and password don't match
+	ErrInvalidCredentials = apperror.Unauthorized("INVALID_CREDENTIALS", "Invalid credentials")
+	// ErrAccountLocked is returned when signing in to a locked account
+	ErrAccountLocked = apperror.Locked("ACCOUNT_LOCKED", "account is locked after too many failed sign-ins")
+	// ErrTooManySignIns is returned when sign-in attempts are rate limited
+	ErrTooManySignIns = apperror.RateLimited("TOO_MANY_REQUESTS", "Too many sign-in attempts; please try again later")
+	// ErrInvalidUnlockToken is returned when an unlock token is unknown, used or the lock has expired
+	ErrInvalidUnlockToken = apperror.Validation("INVALID_UNLOCK_TOKEN", "Invalid or expired unlock token")
+)
+
+// accountLockedError reports that an account is locked until the given time
+func accountLockedError(until time.Time) error {
+	wait := time.Until(until)
+	return ErrAccountLocked.
+		Withf("try again in %s or use the link we emailed you", wait.Round(time.Second)).
+		WithRetryAfter(wait)
+}
+
+// LoginProtectionOptions configures sign-in rate limits and lockout. A limit
+// or threshold of zero disables that check.
+type LoginProtectionOptions struct {
+	Limiter    RateLimiter
+	Window     time.Duration
+	IPLimit    int // attempts per window from one IP address
+	EmailLimit int // attempts per window against one email
+
+	LockoutThreshold   int           // consecutive failures that lock the account
+	LockoutDuration    time.Duration // length of the first lock; each further lock doubles it
+	MaxLockoutDuration time.Duration
+}
+
+// UnlockAccountRequest represents an account unlock request
+type UnlockAccountRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// SetLoginProtection configures sign-in rate limits and lockout
+func (s *AuthService) SetLoginProtection(opts LoginProtectionOptions) {
+	s.loginProtection = opts
+}
+
+// checkLoginRate records a sign-in attempt against the per-IP and per-email
+// limits. Limiter failures are logged and let the attempt through.
+func (s *AuthService) checkLoginRate(ctx context.Context, email string, client ClientInfo) error {
+	opts := s.loginProtection
+	if opts.Limiter == nil {
+		return nil
+	}
+
+	checks := []struct {
+		key   string
+		limit int
+	}{
+		{"login:ip:" + client.IPAddress, opts.IPLimit},
+		{"login:email:" + strings.ToLower(email), opts.EmailLimit},
+	}
+	if client.IPAddress == "" {
+		checks = checks[1:]
+	}
+	for _, check := range checks {
+		if check.limit <= 0 {
+			continue
+		}
+		allowed, retryAfter, err := opts.Limiter.Allow(ctx, check.key, check.limit, opts.Window)
+		if err != nil {
+			s.logger.Errorf("Login rate limiter unavailable: %v", err)
+			return nil
+		}
+		if !allowed {
+			s.logger.Warnf("Login attempt throttled for %s", check.key)
+			return ErrTooManySignIns.WithRetryAfter(retryAfter)
+		}
+	}
+	return nil
+}
+
+// resetLoginRate clears the per-email attempts after a successful sign-in
+func (s *AuthService) resetLoginRate(ctx context.Context, email string) {
+	if s.loginProtection.Limiter == nil {
+		return
+	}
+	if err := s.loginProtection.Limiter.Reset(ctx, "login:email:"+strings.ToLower(email)); err != nil {
+		s.logger.Errorf("Failed to reset login rate limit: %v", err)
+	}
+}
+
+// recordFailedLogin counts a failed password for the user and locks the
+// account once the threshold is reached. It returns ErrAccountLocked when
+// this failure locked the account.
+func (s *AuthService) recordFailedLogin(ctx context.Context, userID string, client ClientInfo) error {
+	opts := s.loginProtection
+	var user *models.User
+	var unlockToken string
+	err := s.store.WithContext(ctx).Transaction(func(tx repository.Store) error {
+		var err error
+		if user, err = tx.Users().GetForUpdate(userID); err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		user.FailedLoginAttempts++
+		updates := map[string]interface{}{"failed_login_attempts": user.FailedLoginAttempts}
+		if opts.LockoutThreshold > 0 && user.FailedLoginAttempts >= opts.LockoutThreshold {
+			token, err := randomURLToken(32)
+			if err != nil {
+				return err
+			}
+			unlockToken = token
+
+			user.LockoutCount++
+			lockedUntil := time.Now().Add(lockoutDuration(opts, user.LockoutCount))
+			user.LockedUntil = &lockedUntil
+			updates = map[string]interface{}{
+				"failed_login_attempts": 0,
+				"lockout_count":         user.LockoutCount,
+				"locked_until":          lockedUntil,
+				"unlock_token":          hashToken(token),
+			}
+		}
+
+		if err := tx.Users().Update(user.ID, updates); err != nil {
+			return fmt.Errorf("failed to record failed login: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.audit(auditLoginFailed, &user.ID, client, models.JSONMap{"email": user.Email, "reason": "wrong_password"})
+	if unlockToken == "" {
+		return nil
+	}
+
+	s.logger.Warnf("Account %s locked until %s", user.ID, user.LockedUntil.Format(time.RFC3339))
+	s.audit(auditAccountLocked, &user.ID, client, models.JSONMap{
+		"email":        user.Email,
+		"lockoutCount": user.LockoutCount,
+		"lockedUntil":  user.LockedUntil,
+	})
+	if err := s.sendUnlockEmail(ctx, user, unlockToken); err != nil {
+		s.logger.Errorf("Failed to send unlock email to %s: %v", user.Email, err)
+	}
+	return accountLockedError(*user.LockedUntil)
+}
+
+// lockoutDuration returns how long the nth lock lasts
+func lockoutDuration(opts LoginProtectionOptions, n int) time.Duration {
+	d := opts.LockoutDuration
+	for i := 1; i < n && i < 16; i++ {
+		d *= 2
+		if opts.MaxLockoutDuration > 0 && d >= opts.MaxLockoutDuration {
+			return opts.MaxLockoutDuration
+		}
+	}
+	return d
+}
+
+// sendUnlockEmail emails the link that lifts a lock early
+func (s *AuthService) sendUnlockEmail(ctx context.Context, user *models.User, token string) error {
+	return s.mailer.Send(ctx, MailMessage{
+		To:      user.Email,
+		Subject: "Your Digital Wardrobe account was locked",
+		Body: fmt.Sprintf("Hi %s,\n\nWe locked your account after several failed sign-in attempts. It unlocks by itself at %s.\n\nIf these attempts were you, you can unlock it now with this link:\n\n%s\n\nIf they weren't, someone may be guessing your password; consider changing it once you're signed in.\n",
+			greetingName(user), user.LockedUntil.UTC().Format("15:04 MST on Jan 2"), s.appLink("/unlock-account", token)),
+	})
+}
+
+// UnlockAccount lifts a lock with the token from the unlock email. Tokens work once.
+func (s *AuthService) UnlockAccount(token string, client ClientInfo) error {
+	var user *models.User
+	err := s.store.Transaction(func(tx repository.Store) error {
+		var err error
+		user, err = tx.Users().GetByTokenForUpdate(repository.UnlockToken, hashToken(token), time.Now())
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrInvalidUnlockToken
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		if err := tx.Users().Update(user.ID, map[string]interface{}{
+			"failed_login_attempts": 0,
+			"lockout_count":         0,
+			"locked_until":          nil,
+			"unlock_token":          nil,
+		}); err != nil {
+			return fmt.Errorf("failed to unlock account: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infof("Account %s unlocked by email", user.ID)
+	s.audit(auditAccountUnlocked, &user.ID, client, models.JSONMap{"email": user.Email})
+	return nil
+}
+
+// clearLockout resets failure counters after a successful sign-in
+func (s *AuthService) clearLockout(user *models.User) {
+	if user.FailedLoginAttempts == 0 && user.LockoutCount == 0 && user.LockedUntil == nil {
+		return
+	}
+	if err := s.store.Users().Update(user.ID, map[string]interface{}{
+		"failed_login_attempts": 0,
+		"lockout_count":         0,
+		"locked_until":          nil,
+		"unlock_token":          nil,
+	}); err != nil {
+		s.logger.Errorf("Failed to clear lockout for user %s: %v", user.ID, err)
+	}
+	user.FailedLoginAttempts = 0
+	user.LockoutCount = 0
+	user.LockedUntil = nil
+	user.UnlockToken = nil
+}
+
+// audit writes an audit log entry. Failures are logged rather than returned
+// so auditing never blocks the action itself.
+func (s *AuthService) audit(action string, userID *string, client ClientInfo, details models.JSONMap) {
+	entry := models.AuditLog{
+		UserID:       userID,
+		Action:       action,
+		ResourceType: "user",
+		ResourceID:   userID,
+		Details:      details,
+		IPAddress:    optionalString(client.IPAddress),
+		UserAgent:    optionalString(client.UserAgent),
+	}
+	if err := s.store.AuditLogs().Create(&entry); err != nil {
+		s.logger.Errorf("Failed to write audit log %s: %v", action, err)
+	}
+}
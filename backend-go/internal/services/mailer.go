@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MailMessage is a plain-text email
+type MailMessage struct {
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sentAt"`
+}
+
+// Mailer sends email
+type Mailer interface {
+	Send(ctx context.Context, msg MailMessage) error
+}
+
+// SMTPMailer sends email through an SMTP server. STARTTLS is used when the
+// server offers it.
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer creates an SMTPMailer. Authentication is skipped when username is empty.
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPMailer{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		auth: auth,
+		from: from,
+	}
+}
+
+// Send sends a message
+func (m *SMTPMailer) Send(_ context.Context, msg MailMessage) error {
+	if strings.ContainsAny(msg.To, "\r\n") {
+		return fmt.Errorf("invalid recipient %q", msg.To)
+	}
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, formatMail(m.from, msg)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// OutboxMailer keeps sent messages in memory, and writes each one to a file
+// when a directory is set. It stands in for SMTP in development and tests.
+type OutboxMailer struct {
+	dir  string
+	from string
+
+	mu       sync.Mutex
+	messages []MailMessage
+}
+
+// NewOutboxMailer creates an OutboxMailer; dir may be empty to keep messages in memory only
+func NewOutboxMailer(dir, from string) *OutboxMailer {
+	return &OutboxMailer{dir: dir, from: from}
+}
+
+// Send records a message
+func (m *OutboxMailer) Send(_ context.Context, msg MailMessage) error {
+	msg.SentAt = time.Now()
+
+	m.mu.Lock()
+	m.messages = append(m.messages, msg)
+	m.mu.Unlock()
+
+	if m.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create outbox: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.eml", msg.SentAt.Format("20060102T150405.000000000"), outboxFileSafe.ReplaceAllString(msg.To, "_"))
+	if err := os.WriteFile(filepath.Join(m.dir, name), formatMail(m.from, msg), 0o644); err != nil {
+		return fmt.Errorf("failed to write outbox message: %w", err)
+	}
+	return nil
+}
+
+// Messages returns the messages sent so far
+func (m *OutboxMailer) Messages() []MailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MailMessage(nil), m.messages...)
+}
+
+// outboxFileSafe matches characters kept out of outbox file names
+var outboxFileSafe = regexp.MustCompile(`[^A-Za-z0-9@._-]`)
+
+// formatMail renders a message in RFC 5322 format
+func formatMail(from string, msg MailMessage) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
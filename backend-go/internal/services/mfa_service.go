@@ -0,0 +1,424 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// tokenTypeMFA marks MFA challenge tokens
+	tokenTypeMFA = "mfa"
+	// mfaChallengeTTL is how long a challenge token can be redeemed
+	mfaChallengeTTL = 5 * time.Minute
+	// mfaAttemptLimit is how many codes can be tried per user per challenge lifetime
+	mfaAttemptLimit = 5
+	// recoveryCodeCount is how many recovery codes a user holds at a time
+	recoveryCodeCount = 10
+
+	auditMFAFailed = "mfa_failed"
+)
+
+var (
+	// ErrMFARequired is returned when a correct sign-in still needs a second
+	// factor. Its data is an *MFAChallenge.
+	ErrMFARequired = apperror.Forbidden("MFA_REQUIRED", "enter the code from your authenticator app to finish signing in")
+	// ErrInvalidMFAChallenge is returned when a challenge token is malformed or expired
+	ErrInvalidMFAChallenge = apperror.Unauthorized("INVALID_MFA_CHALLENGE", "Sign-in attempt expired; please log in again")
+	// ErrInvalidMFACode is returned when a TOTP or recovery code is wrong or already used
+	ErrInvalidMFACode = apperror.Validation("INVALID_MFA_CODE", "Invalid or already used code")
+	// ErrMFAAlreadyEnabled is returned when enrolling a user who already has two-factor authentication
+	ErrMFAAlreadyEnabled = apperror.Conflict("MFA_ALREADY_ENABLED", "Two-factor authentication is already enabled")
+	// ErrMFANotEnabled is returned when managing two-factor authentication for a user without it
+	ErrMFANotEnabled = apperror.Conflict("MFA_NOT_ENABLED", "Two-factor authentication is not enabled")
+	// ErrMFANotEnrolled is returned when confirming before enrolling
+	ErrMFANotEnrolled = apperror.Conflict("MFA_NOT_ENROLLED", "Start enrollment before confirming a code")
+	// ErrTooManyMFAAttempts is returned when codes for one sign-in are tried too often
+	ErrTooManyMFAAttempts = apperror.RateLimited("TOO_MANY_REQUESTS", "Too many attempts; please try again later")
+)
+
+// MFAChallenge carries the challenge token the client redeems with a
+// second factor to finish signing in
+type MFAChallenge struct {
+	MFARequired    bool      `json:"mfaRequired"`
+	ChallengeToken string    `json:"challengeToken"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+	Methods        []string  `json:"methods"`
+}
+
+// mfaClaims are the claims of an MFA challenge token. Subject is the user ID.
+type mfaClaims struct {
+	TokenType string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// MFAVerifyRequest redeems a challenge token with a TOTP or recovery code
+type MFAVerifyRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// MFACodeRequest carries a TOTP or recovery code
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAStatus describes a user's two-factor setup
+type MFAStatus struct {
+	Enabled                bool  `json:"enabled"`
+	RecoveryCodesRemaining int64 `json:"recoveryCodesRemaining"`
+}
+
+// TOTPEnrollment is a new authenticator secret awaiting confirmation
+type TOTPEnrollment struct {
+	Secret string `json:"secret"` // base32, for manual entry
+	URI    string `json:"uri"`    // otpauth URI, for a QR code
+}
+
+// RecoveryCodes are shown to the user once; only their hashes are kept
+type RecoveryCodes struct {
+	Codes []string `json:"codes"`
+}
+
+// SetMFAEncryptionKey sets the passphrase TOTP secrets are encrypted with.
+// Secrets stored under a previous key can no longer be read.
+func (s *AuthService) SetMFAEncryptionKey(passphrase string) error {
+	box, err := newSecretBox(passphrase)
+	if err != nil {
+		return err
+	}
+	s.mfaBox = box
+	return nil
+}
+
+// MFAStatus reports whether the user has two-factor authentication enabled
+func (s *AuthService) MFAStatus(userID string) (*MFAStatus, error) {
+	user, err := s.store.Users().Get(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	remaining, err := s.store.RecoveryCodes().CountUnused(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return &MFAStatus{Enabled: user.TOTPEnabled, RecoveryCodesRemaining: remaining}, nil
+}
+
+// EnrollTOTP generates a new authenticator secret for the user. It takes
+// effect once confirmed with a code from the app.
+func (s *AuthService) EnrollTOTP(userID string) (*TOTPEnrollment, error) {
+	user, err := s.store.Users().Get(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.TOTPEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.mfaBox.seal(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+	if err := s.store.Users().Update(user.ID, map[string]interface{}{"totp_secret": sealed}); err != nil {
+		return nil, fmt.Errorf("failed to save secret: %w", err)
+	}
+
+	return &TOTPEnrollment{
+		Secret: totpEncoding.EncodeToString(secret),
+		URI:    totpURI(secret, user.Email),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user proves their
+// app produces valid codes, and returns their recovery codes
+func (s *AuthService) ConfirmTOTP(userID, code string) (*RecoveryCodes, error) {
+	var codes []string
+	err := s.store.Transaction(func(tx repository.Store) error {
+		user, err := lockUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if user.TOTPEnabled {
+			return ErrMFAAlreadyEnabled
+		}
+		if user.TOTPSecret == nil {
+			return ErrMFANotEnrolled
+		}
+
+		secret, err := s.mfaBox.open(*user.TOTPSecret)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt secret: %w", err)
+		}
+		step, ok := validateTOTP(secret, code, time.Now(), 0)
+		if !ok {
+			return ErrInvalidMFACode
+		}
+
+		if err := tx.Users().Update(user.ID, map[string]interface{}{
+			"totp_enabled":   true,
+			"totp_last_step": step,
+		}); err != nil {
+			return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+		}
+
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Two-factor authentication enabled for user %s", userID)
+	return &RecoveryCodes{Codes: codes}, nil
+}
+
+// DisableTOTP turns off two-factor authentication after checking a current
+// TOTP or recovery code
+func (s *AuthService) DisableTOTP(userID, code string, client ClientInfo) error {
+	err := s.withSecondFactor(userID, code, client, func(tx repository.Store, user *models.User) error {
+		if err := tx.Users().Update(user.ID, map[string]interface{}{
+			"totp_enabled":   false,
+			"totp_secret":    nil,
+			"totp_last_step": 0,
+		}); err != nil {
+			return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+		}
+		if err := tx.RecoveryCodes().DeleteForUser(userID); err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infof("Two-factor authentication disabled for user %s", userID)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a
+// current TOTP or recovery code
+func (s *AuthService) RegenerateRecoveryCodes(userID, code string, client ClientInfo) (*RecoveryCodes, error) {
+	var codes []string
+	err := s.withSecondFactor(userID, code, client, func(tx repository.Store, _ *models.User) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RecoveryCodes{Codes: codes}, nil
+}
+
+// VerifyMFA finishes a sign-in by redeeming a challenge token with a TOTP or
+// recovery code
+func (s *AuthService) VerifyMFA(ctx context.Context, challengeToken, code string, client ClientInfo) (*AuthResult, error) {
+	claims, err := s.parseMFAChallenge(challengeToken)
+	if err != nil {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	if limiter := s.loginProtection.Limiter; limiter != nil {
+		allowed, retryAfter, err := limiter.Allow(ctx, "mfa:user:"+claims.Subject, mfaAttemptLimit, mfaChallengeTTL)
+		if err != nil {
+			s.logger.Errorf("MFA rate limiter unavailable: %v", err)
+		} else if !allowed {
+			return nil, ErrTooManyMFAAttempts.WithRetryAfter(retryAfter)
+		}
+	}
+
+	var user *models.User
+	err = s.withSecondFactor(claims.Subject, code, client, func(_ repository.Store, u *models.User) error {
+		user = u
+		return nil
+	})
+	if errors.Is(err, ErrMFANotEnabled) {
+		return nil, ErrInvalidMFAChallenge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.createSession(user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.LastLoginAt = &now
+	if err := s.store.WithContext(ctx).Users().Update(user.ID, map[string]interface{}{"last_login_at": now}); err != nil {
+		s.logger.Warnf("Failed to record last login for %s: %v", user.ID, err)
+	}
+
+	s.logger.Infof("User logged in with two-factor authentication: %s", user.Email)
+	return result, nil
+}
+
+// mfaRequired issues a challenge token for a user whose first factor checked out
+func (s *AuthService) mfaRequired(user *models.User) error {
+	jti, err := randomURLToken(16)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(mfaChallengeTTL)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &mfaClaims{
+		TokenType: tokenTypeMFA,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID,
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	})
+	signed, err := token.SignedString([]byte(s.jwtSecret))
+	if err != nil {
+		return fmt.Errorf("failed to sign MFA challenge: %w", err)
+	}
+
+	return ErrMFARequired.WithData(&MFAChallenge{
+		MFARequired:    true,
+		ChallengeToken: signed,
+		ExpiresAt:      expiresAt,
+		Methods:        []string{"totp", "recovery_code"},
+	})
+}
+
+// parseMFAChallenge verifies an MFA challenge token
+func (s *AuthService) parseMFAChallenge(tokenString string) (*mfaClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &mfaClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(s.jwtSecret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*mfaClaims)
+	if !ok || !token.Valid || claims.TokenType != tokenTypeMFA || claims.Subject == "" {
+		return nil, errors.New("invalid MFA challenge")
+	}
+	return claims, nil
+}
+
+// withSecondFactor runs fn in a transaction once a TOTP or recovery code for
+// the user checks out. Wrong codes are audited.
+func (s *AuthService) withSecondFactor(userID, code string, client ClientInfo, fn func(tx repository.Store, user *models.User) error) error {
+	err := s.store.Transaction(func(tx repository.Store) error {
+		user, err := checkSecondFactor(tx, s.mfaBox, userID, code)
+		if err != nil {
+			return err
+		}
+		return fn(tx, user)
+	})
+	// Audited outside the transaction, which holds the user row lock the
+	// audit log's foreign key check would wait on
+	if errors.Is(err, ErrInvalidMFACode) {
+		s.audit(auditMFAFailed, &userID, client, nil)
+	}
+	return err
+}
+
+// checkSecondFactor locks the user and accepts a TOTP code or consumes a
+// recovery code
+func checkSecondFactor(tx repository.Store, box *secretBox, userID, code string) (*models.User, error) {
+	user, err := lockUser(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled || user.TOTPSecret == nil {
+		return nil, ErrMFANotEnabled
+	}
+
+	if isTOTPCode(code) {
+		secret, err := box.open(*user.TOTPSecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt secret: %w", err)
+		}
+		step, ok := validateTOTP(secret, code, time.Now(), user.TOTPLastStep)
+		if !ok {
+			return nil, ErrInvalidMFACode
+		}
+		if err := tx.Users().Update(user.ID, map[string]interface{}{"totp_last_step": step}); err != nil {
+			return nil, fmt.Errorf("failed to record code use: %w", err)
+		}
+		return user, nil
+	}
+
+	used, err := tx.RecoveryCodes().Use(userID, hashToken(normalizeRecoveryCode(code)), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	if !used {
+		return nil, ErrInvalidMFACode
+	}
+	return user, nil
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and returns a new set
+func replaceRecoveryCodes(tx repository.Store, userID string) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	rows := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		rows[i] = models.RecoveryCode{UserID: userID, CodeHash: hashToken(normalizeRecoveryCode(code))}
+	}
+	if err := tx.RecoveryCodes().Replace(userID, rows); err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// recoveryEncoding renders recovery codes in lowercase base32
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// generateRecoveryCode returns a random code like "k3v9q-x2mbt"
+func generateRecoveryCode() (string, error) {
+	raw := make([]byte, 6)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	code := recoveryEncoding.EncodeToString(raw)
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode strips the formatting users may or may not type
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// isTOTPCode reports whether a code is shaped like a TOTP code rather than a recovery code
+func isTOTPCode(code string) bool {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != totpDigits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
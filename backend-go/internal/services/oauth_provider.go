@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OAuth provider names, which match the provider ID columns on users
+const (
+	OAuthProviderGoogle   = "google"
+	OAuthProviderFacebook = "facebook"
+	OAuthProviderApple    = "apple"
+)
+
+// ErrOAuthExchangeFailed is returned when a provider rejects an authorization code
+// or its response cannot be verified
+var ErrOAuthExchangeFailed = apperror.Unauthorized("OAUTH_EXCHANGE_FAILED", "The provider could not confirm your sign-in")
+
+// OAuthIdentity is the account a provider vouches for
+type OAuthIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	GivenName     string
+	FamilyName    string
+	Picture       string
+}
+
+// OAuthAuthRequest holds the per-attempt values sent to the authorization endpoint
+type OAuthAuthRequest struct {
+	State         string
+	Nonce         string
+	CodeChallenge string // S256 PKCE challenge
+}
+
+// OAuthProvider is an authorization-code sign-in provider
+type OAuthProvider interface {
+	// Name identifies the provider in URLs and on users
+	Name() string
+	// AuthCodeURL returns the URL to send the user to
+	AuthCodeURL(req OAuthAuthRequest) string
+	// Exchange redeems an authorization code and returns the verified identity.
+	// nonce is checked against the ID token by OpenID Connect providers.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OAuthIdentity, error)
+}
+
+// OIDCProviderConfig configures an OpenID Connect provider
+type OIDCProviderConfig struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	// ClientSecretFunc, when set, generates the client secret for each exchange
+	ClientSecretFunc func() (string, error)
+	RedirectURL      string
+	AuthURL          string
+	TokenURL         string
+	Scopes           []string
+	// AuthParams are extra authorization endpoint parameters
+	AuthParams url.Values
+	HTTPClient *http.Client
+}
+
+// OIDCProvider signs users in with the OpenID Connect authorization code flow
+type OIDCProvider struct {
+	cfg      OIDCProviderConfig
+	verifier *IDTokenVerifier
+}
+
+// NewOIDCProvider creates an OpenID Connect provider whose ID tokens are checked by verifier
+func NewOIDCProvider(cfg OIDCProviderConfig, verifier *IDTokenVerifier) *OIDCProvider {
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &OIDCProvider{cfg: cfg, verifier: verifier}
+}
+
+// NewGoogleOAuthProvider creates the Google provider
+func NewGoogleOAuthProvider(cfg config.OAuthProviderConfig, keys KeySource) *OIDCProvider {
+	return NewOIDCProvider(OIDCProviderConfig{
+		Name:         OAuthProviderGoogle,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		AuthURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:     "https://oauth2.googleapis.com/token",
+		Scopes:       []string{"openid", "email", "profile"},
+	}, NewIDTokenVerifier(keys, googleIssuers, []string{cfg.ClientID}))
+}
+
+// NewAppleOAuthProvider creates the Sign in with Apple provider. Apple's client
+// secret is a short-lived ES256 JWT signed with the configured private key.
+func NewAppleOAuthProvider(cfg config.AppleOAuthConfig, keys KeySource) (*OIDCProvider, error) {
+	pemBytes, err := os.ReadFile(cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Apple private key: %w", err)
+	}
+	privateKey, err := jwt.ParseECPrivateKeyFromPEM(pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Apple private key: %w", err)
+	}
+	if keys == nil {
+		keys = NewRemoteKeySource("https://appleid.apple.com/auth/keys", nil, time.Hour)
+	}
+
+	clientSecret := func() (string, error) {
+		now := time.Now()
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+			Issuer:    cfg.TeamID,
+			Subject:   cfg.ClientID,
+			Audience:  jwt.ClaimStrings{"https://appleid.apple.com"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		})
+		token.Header["kid"] = cfg.KeyID
+		return token.SignedString(privateKey)
+	}
+
+	return NewOIDCProvider(OIDCProviderConfig{
+		Name:             OAuthProviderApple,
+		ClientID:         cfg.ClientID,
+		ClientSecretFunc: clientSecret,
+		RedirectURL:      cfg.RedirectURL,
+		AuthURL:          "https://appleid.apple.com/auth/authorize",
+		TokenURL:         "https://appleid.apple.com/auth/token",
+		Scopes:           []string{"openid", "name", "email"},
+		// Apple requires form_post whenever name or email is requested
+		AuthParams: url.Values{"response_mode": {"form_post"}},
+	}, NewIDTokenVerifier(keys, []string{"https://appleid.apple.com"}, []string{cfg.ClientID})), nil
+}
+
+// Name returns the provider name
+func (p *OIDCProvider) Name() string { return p.cfg.Name }
+
+// AuthCodeURL returns the authorization URL for an attempt
+func (p *OIDCProvider) AuthCodeURL(req OAuthAuthRequest) string {
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {req.State},
+		"nonce":                 {req.Nonce},
+		"code_challenge":        {req.CodeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	for key, values := range p.cfg.AuthParams {
+		params[key] = values
+	}
+	return p.cfg.AuthURL + "?" + params.Encode()
+}
+
+// Exchange redeems a code and verifies the returned ID token
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OAuthIdentity, error) {
+	secret := p.cfg.ClientSecret
+	if p.cfg.ClientSecretFunc != nil {
+		var err error
+		if secret, err = p.cfg.ClientSecretFunc(); err != nil {
+			return nil, fmt.Errorf("failed to build client secret: %w", err)
+		}
+	}
+
+	tokens, err := exchangeAuthCode(ctx, p.cfg.HTTPClient, p.cfg.TokenURL, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"client_secret": {secret},
+		"code_verifier": {codeVerifier},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("%w: no id_token in response", ErrOAuthExchangeFailed)
+	}
+
+	identity, err := p.verifier.Verify(ctx, tokens.IDToken, nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOAuthExchangeFailed, err)
+	}
+	return identity, nil
+}
+
+// FacebookProvider signs users in with Facebook Login. Facebook returns an
+// access token rather than an ID token, so the identity comes from the Graph API.
+type FacebookProvider struct {
+	cfg      config.OAuthProviderConfig
+	client   *http.Client
+	authURL  string
+	tokenURL string
+	graphURL string
+}
+
+// NewFacebookOAuthProvider creates the Facebook provider
+func NewFacebookOAuthProvider(cfg config.OAuthProviderConfig) *FacebookProvider {
+	return &FacebookProvider{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		authURL:  "https://www.facebook.com/v18.0/dialog/oauth",
+		tokenURL: "https://graph.facebook.com/v18.0/oauth/access_token",
+		graphURL: "https://graph.facebook.com/v18.0",
+	}
+}
+
+// Name returns the provider name
+func (p *FacebookProvider) Name() string { return OAuthProviderFacebook }
+
+// AuthCodeURL returns the authorization URL for an attempt
+func (p *FacebookProvider) AuthCodeURL(req OAuthAuthRequest) string {
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {"email,public_profile"},
+		"state":                 {req.State},
+		"code_challenge":        {req.CodeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	return p.authURL + "?" + params.Encode()
+}
+
+// Exchange redeems a code and reads the user's profile
+func (p *FacebookProvider) Exchange(ctx context.Context, code, codeVerifier, _ string) (*OAuthIdentity, error) {
+	tokens, err := exchangeAuthCode(ctx, p.client, p.tokenURL, url.Values{
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"client_secret": {p.cfg.ClientSecret},
+		"code_verifier": {codeVerifier},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tokens.AccessToken == "" {
+		return nil, fmt.Errorf("%w: no access_token in response", ErrOAuthExchangeFailed)
+	}
+
+	mac := hmac.New(sha256.New, []byte(p.cfg.ClientSecret))
+	mac.Write([]byte(tokens.AccessToken))
+	params := url.Values{
+		"fields":          {"id,name,first_name,last_name,email,picture.type(large)"},
+		"access_token":    {tokens.AccessToken},
+		"appsecret_proof": {hex.EncodeToString(mac.Sum(nil))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.graphURL+"/me?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOAuthExchangeFailed, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: profile request returned status %d", ErrOAuthExchangeFailed, resp.StatusCode)
+	}
+
+	var profile struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Email     string `json:"email"`
+		Picture   struct {
+			Data struct {
+				URL string `json:"url"`
+			} `json:"data"`
+		} `json:"picture"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&profile); err != nil {
+		return nil, fmt.Errorf("%w: invalid profile response", ErrOAuthExchangeFailed)
+	}
+	if profile.ID == "" {
+		return nil, fmt.Errorf("%w: profile has no id", ErrOAuthExchangeFailed)
+	}
+
+	return &OAuthIdentity{
+		Subject: profile.ID,
+		Email:   strings.ToLower(profile.Email),
+		// Facebook only returns confirmed email addresses
+		EmailVerified: profile.Email != "",
+		Name:          profile.Name,
+		GivenName:     profile.FirstName,
+		FamilyName:    profile.LastName,
+		Picture:       profile.Picture.Data.URL,
+	}, nil
+}
+
+// oauthTokenResponse is a token endpoint response
+type oauthTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// exchangeAuthCode posts an authorization code grant to a token endpoint
+func exchangeAuthCode(ctx context.Context, client *http.Client, tokenURL string, form url.Values) (*oauthTokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOAuthExchangeFailed, err)
+	}
+	defer resp.Body.Close()
+
+	var tokens oauthTokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("%w: invalid token response (status %d)", ErrOAuthExchangeFailed, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || tokens.Error != "" {
+		return nil, fmt.Errorf("%w: %s %s", ErrOAuthExchangeFailed, tokens.Error, tokens.ErrorDescription)
+	}
+	return &tokens, nil
+}
+
+// randomURLToken returns n random bytes, base64url encoded
+func randomURLToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// pkceChallenge returns the S256 code challenge for a verifier
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
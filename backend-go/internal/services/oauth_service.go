@@ -0,0 +1,476 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// oauthStateTTL is how long a user has to complete an authorization
+const oauthStateTTL = 10 * time.Minute
+
+// oauthLinkTTL is how long a link confirmation token stays valid
+const oauthLinkTTL = 15 * time.Minute
+
+// tokenTypeOAuthLink marks link confirmation tokens
+const tokenTypeOAuthLink = "oauth_link"
+
+// oauthProviderColumns maps provider names to the user columns holding their subject IDs
+var oauthProviderColumns = map[string]string{
+	OAuthProviderGoogle:   "google_id",
+	OAuthProviderFacebook: "facebook_id",
+	OAuthProviderApple:    "apple_id",
+}
+
+var (
+	// ErrOAuthProviderNotFound is returned for unknown or unconfigured providers
+	ErrOAuthProviderNotFound = apperror.NotFound("OAUTH_PROVIDER_NOT_FOUND", "Sign-in provider not available")
+	// ErrInvalidOAuthState is returned when a callback's state is unknown, expired or already used
+	ErrInvalidOAuthState = apperror.Validation("INVALID_OAUTH_STATE", "Sign-in attempt expired or was already used; please try again")
+	// ErrOAuthEmailRequired is returned when a provider shares no email for a new account
+	ErrOAuthEmailRequired = apperror.Validation("OAUTH_EMAIL_REQUIRED", "Please allow access to your email address to sign in")
+	// ErrOAuthEmailUnverified is returned when the provider has not verified the account's email
+	ErrOAuthEmailUnverified = apperror.Unauthorized("OAUTH_EMAIL_UNVERIFIED", "Your account email is not verified with the provider")
+	// ErrOAuthAccountConflict is returned when a provider account is linked to another user,
+	// or the user is linked to another account at the provider
+	ErrOAuthAccountConflict = apperror.Conflict("OAUTH_ACCOUNT_CONFLICT", "This provider account is linked to a different user")
+	// ErrOAuthLinkRequired is returned when the provider account's email belongs to an
+	// existing user who must confirm the link. Its data is an *OAuthLinkRequired.
+	ErrOAuthLinkRequired = apperror.Conflict("OAUTH_LINK_REQUIRED", "an account with this email exists")
+	// ErrInvalidLinkToken is returned when a link confirmation token is invalid, expired,
+	// or was issued for another user's email
+	ErrInvalidLinkToken = apperror.Validation("INVALID_LINK_TOKEN", "Invalid or expired link token")
+	// ErrOAuthNotLinked is returned when unlinking a provider that is not linked
+	ErrOAuthNotLinked = apperror.NotFound("OAUTH_NOT_LINKED", "Provider is not linked")
+	// ErrLastLoginMethod is returned when unlinking would leave the user unable to sign in
+	ErrLastLoginMethod = apperror.Conflict("LAST_LOGIN_METHOD", "Set a password or link another provider before unlinking this one")
+)
+
+// OAuthLinkRequired carries the token the existing user presents to
+// confirm linking a provider account
+type OAuthLinkRequired struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	LinkToken string    `json:"linkToken"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// OAuthStart is where to send the user to authorize
+type OAuthStart struct {
+	AuthorizationURL string    `json:"authorizationUrl"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+}
+
+// OAuthCallbackRequest is the code and state the provider redirected back with
+type OAuthCallbackRequest struct {
+	Code             string `form:"code" json:"code"`
+	State            string `form:"state" json:"state" binding:"required"`
+	Error            string `form:"error" json:"error"`
+	ErrorDescription string `form:"error_description" json:"errorDescription"`
+}
+
+// OAuthResult is the outcome of a completed authorization: a new session for
+// sign-in, or Linked for a link started by a signed-in user
+type OAuthResult struct {
+	Auth     *AuthResult `json:"auth,omitempty"`
+	Provider string      `json:"provider"`
+	Linked   bool        `json:"linked"`
+}
+
+// OAuthLinkConfirmation is a request to link a provider account to the signed-in user
+type OAuthLinkConfirmation struct {
+	LinkToken string `json:"linkToken" binding:"required"`
+}
+
+// OAuthAccount reports whether the user has linked a provider
+type OAuthAccount struct {
+	Provider string `json:"provider"`
+	Linked   bool   `json:"linked"`
+}
+
+// oauthLinkClaims are the claims of a link confirmation token. Subject is the
+// provider account's ID.
+type oauthLinkClaims struct {
+	Provider  string `json:"provider"`
+	Email     string `json:"email"`
+	TokenType string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// RegisterOAuthProvider enables sign-in with a provider
+func (s *AuthService) RegisterOAuthProvider(provider OAuthProvider) error {
+	if _, ok := oauthProviderColumns[provider.Name()]; !ok {
+		return fmt.Errorf("unsupported oauth provider %q", provider.Name())
+	}
+	if s.oauthProviders == nil {
+		s.oauthProviders = map[string]OAuthProvider{}
+	}
+	s.oauthProviders[provider.Name()] = provider
+	return nil
+}
+
+// OAuthProviderNames lists the enabled providers
+func (s *AuthService) OAuthProviderNames() []string {
+	names := make([]string, 0, len(s.oauthProviders))
+	for name := range s.oauthProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartOAuth begins an authorization with a provider. When userID is set the
+// provider account is linked to that user instead of signing in.
+func (s *AuthService) StartOAuth(providerName, userID string) (*OAuthStart, error) {
+	provider, ok := s.oauthProviders[providerName]
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
+	}
+
+	state, err := randomURLToken(32)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomURLToken(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomURLToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := models.OAuthState{
+		ID:           hashToken(state),
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		UserID:       optionalString(userID),
+		ExpiresAt:    time.Now().Add(oauthStateTTL),
+	}
+	if err := s.store.OAuthStates().Create(&pending); err != nil {
+		return nil, fmt.Errorf("failed to save oauth state: %w", err)
+	}
+
+	// Abandoned attempts are cleared as new ones start
+	if err := s.store.OAuthStates().DeleteExpired(time.Now()); err != nil {
+		s.logger.Warnf("Failed to clear expired oauth states: %v", err)
+	}
+
+	return &OAuthStart{
+		AuthorizationURL: provider.AuthCodeURL(OAuthAuthRequest{
+			State:         state,
+			Nonce:         nonce,
+			CodeChallenge: pkceChallenge(verifier),
+		}),
+		State:     state,
+		ExpiresAt: pending.ExpiresAt,
+	}, nil
+}
+
+// CompleteOAuth redeems the state and code a provider redirected back with.
+// Each state can be redeemed once.
+func (s *AuthService) CompleteOAuth(ctx context.Context, providerName, code, state string, client ClientInfo) (*OAuthResult, error) {
+	provider, ok := s.oauthProviders[providerName]
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
+	}
+
+	pending, err := s.store.WithContext(ctx).OAuthStates().Take(hashToken(state), providerName)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidOAuthState
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem oauth state: %w", err)
+	}
+	if !pending.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidOAuthState
+	}
+
+	identity, err := provider.Exchange(ctx, code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if pending.UserID != nil {
+		err := s.store.Transaction(func(tx repository.Store) error {
+			return s.linkOAuthIdentity(tx, *pending.UserID, providerName, identity.Subject)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &OAuthResult{Provider: providerName, Linked: true}, nil
+	}
+
+	auth, err := s.signInWithOAuth(providerName, identity, client)
+	if err != nil {
+		return nil, err
+	}
+	return &OAuthResult{Auth: auth, Provider: providerName}, nil
+}
+
+// ConfirmOAuthLink links the provider account named by a link token to the
+// signed-in user, who must own the email the token was issued for
+func (s *AuthService) ConfirmOAuthLink(userID, linkToken string) (string, error) {
+	claims, err := s.parseLinkToken(linkToken)
+	if err != nil {
+		return "", ErrInvalidLinkToken
+	}
+
+	err = s.store.Transaction(func(tx repository.Store) error {
+		user, err := lockUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(user.Email, claims.Email) {
+			return ErrInvalidLinkToken
+		}
+		return s.linkOAuthIdentity(tx, userID, claims.Provider, claims.Subject)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return claims.Provider, nil
+}
+
+// UnlinkOAuth removes a provider from the user, as long as another way to
+// sign in remains
+func (s *AuthService) UnlinkOAuth(userID, providerName string) error {
+	column, ok := oauthProviderColumns[providerName]
+	if !ok {
+		return ErrOAuthProviderNotFound
+	}
+
+	return s.store.Transaction(func(tx repository.Store) error {
+		user, err := lockUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if *oauthSubjectField(user, providerName) == nil {
+			return ErrOAuthNotLinked
+		}
+
+		remaining := 0
+		if user.PasswordHash != nil {
+			remaining++
+		}
+		for name := range oauthProviderColumns {
+			if name != providerName && *oauthSubjectField(user, name) != nil {
+				remaining++
+			}
+		}
+		if remaining == 0 {
+			return ErrLastLoginMethod
+		}
+
+		if err := tx.Users().Update(user.ID, map[string]interface{}{column: nil}); err != nil {
+			return fmt.Errorf("failed to unlink provider: %w", err)
+		}
+		s.logger.Infof("%s unlinked from user %s", providerName, userID)
+		return nil
+	})
+}
+
+// OAuthAccounts reports which enabled providers the user has linked
+func (s *AuthService) OAuthAccounts(userID string) ([]OAuthAccount, error) {
+	user, err := s.store.Users().Get(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	accounts := []OAuthAccount{}
+	for _, name := range s.OAuthProviderNames() {
+		accounts = append(accounts, OAuthAccount{
+			Provider: name,
+			Linked:   *oauthSubjectField(user, name) != nil,
+		})
+	}
+	return accounts, nil
+}
+
+// signInWithOAuth signs in the user linked to a provider account. Without a
+// link, a new user is created; if the email already belongs to a user, that
+// user has to confirm the link first. Users with two-factor authentication get
+// ErrMFARequired.
+func (s *AuthService) signInWithOAuth(providerName string, identity *OAuthIdentity, client ClientInfo) (*AuthResult, error) {
+	column := oauthProviderColumns[providerName]
+
+	var user *models.User
+	err := s.store.Transaction(func(tx repository.Store) error {
+		var err error
+		user, err = tx.Users().FindByOAuthSubject(column, identity.Subject)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		if identity.Email == "" {
+			return ErrOAuthEmailRequired
+		}
+		// Only a verified email proves the provider account owns the address
+		if !identity.EmailVerified {
+			return ErrOAuthEmailUnverified
+		}
+
+		existing, err := tx.Users().FindByEmailFold(identity.Email)
+		if err == nil {
+			if *oauthSubjectField(existing, providerName) != nil {
+				return ErrOAuthAccountConflict
+			}
+			return s.linkRequired(providerName, identity)
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		user = &models.User{
+			Email:           identity.Email,
+			FirstName:       optionalString(identity.GivenName),
+			LastName:        optionalString(identity.FamilyName),
+			DisplayName:     optionalString(identity.Name),
+			Avatar:          optionalString(identity.Picture),
+			IsEmailVerified: true,
+		}
+		*oauthSubjectField(user, providerName) = &identity.Subject
+		if err := tx.Users().Create(user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		s.logger.Infof("User registered with %s: %s", providerName, user.Email)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, s.mfaRequired(user)
+	}
+
+	result, err := s.createSession(user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.LastLoginAt = &now
+	if err := s.store.Users().Update(user.ID, map[string]interface{}{"last_login_at": now}); err != nil {
+		s.logger.Warnf("Failed to record last login for %s: %v", user.ID, err)
+	}
+
+	return result, nil
+}
+
+// linkOAuthIdentity links a provider account to a user
+func (s *AuthService) linkOAuthIdentity(tx repository.Store, userID, providerName, subject string) error {
+	column := oauthProviderColumns[providerName]
+
+	user, err := lockUser(tx, userID)
+	if err != nil {
+		return err
+	}
+	if current := *oauthSubjectField(user, providerName); current != nil {
+		if *current == subject {
+			return nil
+		}
+		return ErrOAuthAccountConflict
+	}
+
+	owner, err := tx.Users().FindByOAuthSubject(column, subject)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("failed to check provider account: %w", err)
+	}
+	if err == nil && owner.ID != userID {
+		return ErrOAuthAccountConflict
+	}
+
+	if err := tx.Users().Update(user.ID, map[string]interface{}{column: subject}); err != nil {
+		return fmt.Errorf("failed to link provider: %w", err)
+	}
+	s.logger.Infof("%s linked to user %s", providerName, userID)
+	return nil
+}
+
+// linkRequired issues a link confirmation token for a provider account
+func (s *AuthService) linkRequired(providerName string, identity *OAuthIdentity) error {
+	expiresAt := time.Now().Add(oauthLinkTTL)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &oauthLinkClaims{
+		Provider:  providerName,
+		Email:     identity.Email,
+		TokenType: tokenTypeOAuthLink,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   identity.Subject,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	})
+	signed, err := token.SignedString([]byte(s.jwtSecret))
+	if err != nil {
+		return fmt.Errorf("failed to sign link token: %w", err)
+	}
+
+	return ErrOAuthLinkRequired.
+		Withf("sign in as %s and confirm linking %s", identity.Email, providerName).
+		WithData(&OAuthLinkRequired{
+			Provider:  providerName,
+			Email:     identity.Email,
+			LinkToken: signed,
+			ExpiresAt: expiresAt,
+		})
+}
+
+// parseLinkToken verifies a link confirmation token
+func (s *AuthService) parseLinkToken(tokenString string) (*oauthLinkClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &oauthLinkClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(s.jwtSecret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*oauthLinkClaims)
+	if !ok || !token.Valid || claims.TokenType != tokenTypeOAuthLink || claims.Subject == "" {
+		return nil, errors.New("invalid link token")
+	}
+	if _, ok := oauthProviderColumns[claims.Provider]; !ok {
+		return nil, errors.New("invalid link token")
+	}
+	return claims, nil
+}
+
+// lockUser loads and locks a user row
+func lockUser(tx repository.Store, userID string) (*models.User, error) {
+	user, err := tx.Users().GetForUpdate(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// oauthSubjectField returns the user field holding a provider's subject ID
+func oauthSubjectField(user *models.User, providerName string) **string {
+	switch providerName {
+	case OAuthProviderGoogle:
+		return &user.GoogleID
+	case OAuthProviderFacebook:
+		return &user.FacebookID
+	case OAuthProviderApple:
+		return &user.AppleID
+	}
+	panic("unknown oauth provider " + providerName)
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/ids"
+)
+
+const (
+	// DefaultPageLimit is the page size used when none is requested
+	DefaultPageLimit = 20
+	// MaxPageLimit caps the page size a client may request
+	MaxPageLimit = 100
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = apperror.Validation(apperror.CodeValidation, "invalid cursor")
+
+// Pagination mirrors the pagination block of the webapp's PaginatedResponse type
+type Pagination struct {
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	Total      int64  `json:"total"`
+	TotalPages int    `json:"totalPages"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+// pageCursor is the decoded form of an opaque keyset cursor
+type pageCursor struct {
+	SortBy string          `json:"s"`
+	Value  json.RawMessage `json:"v"`
+	ID     string          `json:"id"`
+}
+
+// encodeCursor encodes the sort key of the last row on a page
+func encodeCursor(sortBy string, value interface{}, id string) (string, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(pageCursor{SortBy: sortBy, Value: raw, ID: id})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// decodeCursor decodes a cursor produced by encodeCursor
+func decodeCursor(cursor string) (*pageCursor, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var pc pageCursor
+	if err := json.Unmarshal(payload, &pc); err != nil || !ids.Valid(pc.ID) {
+		return nil, ErrInvalidCursor
+	}
+
+	return &pc, nil
+}
+
+// normalizeLimit clamps a requested page size to [1, MaxPageLimit]
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+	return limit
+}
+
+// totalPages computes the number of pages for total rows at the given size
+func totalPages(total int64, limit int) int {
+	if total == 0 {
+		return 0
+	}
+	return int((total + int64(limit) - 1) / int64(limit))
+}
+
+// cursorMismatchError reports a cursor created for a different sort
+func cursorMismatchError(sortBy string) error {
+	return ErrInvalidCursor.Field("cursor", "cursor was not created for sortBy=%s", sortBy)
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2Params are the Argon2id cost parameters for new password hashes
+type Argon2Params struct {
+	Time       uint32 // iterations
+	Memory     uint32 // KiB
+	Threads    uint8
+	SaltLength uint32 // bytes
+	KeyLength  uint32 // bytes
+}
+
+// DefaultArgon2Params follow the second recommended option of RFC 9106
+var DefaultArgon2Params = Argon2Params{
+	Time:       3,
+	Memory:     64 * 1024,
+	Threads:    4,
+	SaltLength: 16,
+	KeyLength:  32,
+}
+
+// legacyArgon2Params are the parameters of hashes stored as a bare
+// base64(salt || key) blob, before hashes carried their own parameters
+var legacyArgon2Params = Argon2Params{
+	Time:       1,
+	Memory:     64 * 1024,
+	Threads:    4,
+	SaltLength: 16,
+	KeyLength:  32,
+}
+
+// errMalformedHash is returned for stored hashes that can't be parsed
+var errMalformedHash = errors.New("malformed password hash")
+
+// Validate checks the parameters are usable
+func (p Argon2Params) Validate() error {
+	switch {
+	case p.Time < 1:
+		return errors.New("argon2 time must be at least 1")
+	case p.Threads < 1:
+		return errors.New("argon2 threads must be at least 1")
+	case p.Memory < 8*uint32(p.Threads):
+		return errors.New("argon2 memory must be at least 8 KiB per thread")
+	case p.SaltLength < 8:
+		return errors.New("argon2 salt length must be at least 8 bytes")
+	case p.KeyLength < 16:
+		return errors.New("argon2 key length must be at least 16 bytes")
+	}
+	return nil
+}
+
+// PasswordHasher hashes passwords with Argon2id into PHC strings such as
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>
+type PasswordHasher struct {
+	params Argon2Params
+}
+
+// NewPasswordHasher creates a PasswordHasher
+func NewPasswordHasher(params Argon2Params) (*PasswordHasher, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &PasswordHasher{params: params}, nil
+}
+
+// SetPasswordHashing sets the Argon2id parameters for new hashes. Stored
+// hashes with other parameters are upgraded at the next successful login.
+func (s *AuthService) SetPasswordHashing(params Argon2Params) error {
+	hasher, err := NewPasswordHasher(params)
+	if err != nil {
+		return err
+	}
+	s.hasher = hasher
+	return nil
+}
+
+// Hash hashes a password with the current parameters
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.params.Time, h.params.Memory, h.params.Threads, h.params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.params.Memory, h.params.Time, h.params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify checks a password against a stored hash. needsRehash reports a
+// match against a hash made with other parameters than the current ones.
+func (h *PasswordHasher) Verify(password, encoded string) (match, needsRehash bool, err error) {
+	params, salt, key, err := decodePasswordHash(encoded)
+	if err != nil {
+		return false, false, err
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, params.KeyLength)
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return false, false, nil
+	}
+	return true, params != h.params, nil
+}
+
+// decodePasswordHash parses a PHC string, or a legacy salt+key blob
+func decodePasswordHash(encoded string) (Argon2Params, []byte, []byte, error) {
+	if !strings.HasPrefix(encoded, "$") {
+		blob, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(blob) != int(legacyArgon2Params.SaltLength+legacyArgon2Params.KeyLength) {
+			return Argon2Params{}, nil, nil, errMalformedHash
+		}
+		salt, key := blob[:legacyArgon2Params.SaltLength], blob[legacyArgon2Params.SaltLength:]
+		return legacyArgon2Params, salt, key, nil
+	}
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Argon2Params{}, nil, nil, errMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2Params{}, nil, nil, errMalformedHash
+	}
+
+	var params Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return Argon2Params{}, nil, nil, errMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, errMalformedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Argon2Params{}, nil, nil, errMalformedHash
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	if err := params.Validate(); err != nil {
+		return Argon2Params{}, nil, nil, errMalformedHash
+	}
+	return params, salt, key, nil
+}
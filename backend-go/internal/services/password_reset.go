@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
+)
+
+var (
+	// ErrInvalidResetToken is returned when a password reset token is unknown, used or expired
+	ErrInvalidResetToken = apperror.Validation("INVALID_RESET_TOKEN", "Invalid or expired reset token")
+	// ErrIncorrectPassword is returned when the current password given to change it is wrong
+	ErrIncorrectPassword = apperror.Validation("INCORRECT_PASSWORD", "Current password is incorrect")
+	// ErrPasswordNotSet is returned when changing the password of a user who signs in only through a provider
+	ErrPasswordNotSet = apperror.Conflict("PASSWORD_NOT_SET", "This account signs in through a provider; use forgot password to set a password")
+)
+
+// ForgotPasswordRequest represents a request for a password reset email
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest represents a password reset
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required,min=8"`
+}
+
+// ChangePasswordRequest represents a password change by a signed-in user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=8"`
+}
+
+// SetPasswordReset configures password reset tokens
+func (s *AuthService) SetPasswordReset(opts EmailTokenOptions) {
+	s.passwordReset = opts
+}
+
+// ForgotPassword emails a password reset link to the user with the given
+// email. Unknown emails and throttled requests are ignored so the response
+// doesn't reveal whether an account exists.
+func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.store.WithContext(ctx).Users().FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.logger.Infof("Password reset requested for unknown email %s", email)
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if wait := s.passwordReset.resendWait(user.PasswordResetExpires); wait > 0 {
+		s.logger.Infof("Password reset for user %s throttled", user.ID)
+		return nil
+	}
+
+	token, err := randomURLToken(32)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(s.passwordReset.TokenTTL)
+	if err := s.store.WithContext(ctx).Users().Update(user.ID, map[string]interface{}{
+		"password_reset_token":   hashToken(token),
+		"password_reset_expires": expiresAt,
+	}); err != nil {
+		return fmt.Errorf("failed to save password reset token: %w", err)
+	}
+
+	err = s.mailer.Send(ctx, MailMessage{
+		To:      user.Email,
+		Subject: "Reset your Digital Wardrobe password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password for your account. To choose a new password, open this link:\n\n%s\n\nThe link expires in %s and works once. If you didn't ask for this, you can ignore this email; your password won't change.\n",
+			greetingName(user), s.appLink("/reset-password", token), s.passwordReset.TokenTTL.Round(time.Minute)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+
+	s.logger.Infof("Password reset email sent to user %s", user.ID)
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token and signs the user out
+// everywhere. Tokens work once.
+func (s *AuthService) ResetPassword(token, newPassword string) error {
+	hashedPassword, err := s.hashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	var user *models.User
+	err = s.store.Transaction(func(tx repository.Store) error {
+		var err error
+		user, err = tx.Users().GetByTokenForUpdate(repository.PasswordResetToken, hashToken(token), time.Now())
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrInvalidResetToken
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		if err := tx.Users().Update(user.ID, map[string]interface{}{
+			"password_hash":          hashedPassword,
+			"password_reset_token":   nil,
+			"password_reset_expires": nil,
+			// Proving control of the email lifts any lockout
+			"failed_login_attempts": 0,
+			"lockout_count":         0,
+			"locked_until":          nil,
+			"unlock_token":          nil,
+		}); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		return revokeSessions(tx, user.ID, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infof("Password reset for user %s", user.ID)
+	return nil
+}
+
+// ChangePassword replaces the password of a signed-in user after checking the
+// current one. Every session except the current one is revoked.
+func (s *AuthService) ChangePassword(userID, sessionID, currentPassword, newPassword string) error {
+	user, err := s.store.Users().Get(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.PasswordHash == nil {
+		return ErrPasswordNotSet
+	}
+	if ok, _ := s.verifyPassword(currentPassword, *user.PasswordHash); !ok {
+		return ErrIncorrectPassword
+	}
+
+	hashedPassword, err := s.hashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Users().Update(userID, map[string]interface{}{
+			"password_hash":          hashedPassword,
+			"password_reset_token":   nil,
+			"password_reset_expires": nil,
+		}); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		return revokeSessions(tx, userID, sessionID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infof("Password changed for user %s", userID)
+	return nil
+}
+
+// revokeSessions revokes the active sessions of a user, except keepSessionID when set
+func revokeSessions(store repository.Store, userID, keepSessionID string) error {
+	filter := repository.SessionFilter{UserID: userID, ExceptID: keepSessionID}
+	if _, err := store.Sessions().Revoke(filter); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/ids"
+	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
+	"digital-wardrobe-backend/pkg/logger"
+)
+
+// ActiveAlertLimits caps how many alerts each subscription tier may have watching at once
+var ActiveAlertLimits = map[string]int{
+	models.SubscriptionFree:    5,
+	models.SubscriptionPremium: 50,
+	models.SubscriptionPro:     250,
+}
+
+var (
+	// ErrPriceAlertNotFound is returned when an alert does not exist or belongs to another user
+	ErrPriceAlertNotFound = apperror.NotFound("PRICE_ALERT_NOT_FOUND", "Price alert not found")
+	// ErrInvalidPriceAlert is returned when alert data fails validation
+	ErrInvalidPriceAlert = apperror.Validation(apperror.CodeValidation, "invalid price alert data")
+	// ErrPriceAlertLimit is returned when the user's tier allows no more active alerts
+	ErrPriceAlertLimit = apperror.Forbidden("PRICE_ALERT_LIMIT", "active price alert limit reached")
+)
+
+// PriceAlertService handles price alert operations
+type PriceAlertService struct {
+	store             repository.Store
+	logger            logger.Logger
+	allowPrivateHosts bool
+}
+
+// NewPriceAlertService creates a new PriceAlertService
+func NewPriceAlertService(store repository.Store) *PriceAlertService {
+	return &PriceAlertService{
+		store:  store,
+		logger: logger.New("price_alert"),
+	}
+}
+
+// SetAllowPrivateHosts sets whether alerts may watch localhost and private IP addresses
+func (s *PriceAlertService) SetAllowPrivateHosts(allow bool) {
+	s.allowPrivateHosts = allow
+}
+
+// PriceAlertListOptions filters a user's price alerts
+type PriceAlertListOptions struct {
+	Active    *bool  `form:"active"`
+	Triggered *bool  `form:"triggered"`
+	ItemID    string `form:"itemId"`
+}
+
+// GetPriceAlerts gets price alerts for a user, newest first
+func (s *PriceAlertService) GetPriceAlerts(userID string, opts PriceAlertListOptions) ([]models.PriceAlert, error) {
+	if opts.ItemID != "" && !ids.Valid(opts.ItemID) {
+		// No item has a malformed ID, so no alert can match it
+		return []models.PriceAlert{}, nil
+	}
+
+	alerts, err := s.store.PriceAlerts().List(repository.PriceAlertFilter{
+		UserID:    userID,
+		Active:    opts.Active,
+		Triggered: opts.Triggered,
+		ItemID:    opts.ItemID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// GetPriceAlert gets a price alert owned by the user
+func (s *PriceAlertService) GetPriceAlert(userID, alertID string) (*models.PriceAlert, error) {
+	return s.findAlert(s.store, userID, alertID)
+}
+
+// CreatePriceAlert creates a price alert. When ItemID is set the product URL,
+// currency and current price default to the item's.
+func (s *PriceAlertService) CreatePriceAlert(userID string, data models.PriceAlertData) (*models.PriceAlert, error) {
+	alert := models.PriceAlert{
+		UserID:            userID,
+		TargetPrice:       data.TargetPrice,
+		CurrentPrice:      data.CurrentPrice,
+		Currency:          "USD",
+		IsActive:          true,
+		EmailNotification: true,
+		PushNotification:  true,
+	}
+	if data.EmailNotification != nil {
+		alert.EmailNotification = *data.EmailNotification
+	}
+	if data.PushNotification != nil {
+		alert.PushNotification = *data.PushNotification
+	}
+
+	err := s.store.Transaction(func(tx repository.Store) error {
+		if data.ItemID != nil && *data.ItemID != "" {
+			if !ids.Valid(*data.ItemID) {
+				return ErrItemNotFound
+			}
+			item, err := tx.Items().Get(userID, *data.ItemID)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return ErrItemNotFound
+				}
+				return fmt.Errorf("failed to get item: %w", err)
+			}
+
+			alert.ItemID = &item.ID
+			alert.Currency = item.Currency
+			if item.OriginalURL != nil {
+				alert.ProductURL = *item.OriginalURL
+			}
+			if alert.CurrentPrice == nil {
+				alert.CurrentPrice = item.Price
+			}
+		}
+
+		if data.ProductURL != nil {
+			alert.ProductURL = strings.TrimSpace(*data.ProductURL)
+		}
+		if data.Currency != nil && *data.Currency != "" {
+			alert.Currency = strings.ToUpper(*data.Currency)
+		}
+
+		if err := s.validatePriceAlert(&alert); err != nil {
+			return err
+		}
+		if err := s.checkAlertLimit(tx, userID); err != nil {
+			return err
+		}
+
+		if err := tx.PriceAlerts().Create(&alert); err != nil {
+			return fmt.Errorf("failed to create price alert: %w", err)
+		}
+
+		// Create skips false booleans in favour of the column defaults
+		if !alert.EmailNotification || !alert.PushNotification {
+			if err := tx.PriceAlerts().Update(alert.ID, map[string]interface{}{
+				"email_notification": alert.EmailNotification,
+				"push_notification":  alert.PushNotification,
+			}); err != nil {
+				return fmt.Errorf("failed to create price alert: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Price alert %s created for user %s", alert.ID, userID)
+	return &alert, nil
+}
+
+// UpdatePriceAlert updates a price alert owned by the user. Changing the
+// target of a triggered alert re-arms it.
+func (s *PriceAlertService) UpdatePriceAlert(userID, alertID string, data models.PriceAlertUpdate) (*models.PriceAlert, error) {
+	var alert *models.PriceAlert
+
+	err := s.store.Transaction(func(tx repository.Store) error {
+		var err error
+		if alert, err = s.findAlert(tx, userID, alertID); err != nil {
+			return err
+		}
+
+		rearm := false
+		if data.TargetPrice != nil && *data.TargetPrice != alert.TargetPrice {
+			alert.TargetPrice = *data.TargetPrice
+			rearm = alert.IsTriggered
+		}
+		if data.EmailNotification != nil {
+			alert.EmailNotification = *data.EmailNotification
+		}
+		if data.PushNotification != nil {
+			alert.PushNotification = *data.PushNotification
+		}
+
+		if err := s.validatePriceAlert(alert); err != nil {
+			return err
+		}
+		if rearm {
+			if alert.IsActive {
+				if err := s.checkAlertLimit(tx, userID); err != nil {
+					return err
+				}
+			}
+			alert.IsTriggered = false
+			alert.TriggeredAt = nil
+		}
+
+		if err := tx.PriceAlerts().Save(alert); err != nil {
+			return fmt.Errorf("failed to update price alert: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return alert, nil
+}
+
+// PausePriceAlert stops checking a price alert owned by the user
+func (s *PriceAlertService) PausePriceAlert(userID, alertID string) (*models.PriceAlert, error) {
+	alert, err := s.findAlert(s.store, userID, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if !alert.IsActive {
+		return alert, nil
+	}
+
+	alert.IsActive = false
+	if err := s.store.PriceAlerts().Update(alert.ID, map[string]interface{}{"is_active": false}); err != nil {
+		return nil, fmt.Errorf("failed to pause price alert: %w", err)
+	}
+
+	return alert, nil
+}
+
+// ResumePriceAlert resumes checking a price alert owned by the user. A
+// triggered alert is re-armed, which requires its target to be below the
+// current price again.
+func (s *PriceAlertService) ResumePriceAlert(userID, alertID string) (*models.PriceAlert, error) {
+	var alert *models.PriceAlert
+
+	err := s.store.Transaction(func(tx repository.Store) error {
+		var err error
+		if alert, err = s.findAlert(tx, userID, alertID); err != nil {
+			return err
+		}
+		if alert.IsActive && !alert.IsTriggered {
+			return nil
+		}
+
+		if err := s.validatePriceAlert(alert); err != nil {
+			return err
+		}
+		if err := s.checkAlertLimit(tx, userID); err != nil {
+			return err
+		}
+
+		alert.IsActive = true
+		alert.IsTriggered = false
+		alert.TriggeredAt = nil
+
+		if err := tx.PriceAlerts().Save(alert); err != nil {
+			return fmt.Errorf("failed to resume price alert: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return alert, nil
+}
+
+// DeletePriceAlert deletes a price alert owned by the user
+func (s *PriceAlertService) DeletePriceAlert(userID, alertID string) error {
+	if !ids.Valid(alertID) {
+		return ErrPriceAlertNotFound
+	}
+
+	err := s.store.PriceAlerts().Delete(userID, alertID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrPriceAlertNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete price alert: %w", err)
+	}
+	return nil
+}
+
+// findAlert loads an alert owned by the user
+func (s *PriceAlertService) findAlert(store repository.Store, userID, alertID string) (*models.PriceAlert, error) {
+	if !ids.Valid(alertID) {
+		return nil, ErrPriceAlertNotFound
+	}
+
+	alert, err := store.PriceAlerts().Get(userID, alertID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPriceAlertNotFound
+		}
+		return nil, fmt.Errorf("failed to get price alert: %w", err)
+	}
+	return alert, nil
+}
+
+// checkAlertLimit returns ErrPriceAlertLimit if the user cannot watch another alert.
+// The user row is locked so concurrent requests cannot both pass the check.
+func (s *PriceAlertService) checkAlertLimit(tx repository.Store, userID string) error {
+	user, err := tx.Users().GetForUpdate(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	tier := user.EffectiveSubscriptionTier(time.Now())
+	limit, ok := ActiveAlertLimits[tier]
+	if !ok {
+		limit = ActiveAlertLimits[models.SubscriptionFree]
+	}
+
+	active, err := tx.PriceAlerts().CountWatching(userID)
+	if err != nil {
+		return fmt.Errorf("failed to count price alerts: %w", err)
+	}
+
+	if active >= int64(limit) {
+		return ErrPriceAlertLimit.Withf("the %s plan allows %d active alerts", tier, limit)
+	}
+	return nil
+}
+
+// validatePriceAlert checks an alert's URL and target price. Hosts that
+// resolve to private addresses are refused later, when the worker fetches them.
+func (s *PriceAlertService) validatePriceAlert(alert *models.PriceAlert) error {
+	if alert.ProductURL == "" {
+		return ErrInvalidPriceAlert.Field("productUrl", "productUrl is required unless the item has an original URL")
+	}
+	domain, err := alertDomain(alert.ProductURL)
+	if err != nil {
+		return ErrInvalidPriceAlert.Field("productUrl", "productUrl must be an http(s) URL")
+	}
+	if !s.allowPrivateHosts && isPrivateHost(domain) {
+		return ErrInvalidPriceAlert.Field("productUrl", "productUrl must be a public address")
+	}
+	if alert.TargetPrice <= 0 {
+		return ErrInvalidPriceAlert.Field("targetPrice", "targetPrice must be positive")
+	}
+	if alert.CurrentPrice != nil && alert.TargetPrice >= *alert.CurrentPrice {
+		return ErrInvalidPriceAlert.Field("targetPrice", "targetPrice must be below the current price of %.2f", *alert.CurrentPrice)
+	}
+	return nil
+}
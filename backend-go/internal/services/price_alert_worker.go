@@ -0,0 +1,389 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"digital-wardrobe-backend/internal/config"
+	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
+	"digital-wardrobe-backend/pkg/logger"
+)
+
+// PriceAlertNotifier delivers triggered price alerts to their owners
+type PriceAlertNotifier interface {
+	NotifyPriceAlert(ctx context.Context, alert *models.PriceAlert) error
+}
+
+// LogPriceAlertNotifier logs triggered alerts. It is used until a delivery channel is configured.
+type LogPriceAlertNotifier struct {
+	logger logger.Logger
+}
+
+// NewLogPriceAlertNotifier creates a new LogPriceAlertNotifier
+func NewLogPriceAlertNotifier() *LogPriceAlertNotifier {
+	return &LogPriceAlertNotifier{logger: logger.New("price_alerts")}
+}
+
+// NotifyPriceAlert logs the triggered alert
+func (n *LogPriceAlertNotifier) NotifyPriceAlert(ctx context.Context, alert *models.PriceAlert) error {
+	n.logger.Infof("Price alert %s for user %s triggered: %s is now %.2f %s (target %.2f)",
+		alert.ID, alert.UserID, alert.ProductURL, floatOrZero(alert.CurrentPrice), alert.Currency, alert.TargetPrice)
+	return nil
+}
+
+// priceCheck is the outcome of checking one alert
+type priceCheck struct {
+	Alert     *models.PriceAlert
+	Fetched   *FetchedPrice // nil when the fetch failed
+	CheckedAt time.Time
+	Trigger   bool
+}
+
+// priceAlertStore loads and saves the alerts the worker checks
+type priceAlertStore interface {
+	// DueAlerts returns active, untriggered alerts last checked before checkedBefore
+	DueAlerts(ctx context.Context, checkedBefore time.Time, limit int) ([]models.PriceAlert, error)
+	// SaveCheck persists a check and reports whether this call triggered the alert
+	SaveCheck(ctx context.Context, check priceCheck) (bool, error)
+}
+
+// PriceAlertWorker periodically re-fetches product pages and triggers price alerts
+type PriceAlertWorker struct {
+	store    priceAlertStore
+	fetcher  PriceFetcher
+	notifier PriceAlertNotifier
+	limiter  *domainLimiter
+	cfg      config.PriceAlertConfig
+	logger   logger.Logger
+	now      func() time.Time
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPriceAlertWorker creates a new PriceAlertWorker
+func NewPriceAlertWorker(store repository.Store, fetcher PriceFetcher, notifier PriceAlertNotifier, cfg config.PriceAlertConfig) *PriceAlertWorker {
+	return newPriceAlertWorker(&repositoryPriceAlertStore{store: store}, fetcher, notifier, cfg)
+}
+
+// newPriceAlertWorker creates a worker backed by store
+func newPriceAlertWorker(store priceAlertStore, fetcher PriceFetcher, notifier PriceAlertNotifier, cfg config.PriceAlertConfig) *PriceAlertWorker {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxBackoff < cfg.DomainInterval {
+		cfg.MaxBackoff = cfg.DomainInterval
+	}
+
+	return &PriceAlertWorker{
+		store:    store,
+		fetcher:  fetcher,
+		notifier: notifier,
+		limiter:  newDomainLimiter(cfg.DomainInterval, cfg.MaxBackoff),
+		cfg:      cfg,
+		logger:   logger.New("price_alerts"),
+		now:      time.Now,
+	}
+}
+
+// Start runs the worker in the background until Stop is called or ctx is cancelled
+func (w *PriceAlertWorker) Start(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cancel != nil {
+		return
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	done := make(chan struct{})
+	w.done = done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(w.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			if err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				w.logger.Errorf("Price alert pass failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	w.logger.Infof("Price alert worker started (interval %s)", w.cfg.Interval)
+}
+
+// Stop signals the worker to stop and waits for the current pass to finish or ctx to expire
+func (w *PriceAlertWorker) Stop(ctx context.Context) error {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel, w.done = nil, nil
+	w.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	select {
+	case <-done:
+		w.logger.Info("Price alert worker stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("price alert worker did not stop: %w", ctx.Err())
+	}
+}
+
+// RunOnce checks every due alert once. Domains are processed concurrently;
+// alerts on the same domain are checked one at a time within its rate limit.
+func (w *PriceAlertWorker) RunOnce(ctx context.Context) error {
+	alerts, err := w.store.DueAlerts(ctx, w.now().Add(-w.cfg.CheckEvery), w.cfg.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get due alerts: %w", err)
+	}
+
+	byDomain := make(map[string][]*models.PriceAlert)
+	var domains []string
+	for i := range alerts {
+		alert := &alerts[i]
+		domain, err := alertDomain(alert.ProductURL)
+		if err != nil {
+			w.logger.Warnf("Price alert %s has an invalid product URL: %v", alert.ID, err)
+			w.save(ctx, priceCheck{Alert: alert, CheckedAt: w.now()})
+			continue
+		}
+		if _, ok := byDomain[domain]; !ok {
+			domains = append(domains, domain)
+		}
+		byDomain[domain] = append(byDomain[domain], alert)
+	}
+
+	sem := make(chan struct{}, w.cfg.Concurrency)
+	var wg sync.WaitGroup
+	for _, domain := range domains {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(domain string, alerts []*models.PriceAlert) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			w.checkDomain(ctx, domain, alerts)
+		}(domain, byDomain[domain])
+	}
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// checkDomain checks the alerts of one domain in order. Alerts that cannot be
+// checked before the next pass because the domain is backing off are left due.
+func (w *PriceAlertWorker) checkDomain(ctx context.Context, domain string, alerts []*models.PriceAlert) {
+	for _, alert := range alerts {
+		if w.limiter.Delay(domain, w.now()) > w.cfg.Interval {
+			return
+		}
+		if !sleepContext(ctx, w.limiter.Reserve(domain, w.now())) {
+			return
+		}
+
+		fetched, err := w.fetcher.FetchPrice(ctx, alert.ProductURL)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			w.recordFetchFailure(domain, err)
+			w.logger.Warnf("Failed to check price alert %s: %v", alert.ID, err)
+			w.save(ctx, priceCheck{Alert: alert, CheckedAt: w.now()})
+			continue
+		}
+		w.limiter.Success(domain)
+
+		if !currencyMatches(alert.Currency, fetched.Currency) {
+			w.logger.Warnf("Price alert %s expects %s but the page is priced in %s", alert.ID, alert.Currency, fetched.Currency)
+			w.save(ctx, priceCheck{Alert: alert, CheckedAt: w.now()})
+			continue
+		}
+
+		check := priceCheck{
+			Alert:     alert,
+			Fetched:   fetched,
+			CheckedAt: w.now(),
+			Trigger:   shouldTriggerAlert(alert, fetched),
+		}
+		if w.save(ctx, check) {
+			if err := w.notifier.NotifyPriceAlert(ctx, alert); err != nil {
+				w.logger.Errorf("Failed to notify price alert %s: %v", alert.ID, err)
+			}
+		}
+	}
+}
+
+// save persists a check and reports whether it triggered the alert
+func (w *PriceAlertWorker) save(ctx context.Context, check priceCheck) bool {
+	fired, err := w.store.SaveCheck(ctx, check)
+	if err != nil {
+		w.logger.Errorf("Failed to save price alert %s: %v", check.Alert.ID, err)
+		return false
+	}
+
+	check.Alert.LastCheckedAt = &check.CheckedAt
+	if check.Fetched != nil {
+		price := check.Fetched.Price
+		check.Alert.CurrentPrice = &price
+	}
+	if fired {
+		check.Alert.IsTriggered = true
+		check.Alert.TriggeredAt = &check.CheckedAt
+	}
+
+	return fired
+}
+
+// recordFetchFailure backs the domain off after errors the shop may be responsible for
+func (w *PriceAlertWorker) recordFetchFailure(domain string, err error) {
+	var fetchErr *FetchError
+	switch {
+	case errors.As(err, &fetchErr):
+		if fetchErr.StatusCode == 429 || fetchErr.StatusCode >= 500 {
+			w.limiter.Failure(domain, w.now(), fetchErr.RetryAfter)
+			return
+		}
+		w.limiter.Success(domain)
+	case errors.Is(err, ErrPriceNotFound), errors.Is(err, ErrNonPublicAddress):
+		w.limiter.Success(domain)
+	default:
+		w.limiter.Failure(domain, w.now(), 0)
+	}
+}
+
+// shouldTriggerAlert reports whether fetched crosses the alert's target price
+func shouldTriggerAlert(alert *models.PriceAlert, fetched *FetchedPrice) bool {
+	return alert.IsActive && !alert.IsTriggered && fetched.Price <= alert.TargetPrice
+}
+
+// currencyMatches reports whether a fetched currency can be compared with the alert's.
+// Pages that don't state a currency are assumed to use the alert's.
+func currencyMatches(alertCurrency, fetchedCurrency string) bool {
+	return fetchedCurrency == "" || alertCurrency == "" || strings.EqualFold(alertCurrency, fetchedCurrency)
+}
+
+// alertDomain returns the host a product URL is rate limited under
+func alertDomain(productURL string) (string, error) {
+	u, err := url.Parse(productURL)
+	if err != nil {
+		return "", err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return "", fmt.Errorf("unsupported URL %q", productURL)
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."), nil
+}
+
+// sleepContext waits for d and reports false if ctx was cancelled first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// repositoryPriceAlertStore stores price alert checks in the repository
+type repositoryPriceAlertStore struct {
+	store repository.Store
+}
+
+// DueAlerts returns alerts due for a check, least recently checked first
+func (s *repositoryPriceAlertStore) DueAlerts(ctx context.Context, checkedBefore time.Time, limit int) ([]models.PriceAlert, error) {
+	return s.store.WithContext(ctx).PriceAlerts().Due(checkedBefore, limit)
+}
+
+// SaveCheck updates the alert and records the observed price in the item's history.
+// Triggering is conditional on the stored row so an alert fires at most once.
+func (s *repositoryPriceAlertStore) SaveCheck(ctx context.Context, check priceCheck) (bool, error) {
+	fired := false
+
+	err := s.store.WithContext(ctx).Transaction(func(tx repository.Store) error {
+		updates := map[string]interface{}{"last_checked_at": check.CheckedAt}
+		if check.Fetched != nil {
+			updates["current_price"] = check.Fetched.Price
+		}
+
+		if check.Trigger {
+			triggered := map[string]interface{}{"is_triggered": true, "triggered_at": check.CheckedAt}
+			for k, v := range updates {
+				triggered[k] = v
+			}
+			ok, err := tx.PriceAlerts().Trigger(check.Alert.ID, triggered)
+			if err != nil {
+				return fmt.Errorf("failed to trigger alert: %w", err)
+			}
+			fired = ok
+		}
+
+		if !fired {
+			err := tx.PriceAlerts().Update(check.Alert.ID, updates)
+			if errors.Is(err, repository.ErrNotFound) {
+				// The alert was deleted while it was being checked
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to update alert: %w", err)
+			}
+		}
+
+		if check.Fetched == nil || check.Alert.ItemID == nil {
+			return nil
+		}
+
+		currency := check.Fetched.Currency
+		if currency == "" {
+			currency = check.Alert.Currency
+		}
+		return recordPriceChange(tx, models.PriceSnapshot{
+			ItemID:        *check.Alert.ItemID,
+			UserID:        check.Alert.UserID,
+			Price:         check.Fetched.Price,
+			OriginalPrice: check.Fetched.OriginalPrice,
+			Currency:      currency,
+			Source:        models.PriceSourceAlert,
+			ObservedAt:    check.CheckedAt,
+		})
+	})
+
+	return fired, err
+}
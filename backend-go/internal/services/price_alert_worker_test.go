@@ -0,0 +1,374 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"digital-wardrobe-backend/internal/config"
+	"digital-wardrobe-backend/internal/extraction"
+	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
+)
+
+// shopPage renders a product page with JSON-LD pricing
+func shopPage(price float64, currency string) string {
+	return fmt.Sprintf(`<!doctype html><html><head><title>Linen Shirt | Test Shop</title>
+<script type="application/ld+json">
+{"@context":"https://schema.org","@type":"Product","name":"Linen Shirt","brand":{"@type":"Brand","name":"Acme"},
+ "offers":{"@type":"Offer","price":"%.2f","priceCurrency":"%s"}}
+</script></head><body><h1>Linen Shirt</h1></body></html>`, price, currency)
+}
+
+// testShop is a stand-in shop whose prices and failures can be changed per test
+type testShop struct {
+	mu       sync.Mutex
+	prices   map[string]float64
+	currency string
+	// failures responds 429 to the next n requests
+	failures   int
+	retryAfter string
+	requests   int32
+}
+
+func newTestShop(t *testing.T) (*testShop, *httptest.Server) {
+	shop := &testShop{prices: map[string]float64{}, currency: "USD"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&shop.requests, 1)
+
+		shop.mu.Lock()
+		defer shop.mu.Unlock()
+
+		if shop.failures > 0 {
+			shop.failures--
+			if shop.retryAfter != "" {
+				w.Header().Set("Retry-After", shop.retryAfter)
+			}
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		price, ok := shop.prices[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, shopPage(price, shop.currency))
+	}))
+	t.Cleanup(srv.Close)
+	return shop, srv
+}
+
+func (s *testShop) setPrice(path string, price float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prices[path] = price
+}
+
+// newAlertStore returns an in-memory store holding alerts
+func newAlertStore(t *testing.T, alerts ...models.PriceAlert) repository.Store {
+	t.Helper()
+	store := repository.NewMemoryStore()
+	for i := range alerts {
+		if err := store.PriceAlerts().Create(&alerts[i]); err != nil {
+			t.Fatalf("create alert %s: %v", alerts[i].ID, err)
+		}
+	}
+	return store
+}
+
+// getAlert reloads an alert of the test user
+func getAlert(t *testing.T, store repository.Store, id string) models.PriceAlert {
+	t.Helper()
+	alert, err := store.PriceAlerts().Get("u1", id)
+	if err != nil {
+		t.Fatalf("get alert %s: %v", id, err)
+	}
+	return *alert
+}
+
+// recordingNotifier records the alerts it is asked to deliver
+type recordingNotifier struct {
+	mu     sync.Mutex
+	alerts []string
+}
+
+func (n *recordingNotifier) NotifyPriceAlert(ctx context.Context, alert *models.PriceAlert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.alerts = append(n.alerts, alert.ID)
+	return nil
+}
+
+func (n *recordingNotifier) count() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.alerts)
+}
+
+func testWorkerConfig() config.PriceAlertConfig {
+	return config.PriceAlertConfig{
+		Interval:       time.Second,
+		CheckEvery:     0,
+		BatchSize:      50,
+		Concurrency:    2,
+		DomainInterval: 10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+		RequestTimeout: 5 * time.Second,
+		UserAgent:      "test",
+	}
+}
+
+// testFetcher returns a fetcher that may reach the loopback test shop
+func testFetcher() *HTTPPriceFetcher {
+	f := NewHTTPPriceFetcher(extraction.New(), 5*time.Second, "test")
+	f.SetAllowPrivateHosts(true)
+	return f
+}
+
+func TestHTTPPriceFetcherReadsShopPrice(t *testing.T) {
+	shop, srv := newTestShop(t)
+	shop.setPrice("/shirt", 39.5)
+
+	fetched, err := testFetcher().FetchPrice(context.Background(), srv.URL+"/shirt")
+	if err != nil {
+		t.Fatalf("FetchPrice: %v", err)
+	}
+	if fetched.Price != 39.5 || fetched.Currency != "USD" {
+		t.Fatalf("got %.2f %s, want 39.50 USD", fetched.Price, fetched.Currency)
+	}
+}
+
+func TestHTTPPriceFetcherReportsRetryAfter(t *testing.T) {
+	shop, srv := newTestShop(t)
+	shop.setPrice("/shirt", 39.5)
+	shop.failures = 1
+	shop.retryAfter = "120"
+
+	_, err := testFetcher().FetchPrice(context.Background(), srv.URL+"/shirt")
+
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) {
+		t.Fatalf("expected FetchError, got %v", err)
+	}
+	if fetchErr.StatusCode != http.StatusTooManyRequests || fetchErr.RetryAfter != 2*time.Minute {
+		t.Fatalf("got status %d retry %s", fetchErr.StatusCode, fetchErr.RetryAfter)
+	}
+}
+
+func TestHTTPPriceFetcherRefusesNonPublicAddresses(t *testing.T) {
+	shop, srv := newTestShop(t)
+	shop.setPrice("/shirt", 39.5)
+	fetcher := NewHTTPPriceFetcher(extraction.New(), 5*time.Second, "test")
+
+	for _, productURL := range []string{
+		srv.URL + "/shirt",
+		strings.Replace(srv.URL, "127.0.0.1", "localhost", 1) + "/shirt",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/shirt",
+	} {
+		if _, err := fetcher.FetchPrice(context.Background(), productURL); !errors.Is(err, ErrNonPublicAddress) {
+			t.Errorf("FetchPrice(%s) = %v, want ErrNonPublicAddress", productURL, err)
+		}
+	}
+	if n := atomic.LoadInt32(&shop.requests); n != 0 {
+		t.Fatalf("shop received %d requests", n)
+	}
+
+	// Names are checked once resolved
+	for _, address := range []string{"127.0.0.1:80", "10.0.0.5:443", "[fd00::1]:80", "[::ffff:192.168.1.1]:80"} {
+		if err := fetcher.checkDial("tcp", address, nil); !errors.Is(err, ErrNonPublicAddress) {
+			t.Errorf("checkDial(%s) = %v, want ErrNonPublicAddress", address, err)
+		}
+	}
+	if err := fetcher.checkDial("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("checkDial of a public address: %v", err)
+	}
+
+	// Redirects are capped and checked hop by hop
+	redirect, _ := http.NewRequest(http.MethodGet, "http://10.0.0.5/admin", nil)
+	if err := fetcher.checkRedirect(redirect, make([]*http.Request, 1)); !errors.Is(err, ErrNonPublicAddress) {
+		t.Errorf("redirect to a private address = %v, want ErrNonPublicAddress", err)
+	}
+	redirect, _ = http.NewRequest(http.MethodGet, "https://shop.example/shirt", nil)
+	if err := fetcher.checkRedirect(redirect, make([]*http.Request, maxProductPageRedirects)); err == nil {
+		t.Errorf("redirect past the limit was followed")
+	}
+
+	if _, err := testFetcher().FetchPrice(context.Background(), srv.URL+"/shirt"); err != nil {
+		t.Fatalf("FetchPrice with private hosts allowed: %v", err)
+	}
+}
+
+func TestDomainLimiterSpacingAndBackoff(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newDomainLimiter(time.Second, 10*time.Second)
+
+	if wait := l.Reserve("shop.test", now); wait != 0 {
+		t.Fatalf("first reservation waited %s", wait)
+	}
+	if wait := l.Reserve("shop.test", now); wait != time.Second {
+		t.Fatalf("second reservation waited %s, want 1s", wait)
+	}
+	if wait := l.Reserve("other.test", now); wait != 0 {
+		t.Fatalf("other domain waited %s", wait)
+	}
+
+	backoffs := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second}
+	for i, want := range backoffs {
+		if got := l.Failure("shop.test", now, 0); got != want {
+			t.Fatalf("failure %d backed off %s, want %s", i+1, got, want)
+		}
+	}
+	if got := l.Failure("shop.test", now, time.Minute); got != time.Minute {
+		t.Fatalf("retry-after not honoured: %s", got)
+	}
+
+	l.Success("shop.test")
+	if got := l.Failure("shop.test", now, 0); got != 2*time.Second {
+		t.Fatalf("backoff not reset after success: %s", got)
+	}
+}
+
+func TestPriceAlertWorkerFiresOnce(t *testing.T) {
+	shop, srv := newTestShop(t)
+	shop.setPrice("/shirt", 80)
+	shop.setPrice("/dress", 120)
+
+	store := newAlertStore(t,
+		models.PriceAlert{ID: "shirt", UserID: "u1", ProductURL: srv.URL + "/shirt", TargetPrice: 60, Currency: "USD", IsActive: true},
+		models.PriceAlert{ID: "dress", UserID: "u1", ProductURL: srv.URL + "/dress", TargetPrice: 100, Currency: "USD", IsActive: true},
+	)
+	notifier := &recordingNotifier{}
+	worker := NewPriceAlertWorker(store, testFetcher(), notifier, testWorkerConfig())
+	ctx := context.Background()
+
+	if err := worker.RunOnce(ctx); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if notifier.count() != 0 {
+		t.Fatalf("alerts fired above target: %v", notifier.alerts)
+	}
+	if shirt := getAlert(t, store, "shirt"); shirt.CurrentPrice == nil || *shirt.CurrentPrice != 80 || shirt.LastCheckedAt == nil {
+		t.Fatalf("current price not updated: %+v", shirt)
+	}
+
+	shop.setPrice("/shirt", 55)
+	for i := 0; i < 3; i++ {
+		if err := worker.RunOnce(ctx); err != nil {
+			t.Fatalf("RunOnce: %v", err)
+		}
+	}
+
+	if notifier.count() != 1 || notifier.alerts[0] != "shirt" {
+		t.Fatalf("expected shirt to fire once, got %v", notifier.alerts)
+	}
+	if shirt := getAlert(t, store, "shirt"); !shirt.IsTriggered || shirt.TriggeredAt == nil || *shirt.CurrentPrice != 55 {
+		t.Fatalf("shirt not marked triggered: %+v", shirt)
+	}
+	if dress := getAlert(t, store, "dress"); dress.IsTriggered {
+		t.Fatalf("dress triggered above target")
+	}
+}
+
+func TestPriceAlertWorkerIgnoresOtherCurrency(t *testing.T) {
+	shop, srv := newTestShop(t)
+	shop.setPrice("/shirt", 10)
+	shop.currency = "EUR"
+
+	store := newAlertStore(t,
+		models.PriceAlert{ID: "shirt", UserID: "u1", ProductURL: srv.URL + "/shirt", TargetPrice: 60, Currency: "USD", IsActive: true},
+	)
+	notifier := &recordingNotifier{}
+	worker := NewPriceAlertWorker(store, testFetcher(), notifier, testWorkerConfig())
+
+	if err := worker.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if notifier.count() != 0 {
+		t.Fatalf("alert fired on a price in another currency")
+	}
+	if shirt := getAlert(t, store, "shirt"); shirt.CurrentPrice != nil || shirt.LastCheckedAt == nil {
+		t.Fatalf("unexpected alert state: %+v", shirt)
+	}
+}
+
+func TestPriceAlertWorkerBacksOffRateLimitedDomain(t *testing.T) {
+	shop, srv := newTestShop(t)
+	shop.setPrice("/a", 10)
+	shop.setPrice("/b", 10)
+	shop.failures = 1
+	shop.retryAfter = "3600"
+
+	store := newAlertStore(t,
+		models.PriceAlert{ID: "a", UserID: "u1", ProductURL: srv.URL + "/a", TargetPrice: 50, IsActive: true},
+		models.PriceAlert{ID: "b", UserID: "u1", ProductURL: srv.URL + "/b", TargetPrice: 50, IsActive: true},
+	)
+	notifier := &recordingNotifier{}
+	worker := NewPriceAlertWorker(store, testFetcher(), notifier, testWorkerConfig())
+
+	if err := worker.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	// The 429 asked for an hour, longer than the pass interval, so the second alert waits
+	if got := atomic.LoadInt32(&shop.requests); got != 1 {
+		t.Fatalf("shop received %d requests, want 1", got)
+	}
+	if notifier.count() != 0 {
+		t.Fatalf("alerts fired while rate limited: %v", notifier.alerts)
+	}
+}
+
+func TestPriceAlertWorkerStartStop(t *testing.T) {
+	shop, srv := newTestShop(t)
+	shop.setPrice("/shirt", 20)
+
+	store := newAlertStore(t,
+		models.PriceAlert{ID: "shirt", UserID: "u1", ProductURL: srv.URL + "/shirt", TargetPrice: 25, IsActive: true},
+	)
+	notifier := &recordingNotifier{}
+	cfg := testWorkerConfig()
+	cfg.Interval = 10 * time.Millisecond
+	worker := NewPriceAlertWorker(store, testFetcher(), notifier, cfg)
+
+	worker.Start(context.Background())
+
+	deadline := time.Now().Add(2 * time.Second)
+	for notifier.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := worker.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if notifier.count() != 1 {
+		t.Fatalf("expected one notification, got %d", notifier.count())
+	}
+}
+
+func TestPriceAlertWorkerDefaultsNonPositiveInterval(t *testing.T) {
+	cfg := testWorkerConfig()
+	cfg.Interval = 0
+	worker := NewPriceAlertWorker(repository.NewMemoryStore(), testFetcher(), &recordingNotifier{}, cfg)
+	if worker.cfg.Interval != time.Minute {
+		t.Fatalf("interval = %s, want 1m", worker.cfg.Interval)
+	}
+
+	worker.Start(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := worker.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"digital-wardrobe-backend/internal/extraction"
+)
+
+const (
+	// maxProductPageBytes limits how much of a product page the fetcher reads
+	maxProductPageBytes = 5 << 20
+	// maxProductPageRedirects limits how many redirects a fetch follows
+	maxProductPageRedirects = 5
+)
+
+var (
+	// ErrPriceNotFound is returned when a product page has no recognizable price
+	ErrPriceNotFound = errors.New("no price found on product page")
+	// ErrNonPublicAddress is returned when a product URL points at a loopback, private or link-local address
+	ErrNonPublicAddress = errors.New("product URL does not resolve to a public address")
+)
+
+// reservedPrefixes are non-public ranges not covered by the netip.Addr predicates
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// FetchedPrice is a price read from a product page
+type FetchedPrice struct {
+	Price         float64
+	OriginalPrice *float64
+	Currency      string
+}
+
+// PriceFetcher fetches the current price of a product page
+type PriceFetcher interface {
+	FetchPrice(ctx context.Context, productURL string) (*FetchedPrice, error)
+}
+
+// FetchError is returned when a shop responds with a non-success status
+type FetchError struct {
+	StatusCode int
+	// RetryAfter is the delay requested by the shop, if any
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *FetchError) Error() string {
+	return fmt.Sprintf("product page returned status %d", e.StatusCode)
+}
+
+// HTTPPriceFetcher fetches product pages over HTTP and reads prices with the
+// extraction package. Only public addresses are fetched, checked after DNS
+// resolution and on every redirect, so user-supplied URLs cannot reach
+// internal services.
+type HTTPPriceFetcher struct {
+	client            *http.Client
+	extractor         *extraction.Extractor
+	userAgent         string
+	allowPrivateHosts bool
+}
+
+// NewHTTPPriceFetcher creates a new HTTPPriceFetcher
+func NewHTTPPriceFetcher(extractor *extraction.Extractor, timeout time.Duration, userAgent string) *HTTPPriceFetcher {
+	f := &HTTPPriceFetcher{
+		extractor: extractor,
+		userAgent: userAgent,
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: f.checkDial}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the shop, bypassing the address check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	f.client = &http.Client{
+		Timeout:       timeout,
+		Transport:     transport,
+		CheckRedirect: f.checkRedirect,
+	}
+	return f
+}
+
+// SetAllowPrivateHosts allows fetching loopback and private addresses, e.g. a
+// shop running on the developer's machine
+func (f *HTTPPriceFetcher) SetAllowPrivateHosts(allow bool) {
+	f.allowPrivateHosts = allow
+}
+
+// checkDial rejects connections to non-public addresses once DNS has resolved them
+func (f *HTTPPriceFetcher) checkDial(network, address string, _ syscall.RawConn) error {
+	if f.allowPrivateHosts {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, address)
+	}
+	if !isPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// checkRedirect caps redirects and applies the URL checks to each hop
+func (f *HTTPPriceFetcher) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxProductPageRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxProductPageRedirects)
+	}
+	return f.checkURL(req.URL)
+}
+
+// checkURL rejects non-http(s) URLs and hosts that are known to be private
+// before anything is resolved
+func (f *HTTPPriceFetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	if !f.allowPrivateHosts && isPrivateHost(u.Hostname()) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, u.Hostname())
+	}
+	return nil
+}
+
+// FetchPrice fetches productURL and extracts its current price
+func (f *HTTPPriceFetcher) FetchPrice(ctx context.Context, productURL string) (*FetchedPrice, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, productURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if err := f.checkURL(req.URL); err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch product page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &FetchError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProductPageBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read product page: %w", err)
+	}
+
+	result, err := f.extractor.Extract(string(body), resp.Request.URL.String())
+	if err != nil {
+		return nil, err
+	}
+	if result.Item.Price == nil {
+		return nil, ErrPriceNotFound
+	}
+
+	fetched := &FetchedPrice{
+		Price:         *result.Item.Price,
+		OriginalPrice: result.Item.OriginalPrice,
+	}
+	if result.Item.Currency != nil {
+		fetched.Currency = strings.ToUpper(*result.Item.Currency)
+	}
+
+	return fetched, nil
+}
+
+// isPrivateHost reports whether host is localhost or a non-public IP literal.
+// Other names can only be checked once they are resolved.
+func isPrivateHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return !isPublicAddr(addr)
+	}
+	return false
+}
+
+// isPublicAddr reports whether addr is a globally routable unicast address
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"digital-wardrobe-backend/internal/apperror"
+	"digital-wardrobe-backend/internal/ids"
+	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
+	"digital-wardrobe-backend/pkg/logger"
+)
+
+const (
+	// DefaultPriceHistoryDays is the history window returned when none is requested
+	DefaultPriceHistoryDays = 180
+	// MaxPriceHistoryDays caps the history window a client may request
+	MaxPriceHistoryDays = 730
+)
+
+// lowestPriceWindows are the "lowest in N days" windows always reported
+var lowestPriceWindows = []int{7, 30, 90}
+
+// ErrInvalidPrice is returned when a recorded price fails validation
+var ErrInvalidPrice = apperror.Validation(apperror.CodeValidation, "invalid price data")
+
+// PriceHistoryService handles price history operations
+type PriceHistoryService struct {
+	store  repository.Store
+	logger logger.Logger
+}
+
+// NewPriceHistoryService creates a new PriceHistoryService
+func NewPriceHistoryService(store repository.Store) *PriceHistoryService {
+	return &PriceHistoryService{
+		store:  store,
+		logger: logger.New("price_history"),
+	}
+}
+
+// PriceObservation represents a price observed for an item
+type PriceObservation struct {
+	Price         *float64   `json:"price" binding:"required"`
+	OriginalPrice *float64   `json:"originalPrice"`
+	Currency      string     `json:"currency"`
+	ObservedAt    *time.Time `json:"observedAt"`
+}
+
+// PriceHistoryOptions selects the currency and window of a price history
+type PriceHistoryOptions struct {
+	Currency string `form:"currency"`
+	Days     int    `form:"days"`
+}
+
+// PriceStats summarizes the prices in a window
+type PriceStats struct {
+	Current        float64   `json:"current"`
+	Min            float64   `json:"min"`
+	Max            float64   `json:"max"`
+	Median         float64   `json:"median"`
+	Count          int       `json:"count"`
+	FirstObserved  time.Time `json:"firstObservedAt"`
+	LastObserved   time.Time `json:"lastObservedAt"`
+	ChangeFromPeak float64   `json:"changeFromPeak"` // fraction below Max, e.g. 0.25 = 25% off peak
+}
+
+// LowestPriceWindow reports whether the current price is the lowest seen in a window
+type LowestPriceWindow struct {
+	Days     int     `json:"days"`
+	Lowest   float64 `json:"lowest"`
+	IsLowest bool    `json:"isLowest"`
+	// FullWindow is false when tracking started less than Days ago
+	FullWindow bool `json:"fullWindow"`
+}
+
+// PriceHistory is an item's price history in one currency
+type PriceHistory struct {
+	ItemID    string                 `json:"itemId"`
+	Currency  string                 `json:"currency"`
+	Days      int                    `json:"days"`
+	Snapshots []models.PriceSnapshot `json:"snapshots"`
+	Stats     *PriceStats            `json:"stats"`
+	LowestIn  []LowestPriceWindow    `json:"lowestIn"`
+}
+
+// RecordPrice records an observed price for one of the user's items
+func (s *PriceHistoryService) RecordPrice(userID, itemID, source string, obs PriceObservation) (*models.PriceSnapshot, error) {
+	if obs.Price == nil {
+		return nil, ErrInvalidPrice.Field("price", "price is required")
+	}
+	if *obs.Price < 0 || (obs.OriginalPrice != nil && *obs.OriginalPrice < 0) {
+		return nil, ErrInvalidPrice.Field("price", "prices must not be negative")
+	}
+
+	if !ids.Valid(itemID) {
+		return nil, ErrItemNotFound
+	}
+
+	item, err := s.store.Items().Get(userID, itemID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrItemNotFound
+		}
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+
+	currency := strings.ToUpper(obs.Currency)
+	if currency == "" {
+		currency = item.Currency
+	}
+
+	observedAt := time.Now()
+	if obs.ObservedAt != nil {
+		if obs.ObservedAt.After(observedAt) {
+			return nil, ErrInvalidPrice.Field("observedAt", "observedAt must not be in the future")
+		}
+		observedAt = *obs.ObservedAt
+	}
+
+	snapshot := models.PriceSnapshot{
+		ItemID:        item.ID,
+		UserID:        userID,
+		Price:         *obs.Price,
+		OriginalPrice: obs.OriginalPrice,
+		Currency:      currency,
+		Source:        source,
+		ObservedAt:    observedAt,
+	}
+	if err := s.store.PriceSnapshots().Create(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to record price: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// GetPriceHistory returns an item's price history with summary statistics
+func (s *PriceHistoryService) GetPriceHistory(userID, itemID string, opts PriceHistoryOptions) (*PriceHistory, error) {
+	if !ids.Valid(itemID) {
+		return nil, ErrItemNotFound
+	}
+
+	item, err := s.store.Items().Get(userID, itemID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrItemNotFound
+		}
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+
+	days := opts.Days
+	if days <= 0 {
+		days = DefaultPriceHistoryDays
+	}
+	if days > MaxPriceHistoryDays {
+		days = MaxPriceHistoryDays
+	}
+
+	currency := strings.ToUpper(opts.Currency)
+	if currency == "" {
+		currency = item.Currency
+	}
+
+	// Load enough history for the requested window and every lowest-price window
+	lookback := days
+	for _, w := range lowestPriceWindows {
+		if w > lookback {
+			lookback = w
+		}
+	}
+	now := time.Now()
+
+	snapshots, err := s.store.PriceSnapshots().ListSince(item.ID, currency, now.AddDate(0, 0, -lookback))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+
+	var firstEver time.Time
+	if len(snapshots) > 0 {
+		first, err := s.store.PriceSnapshots().Earliest(item.ID, currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get price history: %w", err)
+		}
+		firstEver = first.ObservedAt
+	}
+
+	history := &PriceHistory{
+		ItemID:    item.ID,
+		Currency:  currency,
+		Days:      days,
+		Snapshots: snapshotsSince(snapshots, now.AddDate(0, 0, -days)),
+		LowestIn:  []LowestPriceWindow{},
+	}
+	if history.Snapshots == nil {
+		history.Snapshots = []models.PriceSnapshot{}
+	}
+	history.Stats = computePriceStats(history.Snapshots)
+
+	if len(snapshots) == 0 {
+		return history, nil
+	}
+
+	current := snapshots[len(snapshots)-1].Price
+	windows := append([]int{}, lowestPriceWindows...)
+	if !containsInt(windows, days) {
+		windows = append(windows, days)
+	}
+	sort.Ints(windows)
+
+	for _, w := range windows {
+		since := now.AddDate(0, 0, -w)
+		lowest := current
+		for _, snap := range snapshotsSince(snapshots, since) {
+			lowest = math.Min(lowest, snap.Price)
+		}
+		history.LowestIn = append(history.LowestIn, LowestPriceWindow{
+			Days:       w,
+			Lowest:     lowest,
+			IsLowest:   current <= lowest,
+			FullWindow: !firstEver.After(since),
+		})
+	}
+
+	return history, nil
+}
+
+// recordItemPrice records the item's own price as a snapshot when it differs
+// from the most recent snapshot in the same currency
+func recordItemPrice(tx repository.Store, item *models.Item) error {
+	if item.Price == nil {
+		return nil
+	}
+
+	return recordPriceChange(tx, models.PriceSnapshot{
+		ItemID:        item.ID,
+		UserID:        item.UserID,
+		Price:         *item.Price,
+		OriginalPrice: item.OriginalPrice,
+		Currency:      item.Currency,
+		Source:        models.PriceSourceItem,
+		ObservedAt:    time.Now(),
+	})
+}
+
+// recordPriceChange stores snapshot unless it repeats the most recent snapshot
+// for the same item and currency
+func recordPriceChange(tx repository.Store, snapshot models.PriceSnapshot) error {
+	last, err := tx.PriceSnapshots().Latest(snapshot.ItemID, snapshot.Currency)
+	if err == nil && last.Price == snapshot.Price && equalFloatPtr(last.OriginalPrice, snapshot.OriginalPrice) {
+		return nil
+	}
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("failed to get latest price: %w", err)
+	}
+
+	if err := tx.PriceSnapshots().Create(&snapshot); err != nil {
+		return fmt.Errorf("failed to record price: %w", err)
+	}
+
+	return nil
+}
+
+// computePriceStats summarizes snapshots ordered by observation time
+func computePriceStats(snapshots []models.PriceSnapshot) *PriceStats {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	prices := make([]float64, len(snapshots))
+	for i, snap := range snapshots {
+		prices[i] = snap.Price
+	}
+	sort.Float64s(prices)
+
+	median := prices[len(prices)/2]
+	if len(prices)%2 == 0 {
+		median = (prices[len(prices)/2-1] + prices[len(prices)/2]) / 2
+	}
+
+	stats := &PriceStats{
+		Current:       snapshots[len(snapshots)-1].Price,
+		Min:           prices[0],
+		Max:           prices[len(prices)-1],
+		Median:        math.Round(median*100) / 100,
+		Count:         len(snapshots),
+		FirstObserved: snapshots[0].ObservedAt,
+		LastObserved:  snapshots[len(snapshots)-1].ObservedAt,
+	}
+	if stats.Max > 0 {
+		stats.ChangeFromPeak = math.Round((stats.Max-stats.Current)/stats.Max*10000) / 10000
+	}
+
+	return stats
+}
+
+// snapshotsSince returns the suffix of time-ordered snapshots observed at or after since
+func snapshotsSince(snapshots []models.PriceSnapshot, since time.Time) []models.PriceSnapshot {
+	i := sort.Search(len(snapshots), func(i int) bool {
+		return !snapshots[i].ObservedAt.Before(since)
+	})
+	return snapshots[i:]
+}
+
+// equalFloatPtr reports whether two optional floats are equal
+func equalFloatPtr(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// containsInt reports whether values contains value
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimiter counts attempts per key over a sliding window
+type RateLimiter interface {
+	// Allow records an attempt under key unless limit attempts were already
+	// recorded within window. When the attempt is refused it returns how long
+	// until the next one would be allowed.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+	// Reset forgets the attempts recorded under key
+	Reset(ctx context.Context, key string) error
+}
+
+// RedisRateLimiter is a RateLimiter shared by every instance through Redis.
+// Each key is a sorted set of attempt timestamps.
+type RedisRateLimiter struct {
+	client *redis.Client
+}
+
+// NewRedisRateLimiter creates a RedisRateLimiter
+func NewRedisRateLimiter(rc *RedisClient) *RedisRateLimiter {
+	return &RedisRateLimiter{client: rc.client}
+}
+
+// slidingWindowScript prunes attempts older than the window, then records the
+// attempt if fewer than limit remain. It returns 0 when the attempt was
+// recorded, otherwise the milliseconds until the oldest attempt leaves the window.
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+if redis.call('ZCARD', KEYS[1]) < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	return 0
+end
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return math.max(1, tonumber(oldest[2]) + window - now)
+`)
+
+// Allow implements RateLimiter
+func (l *RedisRateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	now := time.Now().UnixMilli()
+	// Attempts in the same millisecond need distinct members
+	nonce := make([]byte, 4)
+	if _, err := rand.Read(nonce); err != nil {
+		return false, 0, err
+	}
+	member := strconv.FormatInt(now, 10) + "-" + hex.EncodeToString(nonce)
+
+	wait, err := slidingWindowScript.Run(ctx, l.client, []string{"ratelimit:" + key},
+		now, window.Milliseconds(), limit, member).Int64()
+	if err != nil {
+		return false, 0, err
+	}
+	if wait > 0 {
+		return false, time.Duration(wait) * time.Millisecond, nil
+	}
+	return true, 0, nil
+}
+
+// Reset implements RateLimiter
+func (l *RedisRateLimiter) Reset(ctx context.Context, key string) error {
+	return l.client.Del(ctx, "ratelimit:"+key).Err()
+}
+
+// MemoryRateLimiter is a RateLimiter for a single instance, used when Redis
+// is not configured
+type MemoryRateLimiter struct {
+	mu        sync.Mutex
+	attempts  map[string]*attemptLog
+	lastSweep time.Time
+}
+
+// attemptLog holds the attempts recorded under one key, oldest first
+type attemptLog struct {
+	times  []time.Time
+	window time.Duration
+}
+
+// memorySweepInterval is how often keys with no recent attempts are dropped
+const memorySweepInterval = time.Minute
+
+// NewMemoryRateLimiter creates a MemoryRateLimiter
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		attempts:  make(map[string]*attemptLog),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow implements RateLimiter
+func (l *MemoryRateLimiter) Allow(_ context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) >= memorySweepInterval {
+		l.sweep(now)
+	}
+
+	log, ok := l.attempts[key]
+	if !ok {
+		log = &attemptLog{}
+		l.attempts[key] = log
+	}
+	log.window = window
+	log.prune(now)
+
+	if len(log.times) >= limit {
+		return false, log.times[0].Add(window).Sub(now), nil
+	}
+	log.times = append(log.times, now)
+	return true, 0, nil
+}
+
+// Reset implements RateLimiter
+func (l *MemoryRateLimiter) Reset(_ context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.attempts, key)
+	return nil
+}
+
+// sweep drops keys whose attempts have all left their window
+func (l *MemoryRateLimiter) sweep(now time.Time) {
+	for key, log := range l.attempts {
+		if log.prune(now); len(log.times) == 0 {
+			delete(l.attempts, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// prune drops attempts older than the window
+func (a *attemptLog) prune(now time.Time) {
+	cutoff := now.Add(-a.window)
+	i := 0
+	for i < len(a.times) && !a.times[i].After(cutoff) {
+		i++
+	}
+	a.times = a.times[i:]
+}
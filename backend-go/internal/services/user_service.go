@@ -2,29 +2,28 @@ package services
 
 import (
 	"digital-wardrobe-backend/internal/models"
+	"digital-wardrobe-backend/internal/repository"
 	"digital-wardrobe-backend/pkg/logger"
-
-	"gorm.io/gorm"
 )
 
 // UserService handles user operations
 type UserService struct {
-	db     *gorm.DB
+	store  repository.Store
 	logger logger.Logger
 }
 
 // NewUserService creates a new UserService
-func NewUserService(db *gorm.DB) *UserService {
+func NewUserService(store repository.Store) *UserService {
 	return &UserService{
-		db:     db,
+		store:  store,
 		logger: logger.New("user"),
 	}
 }
 
 // GetUserByID gets a user by ID
 func (s *UserService) GetUserByID(userID string) (*models.SafeUser, error) {
-	var user models.User
-	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+	user, err := s.store.Users().Get(userID)
+	if err != nil {
 		return nil, err
 	}
 	return user.ToSafeUser(), nil
@@ -0,0 +1,105 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current responses")
+
+var (
+	uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	jwtPattern  = regexp.MustCompile(`^eyJ[\w-]*\.[\w-]+\.[\w-]+$`)
+	timePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$`)
+)
+
+// opaqueKeys are fields whose non-empty values are replaced wholesale
+var opaqueKeys = map[string]string{
+	"nextCursor": "<cursor>",
+}
+
+// Golden compares the response body with testdata/<name>.golden.json after
+// normalizing the values that change between runs: IDs become <id:N>,
+// numbered in order of appearance, tokens become <jwt>, timestamps <time>
+// and page cursors <cursor>.
+// Run the tests with -update to rewrite the file.
+func (r *Response) Golden(name string) {
+	r.t.Helper()
+
+	var body interface{}
+	if err := json.Unmarshal(r.Body, &body); err != nil {
+		r.t.Fatalf("%s: response is not JSON: %v\n%s", r.label, err, r.Body)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(normalize(body, map[string]string{})); err != nil {
+		r.t.Fatalf("encode normalized response: %v", err)
+	}
+	got := buf.Bytes()
+
+	path := filepath.Join("testdata", name+".golden.json")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			r.t.Fatalf("create testdata: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			r.t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		r.t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		r.t.Errorf("%s: response differs from %s (run with -update to accept it)\ngot:\n%s\nwant:\n%s", r.label, path, got, want)
+	}
+}
+
+// normalize replaces volatile values in a decoded JSON value. Object keys
+// are visited in sorted order so IDs are numbered deterministically.
+func normalize(v interface{}, ids map[string]string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if placeholder, ok := opaqueKeys[k]; ok && v[k] != "" {
+				v[k] = placeholder
+				continue
+			}
+			v[k] = normalize(v[k], ids)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = normalize(v[i], ids)
+		}
+		return v
+	case string:
+		switch {
+		case uuidPattern.MatchString(v):
+			if _, ok := ids[v]; !ok {
+				ids[v] = fmt.Sprintf("<id:%d>", len(ids)+1)
+			}
+			return ids[v]
+		case jwtPattern.MatchString(v):
+			return "<jwt>"
+		case timePattern.MatchString(v):
+			return "<time>"
+		}
+	}
+	return v
+}
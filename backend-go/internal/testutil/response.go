@@ -0,0 +1,96 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Envelope is the JSON shape every API response shares
+type Envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Message string          `json:"message,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Code    string          `json:"code,omitempty"`
+	Details json.RawMessage `json:"details,omitempty"`
+
+	t *testing.T
+}
+
+// Decode unmarshals the envelope's data into v
+func (e Envelope) Decode(v interface{}) {
+	e.t.Helper()
+	if len(e.Data) == 0 {
+		e.t.Fatalf("response has no data")
+	}
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		e.t.Fatalf("decode data: %v\n%s", err, e.Data)
+	}
+}
+
+// Response is a recorded API response
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+
+	t     *testing.T
+	label string
+}
+
+// Envelope decodes the response body
+func (r *Response) Envelope() Envelope {
+	r.t.Helper()
+	env := Envelope{t: r.t}
+	if err := json.Unmarshal(r.Body, &env); err != nil {
+		r.t.Fatalf("%s: response is not an envelope: %v\n%s", r.label, err, r.Body)
+	}
+	return env
+}
+
+// Decode unmarshals the whole response body into v, for responses that
+// carry more than data, such as pagination
+func (r *Response) Decode(v interface{}) {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.t.Fatalf("%s: decode response: %v\n%s", r.label, err, r.Body)
+	}
+}
+
+// ExpectSuccess fails the test unless the response has status and
+// "success": true, and returns its envelope
+func (r *Response) ExpectSuccess(status int) Envelope {
+	r.t.Helper()
+	r.expectStatus(status)
+	env := r.Envelope()
+	if !env.Success {
+		r.t.Fatalf("%s: success = false (%s: %s)", r.label, env.Code, env.Error)
+	}
+	return env
+}
+
+// ExpectError fails the test unless the response has status, "success":
+// false and the error code, and returns its envelope
+func (r *Response) ExpectError(status int, code string) Envelope {
+	r.t.Helper()
+	r.expectStatus(status)
+	env := r.Envelope()
+	if env.Success {
+		r.t.Fatalf("%s: success = true, want error %s", r.label, code)
+	}
+	if env.Code != code {
+		r.t.Fatalf("%s: code = %q, want %q (%s)", r.label, env.Code, code, env.Error)
+	}
+	if env.Error == "" {
+		r.t.Errorf("%s: error message is empty", r.label)
+	}
+	return env
+}
+
+func (r *Response) expectStatus(status int) {
+	r.t.Helper()
+	if r.Status != status {
+		r.t.Fatalf("%s: status = %d, want %d\n%s", r.label, r.Status, status, r.Body)
+	}
+}
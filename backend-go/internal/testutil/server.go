@@ -0,0 +1,183 @@
+// Package testutil boots the full API against an in-memory store for
+// end-to-end tests, with helpers for signing in, making requests and
+// checking the {success, data, error, code} envelope.
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"digital-wardrobe-backend/internal/extraction"
+	"digital-wardrobe-backend/internal/repository"
+	"digital-wardrobe-backend/internal/routes"
+	"digital-wardrobe-backend/internal/services"
+	"digital-wardrobe-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIPrefix is the prefix the test router mounts the API under
+const APIPrefix = "/api/v1"
+
+// DefaultPassword is the password Register gives users
+const DefaultPassword = "correct-horse-battery"
+
+// testArgon2Params keep password hashing cheap in tests
+var testArgon2Params = services.Argon2Params{Time: 1, Memory: 1024, Threads: 1, SaltLength: 16, KeyLength: 32}
+
+// Server is the API router wired to an in-memory store
+type Server struct {
+	Router *gin.Engine
+	Store  *repository.MemoryStore
+	Auth   *services.AuthService
+	Mailer *services.OutboxMailer
+
+	t *testing.T
+}
+
+// NewServer builds the services, handlers and router the way main does,
+// backed by a fresh in-memory store
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store := repository.NewMemoryStore()
+	mailer := services.NewOutboxMailer("", "no-reply@localhost")
+
+	authService := services.NewAuthService(store, "test-secret", 15*time.Minute, 24*time.Hour)
+	authService.SetMailer(mailer, "http://localhost:3000")
+	if err := authService.SetPasswordHashing(testArgon2Params); err != nil {
+		t.Fatalf("configure password hashing: %v", err)
+	}
+
+	handlers := routes.New(
+		authService,
+		services.NewUserService(store),
+		services.NewItemService(store),
+		services.NewCollectionService(store),
+		services.NewAnalyticsService(store),
+		services.NewPriceHistoryService(store),
+		services.NewPriceAlertService(store),
+		extraction.New(),
+		nil,
+	)
+	router := routes.NewRouter(handlers, routes.RouterOptions{
+		APIPrefix:      APIPrefix,
+		CORSOrigins:    []string{"http://localhost:3000"},
+		RequestTimeout: 30 * time.Second,
+		Logger:         logger.New("error"),
+	})
+
+	return &Server{Router: router, Store: store, Auth: authService, Mailer: mailer, t: t}
+}
+
+// User is a signed-in test user
+type User struct {
+	ID           string
+	Email        string
+	Password     string
+	Token        string
+	RefreshToken string
+}
+
+// authData is the data of a successful register or login
+type authData struct {
+	User struct {
+		ID    string `json:"id"`
+		Email string `json:"email"`
+	} `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Register creates an account with DefaultPassword and returns it signed in
+func (s *Server) Register(email string) *User {
+	s.t.Helper()
+	resp := s.Do(http.MethodPost, "/auth/register", map[string]string{
+		"email":     email,
+		"password":  DefaultPassword,
+		"firstName": "Test",
+		"lastName":  "User",
+	})
+
+	var data authData
+	resp.ExpectSuccess(http.StatusCreated).Decode(&data)
+	return &User{ID: data.User.ID, Email: email, Password: DefaultPassword, Token: data.Token, RefreshToken: data.RefreshToken}
+}
+
+// Login signs in with a password and returns the new session
+func (s *Server) Login(email, password string) *User {
+	s.t.Helper()
+	resp := s.Do(http.MethodPost, "/auth/login", map[string]string{"email": email, "password": password})
+
+	var data authData
+	resp.ExpectSuccess(http.StatusOK).Decode(&data)
+	return &User{ID: data.User.ID, Email: email, Password: password, Token: data.Token, RefreshToken: data.RefreshToken}
+}
+
+// Do sends an unauthenticated request. path is relative to APIPrefix unless
+// it starts with "//", which addresses the router root ("//health").
+// body is sent as JSON unless it is nil.
+func (s *Server) Do(method, path string, body interface{}) *Response {
+	s.t.Helper()
+	return s.send(method, path, body, "", nil)
+}
+
+// DoAs sends a request with user's access token
+func (s *Server) DoAs(user *User, method, path string, body interface{}) *Response {
+	s.t.Helper()
+	return s.send(method, path, body, user.Token, nil)
+}
+
+// DoWithHeader sends an unauthenticated request with extra headers. Requests
+// come from 192.0.2.1 unless a trusted proxy header says otherwise.
+func (s *Server) DoWithHeader(method, path string, body interface{}, header http.Header) *Response {
+	s.t.Helper()
+	return s.send(method, path, body, "", header)
+}
+
+func (s *Server) send(method, path string, body interface{}, token string, header http.Header) *Response {
+	s.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatalf("encode request body: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	url := APIPrefix + path
+	if strings.HasPrefix(path, "//") {
+		url = path[1:]
+	}
+	req := httptest.NewRequest(method, url, reader)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.RemoteAddr = "192.0.2.1:1234"
+
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+
+	return &Response{
+		Status: rec.Code,
+		Header: rec.Header(),
+		Body:   rec.Body.Bytes(),
+		t:      s.t,
+		label:  method + " " + path,
+	}
+}
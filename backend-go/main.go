@@ -11,7 +11,8 @@ import (
 
 	"digital-wardrobe-backend/internal/config"
 	"digital-wardrobe-backend/internal/database"
-	"digital-wardrobe-backend/internal/middleware"
+	"digital-wardrobe-backend/internal/extraction"
+	"digital-wardrobe-backend/internal/repository"
 	"digital-wardrobe-backend/internal/routes"
 	"digital-wardrobe-backend/internal/services"
 	"digital-wardrobe-backend/pkg/logger"
@@ -50,6 +51,22 @@ func main() {
 		}
 	}()
 
+	// "migrate up|down|status" manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), db, os.Args[2:]); err != nil {
+			logger.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	if cfg.Database.AutoMigrate {
+		if err := database.Migrate(context.Background(), db); err != nil {
+			logger.Fatalf("Failed to migrate database: %v", err)
+		}
+	} else {
+		warnPendingMigrations(context.Background(), db, logger)
+	}
+
 	// Initialize Redis (optional)
 	var redisClient *services.RedisClient
 	if cfg.Redis.URL != "" {
@@ -62,11 +79,103 @@ func main() {
 	}
 
 	// Initialize services
-	authService := services.NewAuthService(db, cfg.JWT.Secret, cfg.JWT.Expiration)
-	userService := services.NewUserService(db)
-	itemService := services.NewItemService(db)
-	collectionService := services.NewCollectionService(db)
-	analyticsService := services.NewAnalyticsService(db)
+	store := repository.NewGormStore(db)
+	authService := services.NewAuthService(store, cfg.JWT.Secret, cfg.JWT.Expiration, cfg.JWT.RefreshExpiration)
+	// Configure third-party sign-in
+	var googleKeys services.KeySource = services.NewRemoteKeySource(cfg.Google.JWKSURL, nil, cfg.Google.JWKSCacheTTL)
+	if cfg.Google.JWKSFile != "" {
+		if googleKeys, err = services.NewFileKeySource(cfg.Google.JWKSFile); err != nil {
+			logger.Fatalf("Failed to load Google JWKS: %v", err)
+		}
+	}
+	if len(cfg.Google.ClientIDs) > 0 {
+		authService.SetGoogleVerifier(services.NewGoogleVerifier(googleKeys, cfg.Google.ClientIDs))
+	}
+	var oauthProviders []services.OAuthProvider
+	if cfg.OAuth.Google.ClientID != "" {
+		oauthProviders = append(oauthProviders, services.NewGoogleOAuthProvider(cfg.OAuth.Google, googleKeys))
+	}
+	if cfg.OAuth.Facebook.ClientID != "" {
+		oauthProviders = append(oauthProviders, services.NewFacebookOAuthProvider(cfg.OAuth.Facebook))
+	}
+	if cfg.OAuth.Apple.ClientID != "" {
+		apple, err := services.NewAppleOAuthProvider(cfg.OAuth.Apple, nil)
+		if err != nil {
+			logger.Fatalf("Failed to configure Sign in with Apple: %v", err)
+		}
+		oauthProviders = append(oauthProviders, apple)
+	}
+	for _, provider := range oauthProviders {
+		if err := authService.RegisterOAuthProvider(provider); err != nil {
+			logger.Fatalf("Failed to register OAuth provider: %v", err)
+		}
+	}
+
+	// Configure account emails
+	var mailer services.Mailer
+	if cfg.Mail.Driver == "smtp" {
+		mailer = services.NewSMTPMailer(cfg.Mail.SMTPHost, cfg.Mail.SMTPPort, cfg.Mail.SMTPUsername, cfg.Mail.SMTPPassword, cfg.Mail.From)
+	} else {
+		mailer = services.NewOutboxMailer(cfg.Mail.OutboxDir, cfg.Mail.From)
+	}
+	authService.SetMailer(mailer, cfg.Mail.AppURL)
+	authService.SetEmailVerification(services.EmailTokenOptions{
+		TokenTTL:       cfg.Verification.TokenTTL,
+		ResendInterval: cfg.Verification.ResendInterval,
+	})
+	authService.SetPasswordReset(services.EmailTokenOptions{
+		TokenTTL:       cfg.PasswordReset.TokenTTL,
+		ResendInterval: cfg.PasswordReset.ResendInterval,
+	})
+
+	// Configure sign-in protection; limits are shared across instances through Redis when available
+	var loginLimiter services.RateLimiter = services.NewMemoryRateLimiter()
+	if redisClient != nil {
+		loginLimiter = services.NewRedisRateLimiter(redisClient)
+	}
+	authService.SetLoginProtection(services.LoginProtectionOptions{
+		Limiter:            loginLimiter,
+		Window:             cfg.Login.RateLimitWindow,
+		IPLimit:            cfg.Login.IPLimit,
+		EmailLimit:         cfg.Login.EmailLimit,
+		LockoutThreshold:   cfg.Login.LockoutThreshold,
+		LockoutDuration:    cfg.Login.LockoutDuration,
+		MaxLockoutDuration: cfg.Login.MaxLockoutDuration,
+	})
+
+	if cfg.MFA.EncryptionKey != "" {
+		if err := authService.SetMFAEncryptionKey(cfg.MFA.EncryptionKey); err != nil {
+			logger.Fatalf("Failed to configure two-factor authentication: %v", err)
+		}
+	}
+
+	if err := authService.SetPasswordHashing(services.Argon2Params{
+		Time:       uint32(cfg.PasswordHash.Time),
+		Memory:     uint32(cfg.PasswordHash.MemoryKiB),
+		Threads:    uint8(cfg.PasswordHash.Threads),
+		SaltLength: uint32(cfg.PasswordHash.SaltLength),
+		KeyLength:  uint32(cfg.PasswordHash.KeyLength),
+	}); err != nil {
+		logger.Fatalf("Failed to configure password hashing: %v", err)
+	}
+
+	userService := services.NewUserService(store)
+	itemService := services.NewItemService(store)
+	collectionService := services.NewCollectionService(store)
+	collectionService.SetRequireVerifiedEmail(cfg.Verification.RequiredForPublicCollections)
+	analyticsService := services.NewAnalyticsService(store)
+	priceHistoryService := services.NewPriceHistoryService(store)
+	priceAlertService := services.NewPriceAlertService(store)
+	priceAlertService.SetAllowPrivateHosts(cfg.PriceAlerts.AllowPrivate)
+	extractor := extraction.New()
+
+	// Initialize background workers
+	var priceAlertWorker *services.PriceAlertWorker
+	if cfg.PriceAlerts.Enabled {
+		fetcher := services.NewHTTPPriceFetcher(extractor, cfg.PriceAlerts.RequestTimeout, cfg.PriceAlerts.UserAgent)
+		fetcher.SetAllowPrivateHosts(cfg.PriceAlerts.AllowPrivate)
+		priceAlertWorker = services.NewPriceAlertWorker(store, fetcher, services.NewLogPriceAlertNotifier(), cfg.PriceAlerts)
+	}
 
 	// Initialize handlers
 	handlers := routes.New(
@@ -75,6 +184,9 @@ func main() {
 		itemService,
 		collectionService,
 		analyticsService,
+		priceHistoryService,
+		priceAlertService,
+		extractor,
 		redisClient,
 	)
 
@@ -84,27 +196,12 @@ func main() {
 		gin.SetMode(gin.DebugMode)
 	}
 
-	router := gin.New()
-
-	// Global middleware
-	router.Use(middleware.Logger(logger))
-	router.Use(middleware.Recovery(logger))
-	router.Use(middleware.CORS(cfg.CORS.Origins))
-	router.Use(middleware.Compression())
-	router.Use(middleware.RequestID())
-	router.Use(middleware.Timeout(30 * time.Second))
-
-	// Setup routes
-	routes.Setup(router, handlers, cfg.API.Prefix)
-
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status":    "healthy",
-			"timestamp": time.Now().UTC(),
-			"service":   "Digital Wardrobe API",
-			"version":   "1.0.0",
-		})
+	router := routes.NewRouter(handlers, routes.RouterOptions{
+		APIPrefix:      cfg.API.Prefix,
+		CORSOrigins:    cfg.CORS.Origins,
+		RequestTimeout: 30 * time.Second,
+		Logger:         logger,
+		TrustedProxies: cfg.Server.TrustedProxies,
 	})
 
 	// Create HTTP server
@@ -121,6 +218,11 @@ func main() {
 		}
 	}()
 
+	// Start background workers
+	if priceAlertWorker != nil {
+		priceAlertWorker.Start(context.Background())
+	}
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -131,6 +233,12 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	if priceAlertWorker != nil {
+		if err := priceAlertWorker.Stop(ctx); err != nil {
+			logger.Errorf("Failed to stop price alert worker: %v", err)
+		}
+	}
+
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Errorf("Server forced to shutdown: %v", err)
 	}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"digital-wardrobe-backend/internal/database"
+	"digital-wardrobe-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status"
+
+// runMigrate implements the migrate subcommand
+func runMigrate(ctx context.Context, db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+	migrator, err := database.NewMigrator(sqlDB)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+	case "down":
+		steps := 1
+		if len(args) > 2 {
+			return errors.New(migrateUsage)
+		}
+		if len(args) == 2 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("steps must be a positive number")
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reverted %d migration(s)\n", reverted)
+	case "status":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printMigrationStatus(statuses)
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
+
+// printMigrationStatus writes a table of migrations to stdout
+func printMigrationStatus(statuses []database.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", ""
+		if s.Applied {
+			state = "applied"
+			appliedAt = s.AppliedAt.Local().Format(time.RFC3339)
+		}
+		switch {
+		case s.Unknown:
+			state += " (unknown to this build)"
+		case s.Modified:
+			state += " (modified since applied)"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	w.Flush()
+}
+
+// warnPendingMigrations logs when the schema is behind this build
+func warnPendingMigrations(ctx context.Context, db *gorm.DB, log logger.Logger) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Errorf("Failed to get database instance: %v", err)
+		return
+	}
+	migrator, err := database.NewMigrator(sqlDB)
+	if err != nil {
+		log.Errorf("Failed to load migrations: %v", err)
+		return
+	}
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		log.Errorf("Failed to check migrations: %v", err)
+		return
+	}
+	if pending > 0 {
+		log.Warnf("%d migration(s) pending; run \"migrate up\"", pending)
+	}
+}
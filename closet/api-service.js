@@ -3,6 +3,8 @@ class ApiService {
     constructor() {
         this.baseURL = 'http://localhost:8080/api/v1';
         this.authToken = null;
+        this.refreshToken = null;
+        this.refreshPromise = null;
         this.initialized = false;
         this.initPromise = this.init();
     }
@@ -10,8 +12,9 @@ class ApiService {
     async init() {
         // Load auth token from storage
         try {
-            const result = await chrome.storage.local.get(['authToken']);
+            const result = await chrome.storage.local.get(['authToken', 'refreshToken']);
             this.authToken = result.authToken;
+            this.refreshToken = result.refreshToken;
             if (this.authToken) {
                 console.log('🔑 Auth token loaded from storage');
             }
@@ -41,8 +44,8 @@ class ApiService {
         return headers;
     }
     
-    // Helper method to make API calls
-    async makeRequest(endpoint, options = {}) {
+    // Helper method to make API calls; resolves to the whole response envelope
+    async requestEnvelope(endpoint, options = {}, retry = true) {
         const url = `${this.baseURL}${endpoint}`;
         const config = {
             headers: this.getHeaders(),
@@ -53,8 +56,20 @@ class ApiService {
             const response = await fetch(url, config);
             const data = await response.json();
             
-            if (!response.ok) {
-                throw new Error(data.message || `HTTP error! status: ${response.status}`);
+            // Access tokens are short-lived; refresh once and retry
+            if (response.status === 401 && retry && this.refreshToken && !endpoint.startsWith('/auth/refresh')) {
+                if (await this.refreshSession()) {
+                    return await this.requestEnvelope(endpoint, options, false);
+                }
+            }
+            
+            // Failures are { success: false, error, code, details? }
+            if (!response.ok || data.success === false) {
+                const error = new Error(data.error || `HTTP error! status: ${response.status}`);
+                error.code = data.code;
+                error.details = data.details;
+                error.data = data.data;
+                throw error;
             }
             
             return data;
@@ -64,6 +79,50 @@ class ApiService {
         }
     }
     
+    // Helper method to make API calls; successes are { success: true, data }
+    // and resolve to the data. Use requestEnvelope when the pagination block is needed.
+    async makeRequest(endpoint, options = {}, retry = true) {
+        const envelope = await this.requestEnvelope(endpoint, options, retry);
+        return envelope.data !== undefined ? envelope.data : envelope;
+    }
+    
+    // Store the tokens from an auth response
+    async saveTokens(data) {
+        if (!data || !data.token) {
+            return;
+        }
+        
+        this.authToken = data.token;
+        this.refreshToken = data.refreshToken || null;
+        await chrome.storage.local.set({ authToken: this.authToken, refreshToken: this.refreshToken });
+        console.log('🔑 Auth token saved to storage');
+    }
+    
+    // Exchange the refresh token for new tokens. Concurrent callers share one
+    // request because each refresh token can only be used once.
+    async refreshSession() {
+        if (!this.refreshPromise) {
+            this.refreshPromise = (async () => {
+                try {
+                    const data = await this.makeRequest('/auth/refresh', {
+                        method: 'POST',
+                        body: JSON.stringify({ refreshToken: this.refreshToken }),
+                    }, false);
+                    await this.saveTokens(data);
+                    return true;
+                } catch (error) {
+                    this.authToken = null;
+                    this.refreshToken = null;
+                    await chrome.storage.local.remove(['authToken', 'refreshToken']);
+                    return false;
+                } finally {
+                    this.refreshPromise = null;
+                }
+            })();
+        }
+        return this.refreshPromise;
+    }
+    
     // Auth methods
     async login(email, password) {
         await this.ensureInitialized();
@@ -72,11 +131,7 @@ class ApiService {
             body: JSON.stringify({ email, password }),
         });
         
-        if (data.token) {
-            this.authToken = data.token;
-            await chrome.storage.local.set({ authToken: this.authToken });
-            console.log('🔑 Auth token saved to storage');
-        }
+        await this.saveTokens(data);
         
         return data;
     }
@@ -88,11 +143,7 @@ class ApiService {
             body: JSON.stringify({ email, password, fullName }),
         });
         
-        if (data.token) {
-            this.authToken = data.token;
-            await chrome.storage.local.set({ authToken: this.authToken });
-            console.log('🔑 Auth token saved to storage');
-        }
+        await this.saveTokens(data);
         
         return data;
     }
@@ -107,7 +158,8 @@ class ApiService {
         }
         
         this.authToken = null;
-        await chrome.storage.local.remove(['authToken']);
+        this.refreshToken = null;
+        await chrome.storage.local.remove(['authToken', 'refreshToken']);
         console.log('🔑 Auth token removed from storage');
     }
     
@@ -116,8 +168,17 @@ class ApiService {
     }
     
     // Item methods
+    // Items are paginated; follow nextCursor until every page is loaded
     async getItems() {
-        return await this.makeRequest('/items');
+        const items = [];
+        let cursor = '';
+        do {
+            const query = cursor ? `?limit=100&cursor=${encodeURIComponent(cursor)}` : '?limit=100';
+            const page = await this.requestEnvelope(`/items${query}`);
+            items.push(...(page.data || []));
+            cursor = page.pagination && page.pagination.hasMore ? page.pagination.nextCursor : '';
+        } while (cursor);
+        return items;
     }
     
     async createItem(itemData) {
@@ -143,8 +204,13 @@ class ApiService {
         });
     }
     
-    async searchItems(query) {
-        return await this.makeRequest(`/items/search?q=${encodeURIComponent(query)}`);
+    // Search results are ranked { item, rank, highlights } objects, one page at a time
+    async searchItems(query, page = 1) {
+        const envelope = await this.requestEnvelope(`/items/search?q=${encodeURIComponent(query)}&page=${page}`);
+        return {
+            results: envelope.data || [],
+            pagination: envelope.pagination,
+        };
     }
     
     // Collection methods
@@ -1,13 +1,15 @@
 import React, { createContext, useContext, useReducer, useEffect, ReactNode } from 'react';
-import { AuthState, AuthContextType, LoginCredentials, RegisterData, User, ProfileUpdateData } from '../types';
-import { GoogleCredentialResponse, GoogleUser } from '../types/google';
 import { jwtDecode } from 'jwt-decode';
+import { AuthState, AuthContextType, LoginCredentials, RegisterData, User, ProfileUpdateData } from '../types';
+import { GoogleCredentialResponse } from '../types/google';
+import { API_BASE_URL } from '../config/api';
 import { toast } from 'react-hot-toast';
 
 // Auth Actions
 type AuthAction = 
   | { type: 'AUTH_START' }
-  | { type: 'AUTH_SUCCESS'; payload: { user: User; token: string } }
+  | { type: 'AUTH_SUCCESS'; payload: { user: User; token: string; refreshToken?: string | null } }
+  | { type: 'TOKEN_REFRESHED'; payload: { token: string; refreshToken: string } }
   | { type: 'AUTH_FAILURE'; payload: string }
   | { type: 'LOGOUT' }
   | { type: 'UPDATE_PROFILE'; payload: User }
@@ -20,6 +22,7 @@ const initialState: AuthState = {
   isLoading: false,
   error: null,
   token: null,
+  refreshToken: null,
 };
 
 // Auth reducer
@@ -33,16 +36,25 @@ function authReducer(state: AuthState, action: AuthAction): AuthState {
         ...state,
         user: action.payload.user,
         token: action.payload.token,
+        refreshToken: action.payload.refreshToken ?? null,
         isAuthenticated: true,
         isLoading: false,
         error: null,
       };
     
+    case 'TOKEN_REFRESHED':
+      return {
+        ...state,
+        token: action.payload.token,
+        refreshToken: action.payload.refreshToken,
+      };
+    
     case 'AUTH_FAILURE':
       return {
         ...state,
         user: null,
         token: null,
+        refreshToken: null,
         isAuthenticated: false,
         isLoading: false,
         error: action.payload,
@@ -53,6 +65,7 @@ function authReducer(state: AuthState, action: AuthAction): AuthState {
         ...state,
         user: null,
         token: null,
+        refreshToken: null,
         isAuthenticated: false,
         isLoading: false,
         error: null,
@@ -157,15 +170,27 @@ const mockAPI = {
   },
 
   forgotPassword: async (email: string) => {
-    // Simulate API call
-    await new Promise(resolve => setTimeout(resolve, 1000));
-    console.log('Password reset email sent to:', email);
+    const response = await fetch(`${API_BASE_URL}/auth/forgot-password`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ email }),
+    });
+    const body = await response.json().catch(() => null);
+    if (!response.ok || !body?.success) {
+      throw new Error(body?.error || 'Failed to send reset email');
+    }
   },
 
   resetPassword: async (token: string, newPassword: string) => {
-    // Simulate API call
-    await new Promise(resolve => setTimeout(resolve, 1000));
-    console.log('Password reset with token:', token);
+    const response = await fetch(`${API_BASE_URL}/auth/reset-password`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ token, newPassword }),
+    });
+    const body = await response.json().catch(() => null);
+    if (!response.ok || !body?.success) {
+      throw new Error(body?.error || 'Password reset failed');
+    }
   },
 
   verifyEmail: async (token: string) => {
@@ -181,24 +206,28 @@ const mockAPI = {
   },
 
   loginWithGoogle: async (credential: string) => {
-    // Simulate API call - decode the Google credential
-    await new Promise(resolve => setTimeout(resolve, 1000));
-    
-    // Decode JWT using jwt-decode library
-    const googleUser: GoogleUser = jwtDecode(credential) as GoogleUser;
-    if (!googleUser) {
-      throw new Error('Invalid Google credential');
+    // The backend verifies the Google ID token and returns a real session
+    const response = await fetch(`${API_BASE_URL}/auth/google`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ credential }),
+    });
+    const body = await response.json().catch(() => null);
+    if (!response.ok || !body?.success) {
+      throw new Error(body?.error || 'Google sign-in failed');
     }
 
-    // Map Google user to our User type
+    const { user: apiUser, token, refreshToken } = body.data;
+
+    // Map the API user to our User type
     const user: User = {
-      id: googleUser.sub,
-      username: googleUser.email.split('@')[0], // Use email prefix as username
-      displayName: googleUser.name,
-      email: googleUser.email,
-      avatar: googleUser.picture,
-      bio: '',
-      isPrivate: false,
+      id: apiUser.id,
+      username: apiUser.username || apiUser.email.split('@')[0], // Use email prefix as username
+      displayName: apiUser.displayName || apiUser.email,
+      email: apiUser.email,
+      avatar: apiUser.avatar || undefined,
+      bio: apiUser.bio || '',
+      isPrivate: apiUser.isPrivate,
       totalItems: 0,
       totalValue: 0,
       totalCollections: 0,
@@ -212,15 +241,44 @@ const mockAPI = {
         weeklyDigest: true,
         salesAlerts: true,
       },
-      isEmailVerified: googleUser.email_verified,
-      dateCreated: new Date(),
+      isEmailVerified: true, // Google sign-in requires a verified email
+      dateCreated: new Date(apiUser.createdAt),
       lastLogin: new Date(),
     };
 
-    return { user, token: 'google-oauth-token' };
+    return { user, token, refreshToken };
+  },
+
+  // Exchange a refresh token for new tokens; each refresh token works once
+  refreshSession: async (refreshToken: string) => {
+    const response = await fetch(`${API_BASE_URL}/auth/refresh`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ refreshToken }),
+    });
+    const body = await response.json().catch(() => null);
+    if (!response.ok || !body?.success) {
+      throw new Error(body?.error || 'Session expired');
+    }
+    return { token: body.data.token as string, refreshToken: body.data.refreshToken as string };
   },
 };
 
+// Refresh access tokens this long before they expire
+const REFRESH_MARGIN_MS = 60 * 1000;
+
+// msUntilRefresh returns how long to wait before refreshing token, or 0 if it
+// has expired or cannot be decoded
+function msUntilRefresh(token: string): number {
+  try {
+    const { exp } = jwtDecode<{ exp?: number }>(token);
+    if (!exp) return 0;
+    return Math.max(exp * 1000 - Date.now() - REFRESH_MARGIN_MS, 0);
+  } catch {
+    return 0;
+  }
+}
+
 // Auth Provider
 export function AuthProvider({ children }: { children: ReactNode }) {
   const [state, dispatch] = useReducer(authReducer, initialState);
@@ -248,15 +306,17 @@ export function AuthProvider({ children }: { children: ReactNode }) {
   // Load persisted auth state on mount
   useEffect(() => {
     const token = localStorage.getItem('digitalCloset_token');
+    const refreshToken = localStorage.getItem('digitalCloset_refreshToken');
     const userData = localStorage.getItem('digitalCloset_user');
     
     if (token && userData) {
       try {
         const user = JSON.parse(userData);
-        dispatch({ type: 'AUTH_SUCCESS', payload: { user, token } });
+        dispatch({ type: 'AUTH_SUCCESS', payload: { user, token, refreshToken } });
       } catch (error) {
         console.error('Error loading auth state:', error);
         localStorage.removeItem('digitalCloset_token');
+        localStorage.removeItem('digitalCloset_refreshToken');
         localStorage.removeItem('digitalCloset_user');
       }
     }
@@ -269,11 +329,44 @@ export function AuthProvider({ children }: { children: ReactNode }) {
     if (state.user && state.token) {
       localStorage.setItem('digitalCloset_token', state.token);
       localStorage.setItem('digitalCloset_user', JSON.stringify(state.user));
+      if (state.refreshToken) {
+        localStorage.setItem('digitalCloset_refreshToken', state.refreshToken);
+      } else {
+        localStorage.removeItem('digitalCloset_refreshToken');
+      }
     } else {
       localStorage.removeItem('digitalCloset_token');
+      localStorage.removeItem('digitalCloset_refreshToken');
       localStorage.removeItem('digitalCloset_user');
     }
-  }, [state.user, state.token]);
+  }, [state.user, state.token, state.refreshToken]);
+
+  // Access tokens are short-lived; refresh shortly before each one expires
+  useEffect(() => {
+    const token = state.token;
+    const refreshToken = state.refreshToken;
+    if (!token || !refreshToken) return;
+
+    let cancelled = false;
+    const timer = setTimeout(async () => {
+      try {
+        const tokens = await mockAPI.refreshSession(refreshToken);
+        if (!cancelled) {
+          dispatch({ type: 'TOKEN_REFRESHED', payload: tokens });
+        }
+      } catch {
+        if (!cancelled) {
+          dispatch({ type: 'LOGOUT' });
+          toast.error('Your session has expired. Please sign in again.');
+        }
+      }
+    }, msUntilRefresh(token));
+
+    return () => {
+      cancelled = true;
+      clearTimeout(timer);
+    };
+  }, [state.token, state.refreshToken]);
 
   const login = async (credentials: LoginCredentials) => {
     dispatch({ type: 'AUTH_START' });
@@ -327,7 +420,7 @@ export function AuthProvider({ children }: { children: ReactNode }) {
     try {
       await mockAPI.forgotPassword(email);
       dispatch({ type: 'CLEAR_ERROR' });
-      toast.success('Password reset email sent!');
+      toast.success('If an account exists for that email, a reset link is on its way');
     } catch (error) {
       const message = error instanceof Error ? error.message : 'Failed to send reset email';
       dispatch({ type: 'AUTH_FAILURE', payload: message });
@@ -285,6 +285,7 @@ export interface AuthState {
   isLoading: boolean;
   error: string | null;
   token: string | null;
+  refreshToken: string | null;
 }
 
 export interface LoginCredentials {